@@ -0,0 +1,12 @@
+// gkm 游戏手柄按键映射工具的命令行版本（不包含图形界面）
+package main
+
+import (
+	"os"
+
+	"gamepad-key-mapper/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Main(os.Args[1:], os.Stdout, os.Stderr))
+}
@@ -1,14 +1,19 @@
 package app
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
 	"gamepad-key-mapper/internal/config"
 	"gamepad-key-mapper/internal/gamepad"
 	"gamepad-key-mapper/internal/keyboard"
+	"gamepad-key-mapper/internal/logging"
 	"gamepad-key-mapper/internal/mapper"
+	"gamepad-key-mapper/internal/window"
 )
 
 // State 应用状态
@@ -17,6 +22,7 @@ type State int
 const (
 	StateStopped State = iota
 	StateRunning
+	StatePaused // 映射已暂停（仍监听手柄以响应系统组合键）
 )
 
 // App 应用主控制器
@@ -24,22 +30,63 @@ type App struct {
 	mapper   *mapper.Mapper
 	listener *gamepad.Listener
 	state    State
+	cfg      *config.Config // 当前配置（保留规则以外的设置）
 	mu       sync.RWMutex
 
+	// 前台窗口自动切换方案
+	windowSource window.Source
+	watcher      *window.Watcher
+
+	// 系统组合键
+	system *systemFilter
+
+	// 配置文件热加载
+	cfgWatcher *config.Watcher
+
+	// 正在捕获按键（捕获期间事件不传给映射引擎）
+	capturing bool
+
+	// 修改后不自动保存（由调用方保存并处理错误）
+	manualSave bool
+
+	// 启动时配置文件无法读取（已回退为默认配置），恢复备份或用户确认覆盖之前不写入配置文件
+	loadErr *config.LoadError
+
+	// 映射以外使用手柄监听的数量（捕获按键、状态显示），停止映射时监听继续运行
+	listenerUsers int
+
+	// 映射引擎事件日志和其它跟踪事件观察者
+	eventLog  *EventLog
+	tracersMu sync.RWMutex
+	tracers   []*tracerEntry
+
 	// 状态变更回调
-	onStateChange func(State)
-	onRulesChange func()
-	onError       func(error)
+	onStateChange   func(State)
+	onRulesChange   func()
+	onProfileChange func(string)
+	onShowWindow    func()
+	onError         func(error)
 }
 
 // New 创建新的应用实例
-func New() *App {
-	m, _ := mapper.New()
-	return &App{
-		mapper:   m,
-		listener: gamepad.NewListener(0), // 默认监听第一个手柄
-		state:    StateStopped,
+func New() (*App, error) {
+	m, err := mapper.New()
+	if err != nil {
+		return nil, fmt.Errorf("初始化映射引擎失败: %w", err)
 	}
+	a := &App{
+		mapper:       m,
+		listener:     gamepad.NewListener(0), // 默认监听第一个手柄
+		state:        StateStopped,
+		cfg:          config.NewDefault(),
+		windowSource: window.NewSource(),
+		eventLog:     NewEventLog(eventLogSize),
+	}
+	m.SetTracer(mapper.TracerFunc(a.trace))
+	m.SetOnError(a.notifyError) // 映射引擎自己记录日志
+	a.system = newSystemFilter(a.runSystemAction, a.forwardEvents)
+	a.system.SetBindings(a.cfg.SystemBindings)
+	return a, nil
 }
 
 // Start 启动映射
@@ -51,22 +98,29 @@ func (a *App) Start() error {
 		return nil
 	}
 
-	// 启动手柄监听
+	// 暂停状态下直接恢复映射
+	if a.state == StatePaused {
+		a.setStateLocked(StateRunning)
+		return nil
+	}
+
+	// 启动手柄监听（捕获按键或状态显示可能已经启动了监听，重新启动以丢弃积压的事件）
+	a.listener.Stop()
 	if err := a.listener.Start(); err != nil {
-		if a.onError != nil {
-			a.onError(err)
-		}
+		a.reportError(fmt.Errorf("启动手柄监听失败: %w", err))
 		return err
 	}
 
 	// 启动事件处理协程
-	go a.eventLoop()
+	a.system.Reset()
+	go a.eventLoop(a.listener.Events(), a.listener.Axes())
 
-	a.state = StateRunning
-	if a.onStateChange != nil {
-		a.onStateChange(StateRunning)
+	// 启动前台窗口监视
+	if a.cfg.AutoSwitch {
+		a.startWatcherLocked()
 	}
 
+	a.setStateLocked(StateRunning)
 	return nil
 }
 
@@ -83,10 +137,55 @@ func (a *App) Stop() {
 	a.mapper.ReleaseAll()
 
 	a.listener.Stop()
-	a.state = StateStopped
+	if a.listenerUsers > 0 {
+		a.listener.Start() // 事件循环随旧通道关闭而退出，监听继续为状态显示等提供数据
+	}
+	a.stopWatcherLocked()
+	a.system.Reset()
+	a.setStateLocked(StateStopped)
+}
+
+// Pause 暂停映射（释放所有按键，继续监听系统组合键）
+func (a *App) Pause() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.state != StateRunning {
+		return
+	}
+
+	a.mapper.ReleaseAll()
+	a.setStateLocked(StatePaused)
+}
 
+// Resume 恢复已暂停的映射
+func (a *App) Resume() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.state != StatePaused {
+		return
+	}
+
+	a.setStateLocked(StateRunning)
+}
+
+// TogglePause 在运行和暂停之间切换
+func (a *App) TogglePause() {
+	switch a.GetState() {
+	case StateRunning:
+		a.Pause()
+	case StatePaused:
+		a.Resume()
+	}
+}
+
+// setStateLocked 更新状态并通知（调用方需持有锁）
+func (a *App) setStateLocked(state State) {
+	slog.Info("mapping state changed", "from", a.state, "to", state)
+	a.state = state
 	if a.onStateChange != nil {
-		a.onStateChange(StateStopped)
+		a.onStateChange(state)
 	}
 }
 
@@ -105,9 +204,43 @@ func (a *App) GetState() State {
 }
 
 // eventLoop 事件处理循环
-func (a *App) eventLoop() {
-	for event := range a.listener.Events() {
-		a.mapper.HandleEvent(event)
+func (a *App) eventLoop(events <-chan gamepad.ButtonEvent, axes <-chan gamepad.XInputGamepad) {
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			// 系统组合键优先处理，触发按键不会传给映射引擎
+			if a.GetState() == StateStopped {
+				continue
+			}
+			a.system.Process(event)
+		case state, ok := <-axes:
+			if !ok {
+				return
+			}
+			// 捕获按键时模拟量也不传给映射引擎（摇杆方向会被当作按键捕获）
+			a.mu.RLock()
+			active := a.state == StateRunning && !a.capturing
+			a.mu.RUnlock()
+			if active {
+				a.mapper.HandleAxes(state)
+			}
+		}
+	}
+}
+
+// forwardEvents 将系统组合键过滤后的事件传给映射引擎（未运行或正在捕获按键时丢弃）
+func (a *App) forwardEvents(events []gamepad.ButtonEvent) {
+	a.mu.RLock()
+	active := a.state == StateRunning && !a.capturing
+	a.mu.RUnlock()
+	if !active {
+		return
+	}
+	for _, ev := range events {
+		a.mapper.HandleEvent(ev)
 	}
 }
 
@@ -125,17 +258,20 @@ func (a *App) generateRuleID() string {
 func (a *App) AddRuleMultiKeys(source gamepad.Button, targets []keyboard.KeyCode, mods keyboard.Modifiers) (*mapper.MappingRule, error) {
 	// 检查冲突
 	if a.mapper.HasConflict(source, "") {
-		return nil, fmt.Errorf("源按键 %s 已存在映射规则", source.String())
+		return nil, fmt.Errorf("源按键 %s 已存在启用的映射规则", source.String())
 	}
 
 	// 生成唯一ID
 	id := a.generateRuleID()
 
 	rule := mapper.NewRuleMultiKeys(id, source, targets, mods)
+	if err := a.checkNewRule(rule); err != nil {
+		return nil, err
+	}
 	a.mapper.AddRule(rule)
 
 	// 自动保存配置
-	a.SaveConfig()
+	a.autoSave()
 
 	if a.onRulesChange != nil {
 		a.onRulesChange()
@@ -148,24 +284,55 @@ func (a *App) AddRuleMultiKeys(source gamepad.Button, targets []keyboard.KeyCode
 func (a *App) AddRuleGamepad(source gamepad.Button, targets []gamepad.Button) (*mapper.MappingRule, error) {
 	// 检查冲突
 	if a.mapper.HasConflict(source, "") {
-		return nil, fmt.Errorf("源按键 %s 已存在映射规则", source.String())
+		return nil, fmt.Errorf("源按键 %s 已存在启用的映射规则", source.String())
 	}
 
 	// 检查是否映射到自己
-	for _, target := range targets {
-		if target == source {
-			return nil, fmt.Errorf("不能将按键映射到自己")
-		}
+	if err := checkSelfTarget(source, targets); err != nil {
+		return nil, err
 	}
 
 	// 生成唯一ID
 	id := a.generateRuleID()
 
 	rule := mapper.NewRuleGamepad(id, source, targets)
+	if err := a.checkNewRule(rule); err != nil {
+		return nil, err
+	}
+	a.mapper.AddRule(rule)
+
+	// 自动保存配置
+	a.autoSave()
+
+	if a.onRulesChange != nil {
+		a.onRulesChange()
+	}
+
+	return rule, nil
+}
+
+// AddRuleExec 添加命令执行规则
+func (a *App) AddRuleExec(source gamepad.Button, action *mapper.ExecAction) (*mapper.MappingRule, error) {
+	// 检查冲突
+	if a.mapper.HasConflict(source, "") {
+		return nil, fmt.Errorf("源按键 %s 已存在启用的映射规则", source.String())
+	}
+
+	if err := checkExecAction(action); err != nil {
+		return nil, err
+	}
+
+	// 生成唯一ID
+	id := a.generateRuleID()
+
+	rule := mapper.NewRuleExec(id, source, action)
+	if err := a.checkNewRule(rule); err != nil {
+		return nil, err
+	}
 	a.mapper.AddRule(rule)
 
 	// 自动保存配置
-	a.SaveConfig()
+	a.autoSave()
 
 	if a.onRulesChange != nil {
 		a.onRulesChange()
@@ -174,12 +341,187 @@ func (a *App) AddRuleGamepad(source gamepad.Button, targets []gamepad.Button) (*
 	return rule, nil
 }
 
+// AddMappingRule 按完整的规则内容添加规则（忽略 rule.ID，重新生成），供命令行和控制接口使用
+func (a *App) AddMappingRule(rule *mapper.MappingRule) (*mapper.MappingRule, error) {
+	// 检查冲突（停用的规则不冲突）
+	if rule.Enabled && a.mapper.HasConflict(rule.SourceKey, "") {
+		return nil, fmt.Errorf("源按键 %s 已存在启用的映射规则", rule.SourceKey.String())
+	}
+
+	switch rule.TargetType {
+	case mapper.TargetGamepad:
+		if err := checkSelfTarget(rule.SourceKey, rule.TargetButtons); err != nil {
+			return nil, err
+		}
+	case mapper.TargetExec:
+		if err := checkExecAction(rule.Exec); err != nil {
+			return nil, err
+		}
+	}
+
+	// 复制后再添加，调用方之后修改 rule 不影响映射引擎
+	added := *rule
+	added.ID = a.generateRuleID()
+	added.Name = strings.TrimSpace(added.Name)
+	added.Description = strings.TrimSpace(added.Description)
+	if err := a.checkNewRule(&added); err != nil {
+		return nil, err
+	}
+	a.mapper.AddRule(&added)
+
+	a.rulesChanged()
+	return &added, nil
+}
+
+// UpdateRule 修改已有规则（按 rule.ID 查找，保持规则在列表中的位置）
+//
+// 源按键正被按住时会先释放旧规则的输出，修改在下次按下时生效。
+func (a *App) UpdateRule(rule *mapper.MappingRule) error {
+	if a.mapper.GetRuleByID(rule.ID) == nil {
+		return fmt.Errorf("规则不存在: %s", rule.ID)
+	}
+
+	// 检查冲突（排除规则自身，停用的规则不冲突）
+	if rule.Enabled && a.mapper.HasConflict(rule.SourceKey, rule.ID) {
+		return fmt.Errorf("源按键 %s 已存在启用的映射规则", rule.SourceKey.String())
+	}
+
+	switch rule.TargetType {
+	case mapper.TargetGamepad:
+		if err := checkSelfTarget(rule.SourceKey, rule.TargetButtons); err != nil {
+			return err
+		}
+	case mapper.TargetExec:
+		if err := checkExecAction(rule.Exec); err != nil {
+			return err
+		}
+	}
+	if err := a.checkNewRule(rule); err != nil {
+		return err
+	}
+
+	if !a.mapper.UpdateRule(rule) {
+		return fmt.Errorf("规则不存在: %s", rule.ID)
+	}
+
+	// 自动保存配置
+	a.autoSave()
+
+	if a.onRulesChange != nil {
+		a.onRulesChange()
+	}
+	return nil
+}
+
+// SetRuleEnabled 启用或停用规则（同一源按键同时只能启用一条规则）
+func (a *App) SetRuleEnabled(id string, enabled bool) error {
+	if enabled {
+		rule := a.mapper.GetRuleByID(id)
+		if rule == nil {
+			return fmt.Errorf("规则不存在: %s", id)
+		}
+		if a.mapper.HasConflict(rule.SourceKey, id) {
+			return fmt.Errorf("源按键 %s 已存在启用的映射规则，请先停用该规则", rule.SourceKey.String())
+		}
+	}
+	if !a.mapper.SetRuleEnabled(id, enabled) {
+		return fmt.Errorf("规则不存在: %s", id)
+	}
+	a.rulesChanged()
+	return nil
+}
+
+// SetRuleInfo 设置规则的名称和说明
+func (a *App) SetRuleInfo(id string, name string, description string) error {
+	rule := a.mapper.GetRuleByID(id)
+	if rule == nil {
+		return fmt.Errorf("规则不存在: %s", id)
+	}
+
+	// 复制后整体替换，避免与映射引擎并发读取同一规则
+	updated := *rule
+	updated.Name = strings.TrimSpace(name)
+	updated.Description = strings.TrimSpace(description)
+	a.mapper.UpdateRule(&updated)
+
+	a.rulesChanged()
+	return nil
+}
+
+// MoveRule 调整规则顺序（delta 为负数时向前移动）
+func (a *App) MoveRule(id string, delta int) error {
+	if a.mapper.GetRuleByID(id) == nil {
+		return fmt.Errorf("规则不存在: %s", id)
+	}
+	if a.mapper.MoveRule(id, delta) {
+		a.rulesChanged()
+	}
+	return nil
+}
+
+// rulesChanged 规则修改后自动保存并通知界面
+func (a *App) rulesChanged() {
+	a.autoSave()
+
+	if a.onRulesChange != nil {
+		a.onRulesChange()
+	}
+}
+
+// GetRule 根据ID获取规则
+func (a *App) GetRule(id string) *mapper.MappingRule {
+	return a.mapper.GetRuleByID(id)
+}
+
+// checkSelfTarget 检查手柄映射的目标中是否包含源按键自身
+func checkSelfTarget(source gamepad.Button, targets []gamepad.Button) error {
+	for _, target := range targets {
+		if target == source {
+			return fmt.Errorf("不能将按键映射到自己")
+		}
+	}
+	return nil
+}
+
+// checkExecAction 检查命令规则的设置
+func checkExecAction(action *mapper.ExecAction) error {
+	if action == nil || strings.TrimSpace(action.Command) == "" {
+		return fmt.Errorf("命令不能为空")
+	}
+	if !action.OnPress && !action.OnRelease {
+		return fmt.Errorf("请至少选择按下或释放时执行")
+	}
+	return nil
+}
+
+// SetAllowExec 设置是否允许执行命令规则
+func (a *App) SetAllowExec(allowed bool) error {
+	a.mu.Lock()
+	a.cfg.AllowExec = allowed
+	a.mu.Unlock()
+
+	a.mapper.SetExecAllowed(allowed)
+	return a.SaveConfig()
+}
+
+// ExecAllowed 检查是否允许执行命令规则
+func (a *App) ExecAllowed() bool {
+	return a.mapper.ExecAllowed()
+}
+
+// OverlayAddr 返回直播叠加层服务的监听地址（未开启时为空，修改配置后重启生效）
+func (a *App) OverlayAddr() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.cfg.OverlayListenAddr()
+}
+
 // RemoveRule 删除映射规则
 func (a *App) RemoveRule(id string) bool {
 	removed := a.mapper.RemoveRule(id)
 	if removed {
 		// 自动保存配置
-		a.SaveConfig()
+		a.autoSave()
 		
 		if a.onRulesChange != nil {
 			a.onRulesChange()
@@ -201,6 +543,57 @@ func (a *App) SetRules(rules []*mapper.MappingRule) {
 	}
 }
 
+// AddAxisRule 添加模拟轴映射规则（ID 为空时自动生成）
+func (a *App) AddAxisRule(rule *mapper.AxisRule) (*mapper.AxisRule, error) {
+	if rule.Source.String() == "Unknown" {
+		return nil, fmt.Errorf("无效的源轴")
+	}
+	if !rule.Target.IsValid() {
+		return nil, fmt.Errorf("无效的输出目标")
+	}
+
+	if rule.ID == "" {
+		rule.ID = a.generateRuleID()
+	}
+	a.mapper.AddAxisRule(rule)
+
+	// 自动保存配置
+	a.autoSave()
+
+	if a.onRulesChange != nil {
+		a.onRulesChange()
+	}
+
+	return rule, nil
+}
+
+// RemoveAxisRule 删除模拟轴映射规则
+func (a *App) RemoveAxisRule(id string) bool {
+	removed := a.mapper.RemoveAxisRule(id)
+	if removed {
+		// 自动保存配置
+		a.autoSave()
+
+		if a.onRulesChange != nil {
+			a.onRulesChange()
+		}
+	}
+	return removed
+}
+
+// GetAxisRules 获取所有模拟轴规则
+func (a *App) GetAxisRules() []*mapper.AxisRule {
+	return a.mapper.GetAxisRules()
+}
+
+// SetAxisRules 设置所有模拟轴规则
+func (a *App) SetAxisRules(rules []*mapper.AxisRule) {
+	a.mapper.SetAxisRules(rules)
+	if a.onRulesChange != nil {
+		a.onRulesChange()
+	}
+}
+
 // HasConflict 检查源按键是否冲突
 func (a *App) HasConflict(source gamepad.Button, excludeID string) bool {
 	return a.mapper.HasConflict(source, excludeID)
@@ -216,6 +609,16 @@ func (a *App) SetOnRulesChange(callback func()) {
 	a.onRulesChange = callback
 }
 
+// SetOnProfileChange 设置方案切换/方案列表变更回调（参数为当前方案名称）
+func (a *App) SetOnProfileChange(callback func(string)) {
+	a.onProfileChange = callback
+}
+
+// SetOnShowWindow 设置显示窗口回调（由系统组合键触发）
+func (a *App) SetOnShowWindow(callback func()) {
+	a.onShowWindow = callback
+}
+
 // SetOnError 设置错误回调
 func (a *App) SetOnError(callback func(error)) {
 	a.onError = callback
@@ -228,28 +631,107 @@ func (s State) String() string {
 		return "已停止"
 	case StateRunning:
 		return "运行中"
+	case StatePaused:
+		return "已暂停"
 	default:
 		return "未知"
 	}
 }
 
 // LoadConfig 加载配置
+//
+// 配置文件损坏时仍会应用默认配置，并返回 *config.LoadError 供界面提示；此后暂停保存，
+// 直到恢复备份、配置文件被外部修复或调用 ConfirmDefaultConfig，避免默认配置覆盖用户的文件。
 func (a *App) LoadConfig() error {
 	cfg, err := config.Load()
-	if err != nil {
+	if cfg == nil {
 		return err
 	}
 
-	a.mapper.SetRules(cfg.Rules)
-	return nil
+	a.applyConfig(cfg)
+
+	var loadErr *config.LoadError
+	if errors.As(err, &loadErr) {
+		a.mu.Lock()
+		a.loadErr = loadErr
+		a.mu.Unlock()
+	}
+	return err
+}
+
+// applyConfig 应用配置（替换规则时释放所有按住的键）
+func (a *App) applyConfig(cfg *config.Config) {
+	a.mu.Lock()
+	a.cfg = cfg
+	a.loadErr = nil
+	active := cfg.Active()
+
+	// 运行中时按新配置启停前台窗口监视
+	if a.state != StateStopped {
+		if cfg.AutoSwitch {
+			a.startWatcherLocked()
+		} else {
+			a.stopWatcherLocked()
+		}
+	}
+	a.mu.Unlock()
+
+	a.mapper.ReplaceRules(active.Rules, active.AxisRules)
+	a.mapper.SetExecAllowed(cfg.AllowExec)
+	if level, err := logging.ParseLevel(cfg.LogLevel); err != nil {
+		slog.Warn("invalid log level in config, keeping current level", "err", err)
+	} else {
+		logging.SetLevel(level)
+	}
+	logDiagnostics(active.Name, active.Rules, active.AxisRules)
+	a.system.SetBindings(cfg.SystemBindings)
+}
+
+// SetManualSave 关闭修改规则和切换方案后的自动保存，由调用方调用 SaveConfig 保存
+//
+// 供命令行使用：每次修改只保存一次，保存失败时返回错误而不是通过错误回调通知。需在使用前调用。
+func (a *App) SetManualSave(manual bool) {
+	a.manualSave = manual
+}
+
+// autoSave 修改后自动保存配置，失败时记录日志并通知界面
+func (a *App) autoSave() {
+	if a.manualSave {
+		return
+	}
+	if err := a.SaveConfig(); err != nil {
+		if errors.Is(err, ErrSavePaused) {
+			slog.Debug("autosave skipped, config file could not be loaded")
+			return
+		}
+		a.reportError(fmt.Errorf("自动保存配置失败: %w", err))
+	}
 }
 
-// SaveConfig 保存配置
+// SaveConfig 保存配置（当前规则写回当前方案）
+//
+// 在锁内深拷贝配置，编码和写入在锁外进行，不会与重命名方案、修改规则等操作并发访问同一方案。
+// 启动时配置文件无法读取时返回 ErrSavePaused（见 LoadConfig）。
 func (a *App) SaveConfig() error {
-	cfg := &config.Config{
-		Rules:          a.mapper.GetRules(),
-		MinimizeToTray: true,
-		StartMinimized: false,
+	a.mu.Lock()
+	if a.loadErr != nil {
+		a.mu.Unlock()
+		return ErrSavePaused
 	}
+	a.syncActiveProfileLocked()
+	cfg, err := a.cfg.Clone()
+	a.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
 	return config.Save(cfg)
 }
+
+// syncActiveProfileLocked 将映射引擎中的规则写回当前方案（调用方需持有锁）
+func (a *App) syncActiveProfileLocked() {
+	if active := a.cfg.Active(); active != nil {
+		active.Rules = a.mapper.GetRules()
+		active.AxisRules = a.mapper.GetAxisRules()
+	}
+}
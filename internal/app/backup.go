@@ -0,0 +1,59 @@
+package app
+
+import (
+	"errors"
+
+	"gamepad-key-mapper/internal/config"
+)
+
+// ErrSavePaused 启动时配置文件无法读取，恢复备份或确认使用当前配置之前不保存
+var ErrSavePaused = errors.New("配置文件无法读取，已暂停保存：请恢复备份，或确认用当前配置覆盖配置文件")
+
+// LoadFailure 返回启动时配置文件无法读取的错误（保存暂停中），正常加载时返回 nil
+func (a *App) LoadFailure() *config.LoadError {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.loadErr
+}
+
+// ConfirmDefaultConfig 用户确认放弃无法读取的配置文件：恢复保存并立即用当前配置覆盖它
+//
+// 原文件在加载时已备份，之后仍可通过 RestoreBackup 恢复。
+func (a *App) ConfirmDefaultConfig() error {
+	a.mu.Lock()
+	a.loadErr = nil
+	a.mu.Unlock()
+	return a.SaveConfig()
+}
+
+// ListBackups 列出配置备份（最新的在前）
+func (a *App) ListBackups() ([]config.Backup, error) {
+	path, err := config.GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return config.ListBackups(path)
+}
+
+// RestoreBackup 从备份恢复配置并立即应用（当前配置会先被备份，暂停的保存随之恢复）
+func (a *App) RestoreBackup(backupPath string) error {
+	path, err := config.GetConfigPath()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.RestoreBackup(path, backupPath)
+	if err != nil {
+		return err
+	}
+
+	a.applyConfig(cfg)
+
+	if a.onProfileChange != nil {
+		a.onProfileChange(a.ActiveProfile())
+	}
+	if a.onRulesChange != nil {
+		a.onRulesChange()
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gamepad-key-mapper/internal/config"
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/keyboard"
+)
+
+// loadBrokenConfig 写入无法解析的配置文件并加载，返回配置文件路径和原内容
+func loadBrokenConfig(t *testing.T, a *App) (string, []byte) {
+	t.Helper()
+	path, err := config.GetConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := []byte(`{"version": 2, "profiles": [`)
+	if err := os.WriteFile(path, broken, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var loadErr *config.LoadError
+	if err := a.LoadConfig(); !errors.As(err, &loadErr) {
+		t.Fatalf("LoadConfig() error = %v, want *config.LoadError", err)
+	}
+	if a.LoadFailure() == nil {
+		t.Fatal("LoadFailure() = nil after a failed load")
+	}
+	return path, broken
+}
+
+// expectFile 检查配置文件内容
+func expectFile(t *testing.T, path string, want []byte) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, want) {
+		t.Fatalf("config file = %q, want %q", data, want)
+	}
+}
+
+func TestFailedLoadPausesSaving(t *testing.T) {
+	a := newTestApp(t)
+	var reported []error
+	a.SetOnError(func(err error) { reported = append(reported, err) })
+	path, broken := loadBrokenConfig(t, a)
+
+	// 自动保存静默跳过，显式保存返回 ErrSavePaused，文件保持原样
+	if _, err := a.AddRule(gamepad.ButtonA, keyboard.KeyCode(0x41), keyboard.Modifiers{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SetAllowExec(true); !errors.Is(err, ErrSavePaused) {
+		t.Errorf("SetAllowExec() error = %v, want ErrSavePaused", err)
+	}
+	if len(reported) != 0 {
+		t.Errorf("errors reported by autosave = %v, want none", reported)
+	}
+	expectFile(t, path, broken)
+
+	// 确认后保存恢复，当前配置（含暂停期间的修改）写入文件
+	if err := a.ConfirmDefaultConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if a.LoadFailure() != nil {
+		t.Error("LoadFailure() != nil after ConfirmDefaultConfig")
+	}
+	loaded, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules := loaded.Active().Rules; len(rules) != 1 || !loaded.AllowExec {
+		t.Errorf("saved config rules = %v, allow exec = %v; want the edits made while paused", rules, loaded.AllowExec)
+	}
+}
+
+func TestRestoreBackupResumesSaving(t *testing.T) {
+	a := newTestApp(t)
+	if _, err := a.AddRule(gamepad.ButtonB, keyboard.KeyCode(0x42), keyboard.Modifiers{}); err != nil {
+		t.Fatal(err)
+	}
+	path, err := config.GetConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	good, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新启动时配置文件已损坏
+	if a, err = New(); err != nil {
+		t.Fatal(err)
+	}
+	_, broken := loadBrokenConfig(t, a)
+
+	// 加载失败时备份了损坏的文件；把正常的配置也放进备份目录后恢复它
+	backups, err := a.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 || a.LoadFailure().BackupPath != backups[0].Path {
+		t.Fatalf("backups = %v, want only the broken file (%s)", backups, a.LoadFailure().BackupPath)
+	}
+	expectFile(t, backups[0].Path, broken)
+
+	goodBackup := filepath.Join(config.BackupDir(path), "config.20000101-000000.000.json")
+	if err := os.WriteFile(goodBackup, good, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.RestoreBackup(goodBackup); err != nil {
+		t.Fatal(err)
+	}
+	if a.LoadFailure() != nil {
+		t.Error("LoadFailure() != nil after RestoreBackup")
+	}
+	if rules := a.GetRules(); len(rules) != 1 || rules[0].SourceKey != gamepad.ButtonB {
+		t.Errorf("rules after restore = %v, want the backed up rule", rules)
+	}
+	if err := a.SaveConfig(); err != nil {
+		t.Errorf("SaveConfig() after restore = %v", err)
+	}
+}
@@ -0,0 +1,348 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"gamepad-key-mapper/internal/config"
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// ImportMode 方案包导入方式
+type ImportMode int
+
+const (
+	ImportAsNew   ImportMode = iota // 作为新方案导入
+	ImportMerge                     // 合并到已有方案
+	ImportReplace                   // 替换已有方案的全部规则
+)
+
+// String 返回导入方式名称
+func (m ImportMode) String() string {
+	switch m {
+	case ImportAsNew:
+		return "新建方案"
+	case ImportMerge:
+		return "合并到方案"
+	case ImportReplace:
+		return "替换方案"
+	default:
+		return "未知"
+	}
+}
+
+// ConflictPolicy 合并时导入的规则与已有规则使用同一源按键（或同一源轴）的处理方式
+type ConflictPolicy int
+
+const (
+	ConflictSkip      ConflictPolicy = iota // 保留已有规则，跳过导入的规则
+	ConflictOverwrite                       // 删除已有规则，使用导入的规则
+)
+
+// String 返回冲突处理方式名称
+func (c ConflictPolicy) String() string {
+	switch c {
+	case ConflictSkip:
+		return "保留已有规则"
+	case ConflictOverwrite:
+		return "使用导入的规则"
+	default:
+		return "未知"
+	}
+}
+
+// ImportOptions 方案包导入选项
+type ImportOptions struct {
+	Mode     ImportMode
+	Target   string         // 目标方案；新建时为空使用方案包名称，合并/替换时为空使用当前方案
+	Conflict ConflictPolicy // 仅合并时使用
+
+	// AllowExec 导入的命令规则保持原来的启用状态（用户已确认信任这些命令）；
+	// 为 false 时命令规则导入后停用，用户检查后再手动启用
+	AllowExec bool
+}
+
+// ImportResult 方案包导入结果
+type ImportResult struct {
+	Profile  string // 导入到的方案
+	Added    int    // 新增的规则数
+	Replaced int    // 覆盖的已有规则数
+	Skipped  int    // 因冲突跳过的规则数
+
+	ExecDisabled int // 导入后被停用的命令规则数（未设置 AllowExec 时）
+}
+
+// ExportProfile 将方案导出为方案包文件（格式由扩展名决定）
+func (a *App) ExportProfile(name string, path string, meta config.BundleMeta) error {
+	a.mu.Lock()
+	p := a.cfg.Profile(name)
+	if p == nil {
+		a.mu.Unlock()
+		return fmt.Errorf("方案 %s 不存在", name)
+	}
+	a.syncActiveProfileLocked()
+	bundle, err := config.NewBundle(p, meta)
+	a.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := config.SaveBundle(path, bundle); err != nil {
+		return fmt.Errorf("导出方案失败: %w", err)
+	}
+	return nil
+}
+
+// ReadBundle 读取方案包（用于导入前显示方案包信息）
+func (a *App) ReadBundle(path string) (*config.Bundle, error) {
+	bundle, err := config.LoadBundle(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取方案包失败: %w", err)
+	}
+	return bundle, nil
+}
+
+// ReadForeign 读取其它映射工具的配置文件并转换为方案包（不导入），同时返回转换报告
+func (a *App) ReadForeign(path string) (*config.Bundle, *config.ImportReport, error) {
+	bundle, report, err := config.ImportForeign(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	return bundle, report, nil
+}
+
+// ShareCode 生成方案的分享码
+func (a *App) ShareCode(name string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	p := a.cfg.Profile(name)
+	if p == nil {
+		return "", fmt.Errorf("方案 %s 不存在", name)
+	}
+	a.syncActiveProfileLocked()
+	return config.EncodeShareCode(p)
+}
+
+// ReadShareCode 解析分享码（得到的方案包可通过 ImportBundle 导入）
+//
+// 分享码可能来自聊天中的任何人，其中的命令规则与方案包文件一样，只有设置了
+// ImportOptions.AllowExec 才会保持启用。
+func (a *App) ReadShareCode(code string) (*config.Bundle, error) {
+	return config.DecodeShareCode(code)
+}
+
+// ImportProfile 从方案包文件导入方案
+func (a *App) ImportProfile(path string, opts ImportOptions) (*ImportResult, error) {
+	bundle, err := a.ReadBundle(path)
+	if err != nil {
+		return nil, err
+	}
+	return a.ImportBundle(bundle, opts)
+}
+
+// ImportBundle 导入方案包（规则使用新的 ID，导入到当前方案时先释放所有按住的键）
+//
+// 未设置 opts.AllowExec 时，方案包中的命令规则导入后处于停用状态。
+func (a *App) ImportBundle(bundle *config.Bundle, opts ImportOptions) (*ImportResult, error) {
+	rules, axisRules, err := a.cloneBundleRules(bundle)
+	if err != nil {
+		return nil, err
+	}
+	disabled := make(map[*mapper.MappingRule]bool)
+	if !opts.AllowExec {
+		for _, rule := range rules {
+			if rule.TargetType == mapper.TargetExec && rule.Enabled {
+				rule.Enabled = false
+				disabled[rule] = true
+			}
+		}
+	}
+
+	a.mu.Lock()
+	a.syncActiveProfileLocked()
+
+	var result *ImportResult
+	switch opts.Mode {
+	case ImportAsNew:
+		result, err = a.importAsNewLocked(bundle, opts.Target, rules, axisRules)
+	case ImportMerge, ImportReplace:
+		result, err = a.importIntoLocked(opts, rules, axisRules)
+	default:
+		err = fmt.Errorf("未知的导入方式")
+	}
+	if err != nil {
+		a.mu.Unlock()
+		return nil, err
+	}
+
+	imported := a.cfg.Profile(result.Profile)
+	for _, rule := range imported.Rules {
+		if disabled[rule] {
+			result.ExecDisabled++ // 合并时跳过的规则不计入
+		}
+	}
+	isActive := a.cfg.Active() == imported
+	if isActive {
+		a.mapper.ReplaceRules(imported.Rules, imported.AxisRules)
+	}
+	a.mu.Unlock()
+
+	if isActive && a.onRulesChange != nil {
+		a.onRulesChange()
+	}
+	return result, a.saveProfiles()
+}
+
+// cloneBundleRules 复制方案包中的规则并分配新的 ID
+func (a *App) cloneBundleRules(bundle *config.Bundle) ([]*mapper.MappingRule, []*mapper.AxisRule, error) {
+	clone, err := bundle.Profile.Clone(bundle.Profile.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	base := a.generateRuleID()
+	for i, rule := range clone.Rules {
+		rule.ID = fmt.Sprintf("%s_%d", base, i)
+	}
+	for i, rule := range clone.AxisRules {
+		rule.ID = fmt.Sprintf("%s_a%d", base, i)
+	}
+	return clone.Rules, clone.AxisRules, nil
+}
+
+// importAsNewLocked 作为新方案导入（调用方需持有锁）
+func (a *App) importAsNewLocked(bundle *config.Bundle, name string, rules []*mapper.MappingRule, axisRules []*mapper.AxisRule) (*ImportResult, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = a.uniqueProfileNameLocked(bundle.Meta.Name)
+	}
+	if err := a.checkProfileNameLocked(name); err != nil {
+		return nil, err
+	}
+
+	p := config.NewProfile(name)
+	p.Rules = rules
+	p.AxisRules = axisRules
+	p.Match = append(p.Match, bundle.Profile.Match...)
+	a.cfg.Profiles = append(a.cfg.Profiles, p)
+
+	return &ImportResult{Profile: name, Added: len(rules) + len(axisRules)}, nil
+}
+
+// importIntoLocked 合并或替换到已有方案（调用方需持有锁）
+func (a *App) importIntoLocked(opts ImportOptions, rules []*mapper.MappingRule, axisRules []*mapper.AxisRule) (*ImportResult, error) {
+	p := a.cfg.Active()
+	if opts.Target != "" {
+		p = a.cfg.Profile(opts.Target)
+	}
+	if p == nil {
+		return nil, fmt.Errorf("方案 %s 不存在", opts.Target)
+	}
+
+	result := &ImportResult{Profile: p.Name}
+
+	if opts.Mode == ImportReplace {
+		result.Replaced = len(p.Rules) + len(p.AxisRules)
+		result.Added = len(rules) + len(axisRules)
+		p.Rules = rules
+		p.AxisRules = axisRules
+		return result, nil
+	}
+
+	// 冲突只与方案中原有的规则比较，方案包内部的规则互不影响
+	existing := &config.Profile{Rules: p.Rules, AxisRules: p.AxisRules}
+
+	var addRules []*mapper.MappingRule
+	overwrite := make(map[gamepad.Button]bool)
+	for _, rule := range rules {
+		if rule.Enabled && existing.HasConflict(rule.SourceKey, "") {
+			if opts.Conflict == ConflictSkip {
+				result.Skipped++
+				continue
+			}
+			overwrite[rule.SourceKey] = true
+		}
+		addRules = append(addRules, rule)
+	}
+
+	var addAxisRules []*mapper.AxisRule
+	overwriteAxes := make(map[gamepad.Axis]bool)
+	for _, rule := range axisRules {
+		if hasAxisConflict(existing, rule.Source) {
+			if opts.Conflict == ConflictSkip {
+				result.Skipped++
+				continue
+			}
+			overwriteAxes[rule.Source] = true
+		}
+		addAxisRules = append(addAxisRules, rule)
+	}
+
+	for source := range overwrite {
+		result.Replaced += removeRulesBySource(p, source)
+	}
+	for source := range overwriteAxes {
+		result.Replaced += removeAxisRulesBySource(p, source)
+	}
+
+	p.Rules = append(p.Rules, addRules...)
+	p.AxisRules = append(p.AxisRules, addAxisRules...)
+	result.Added = len(addRules) + len(addAxisRules)
+
+	return result, nil
+}
+
+// uniqueProfileNameLocked 返回不与现有方案重名的名称（调用方需持有锁）
+func (a *App) uniqueProfileNameLocked(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "导入的方案"
+	}
+	if a.cfg.Profile(name) == nil {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)", name, i)
+		if a.cfg.Profile(candidate) == nil {
+			return candidate
+		}
+	}
+}
+
+// removeRulesBySource 删除方案中使用该源按键的规则，返回删除数量
+func removeRulesBySource(p *config.Profile, source gamepad.Button) int {
+	kept := make([]*mapper.MappingRule, 0, len(p.Rules))
+	for _, rule := range p.Rules {
+		if rule.SourceKey != source {
+			kept = append(kept, rule)
+		}
+	}
+	removed := len(p.Rules) - len(kept)
+	p.Rules = kept
+	return removed
+}
+
+// hasAxisConflict 检查方案中是否已有使用该源轴的模拟量规则
+func hasAxisConflict(p *config.Profile, source gamepad.Axis) bool {
+	for _, rule := range p.AxisRules {
+		if rule.Source == source {
+			return true
+		}
+	}
+	return false
+}
+
+// removeAxisRulesBySource 删除方案中使用该源轴的模拟量规则，返回删除数量
+func removeAxisRulesBySource(p *config.Profile, source gamepad.Axis) int {
+	kept := make([]*mapper.AxisRule, 0, len(p.AxisRules))
+	for _, rule := range p.AxisRules {
+		if rule.Source != source {
+			kept = append(kept, rule)
+		}
+	}
+	removed := len(p.AxisRules) - len(kept)
+	p.AxisRules = kept
+	return removed
+}
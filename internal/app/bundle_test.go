@@ -0,0 +1,139 @@
+package app
+
+import (
+	"testing"
+
+	"gamepad-key-mapper/internal/config"
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/keyboard"
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// execBundle 创建包含一条键盘规则和两条命令规则的方案包
+func execBundle(t *testing.T) *config.Bundle {
+	t.Helper()
+	p := config.NewProfile("shared")
+	p.Rules = []*mapper.MappingRule{
+		mapper.NewRule("k", gamepad.ButtonA, keyboard.KeyCode(0x41), keyboard.Modifiers{}),
+		mapper.NewRuleExec("e1", gamepad.ButtonB, &mapper.ExecAction{Mode: mapper.ExecShell, Command: "echo hi", OnPress: true}),
+		mapper.NewRuleExec("e2", gamepad.ButtonX, &mapper.ExecAction{Mode: mapper.ExecProgram, Command: "obs64.exe", OnPress: true}),
+	}
+	bundle, err := config.NewBundle(p, config.BundleMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bundle
+}
+
+// enabledBySource 返回方案中各源按键规则的启用状态
+func enabledBySource(a *App, profile string) map[gamepad.Button]bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	enabled := make(map[gamepad.Button]bool)
+	for _, rule := range a.cfg.Profile(profile).Rules {
+		enabled[rule.SourceKey] = rule.Enabled
+	}
+	return enabled
+}
+
+func TestImportBundleDisablesExecRules(t *testing.T) {
+	a := newTestApp(t)
+	bundle := execBundle(t)
+
+	if got := len(bundle.ExecRules()); got != 2 {
+		t.Fatalf("ExecRules() returned %d rules, want 2", got)
+	}
+
+	result, err := a.ImportBundle(bundle, ImportOptions{Mode: ImportAsNew})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExecDisabled != 2 {
+		t.Errorf("ExecDisabled = %d, want 2", result.ExecDisabled)
+	}
+
+	enabled := enabledBySource(a, result.Profile)
+	if !enabled[gamepad.ButtonA] {
+		t.Error("keyboard rule was disabled")
+	}
+	if enabled[gamepad.ButtonB] || enabled[gamepad.ButtonX] {
+		t.Error("exec rules were imported enabled without AllowExec")
+	}
+
+	// 方案包本身不被修改，可以再次按同意后的选项导入
+	for _, rule := range bundle.ExecRules() {
+		if !rule.Enabled {
+			t.Errorf("bundle rule %s was modified", rule.ID)
+		}
+	}
+}
+
+func TestImportBundleAllowExecKeepsRulesEnabled(t *testing.T) {
+	a := newTestApp(t)
+
+	result, err := a.ImportBundle(execBundle(t), ImportOptions{Mode: ImportAsNew, AllowExec: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExecDisabled != 0 {
+		t.Errorf("ExecDisabled = %d, want 0", result.ExecDisabled)
+	}
+	enabled := enabledBySource(a, result.Profile)
+	if !enabled[gamepad.ButtonB] || !enabled[gamepad.ButtonX] {
+		t.Error("exec rules were disabled despite AllowExec")
+	}
+}
+
+func TestImportBundleMergeAddsDisabledExecRulesAlongside(t *testing.T) {
+	a := newTestApp(t)
+	existing, err := a.AddRule(gamepad.ButtonB, keyboard.KeyCode(0x42), keyboard.Modifiers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 停用的命令规则不与原有规则冲突，作为备选规则加入
+	result, err := a.ImportBundle(execBundle(t), ImportOptions{Mode: ImportMerge, Conflict: ConflictSkip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Skipped != 0 || result.ExecDisabled != 2 {
+		t.Errorf("Skipped = %d, ExecDisabled = %d; want 0 and 2", result.Skipped, result.ExecDisabled)
+	}
+	if rule := a.mapper.FindRuleBySource(gamepad.ButtonB); rule == nil || rule.ID != existing.ID {
+		t.Errorf("active rule for B = %+v, want the existing keyboard rule", rule)
+	}
+
+	// 允许命令时规则保持启用，与已启用的 A、B 规则冲突，按选项跳过
+	result, err = a.ImportBundle(execBundle(t), ImportOptions{Mode: ImportMerge, Conflict: ConflictSkip, AllowExec: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Skipped != 2 {
+		t.Errorf("Skipped = %d, want 2", result.Skipped)
+	}
+}
+
+func TestImportShareCodeDisablesExecRules(t *testing.T) {
+	a := newTestApp(t)
+
+	code, err := config.EncodeShareCode(execBundle(t).Profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := a.ReadShareCode(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := a.ImportBundle(bundle, ImportOptions{Mode: ImportAsNew})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExecDisabled != 2 {
+		t.Errorf("ExecDisabled = %d, want 2", result.ExecDisabled)
+	}
+	enabled := enabledBySource(a, result.Profile)
+	if enabled[gamepad.ButtonB] || enabled[gamepad.ButtonX] {
+		t.Error("exec rules from a share code were imported enabled")
+	}
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gamepad-key-mapper/internal/gamepad"
+)
+
+// ErrCaptureTimeout 等待按键超时
+var ErrCaptureTimeout = errors.New("等待按键超时")
+
+// CaptureInput 捕获用户在手柄上按下的按键（阻塞直到按键全部松开、超时或 ctx 取消）
+//
+// 捕获期间所有按键事件都不传给映射引擎和系统组合键。同时按下多个按键（组合键）时，
+// 按按下顺序返回所有按键。映射未启动时会临时启动手柄监听。
+// timeout 内没有按下任何按键时返回 ErrCaptureTimeout。
+func (a *App) CaptureInput(ctx context.Context, timeout time.Duration) ([]gamepad.Button, error) {
+	a.mu.Lock()
+	if a.capturing {
+		a.mu.Unlock()
+		return nil, errors.New("正在等待其它按键输入")
+	}
+	if err := a.acquireListenerLocked(); err != nil {
+		a.mu.Unlock()
+		return nil, err
+	}
+	a.capturing = true
+
+	// 捕获期间收不到释放事件，先释放所有按住的键
+	a.mapper.ReleaseAll()
+	a.system.Reset()
+	a.mu.Unlock()
+
+	events := make(chan gamepad.ButtonEvent, 16)
+	a.listener.SetTap(func(event gamepad.ButtonEvent) bool {
+		select {
+		case events <- event:
+		default:
+		}
+		return true
+	})
+
+	defer func() {
+		a.listener.SetTap(nil)
+
+		a.mu.Lock()
+		a.capturing = false
+		a.releaseListenerLocked()
+		a.system.Reset()
+		a.mu.Unlock()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	held := make(map[gamepad.Button]bool)
+	var pressed []gamepad.Button
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case <-timer.C:
+			if len(pressed) > 0 {
+				return pressed, nil // 一直按住不放时以超时前按下的按键为准
+			}
+			return nil, ErrCaptureTimeout
+
+		case event := <-events:
+			if event.Pressed {
+				if !held[event.Button] {
+					held[event.Button] = true
+					pressed = appendButton(pressed, event.Button)
+				}
+				continue
+			}
+			// 忽略捕获开始前就按住的按键的释放
+			if held[event.Button] {
+				delete(held, event.Button)
+				if len(held) == 0 {
+					return pressed, nil
+				}
+			}
+		}
+	}
+}
+
+// appendButton 添加按键（已存在时不重复添加）
+func appendButton(buttons []gamepad.Button, button gamepad.Button) []gamepad.Button {
+	for _, b := range buttons {
+		if b == button {
+			return buttons
+		}
+	}
+	return append(buttons, button)
+}
@@ -0,0 +1,192 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// eventLogSize 事件日志保留的最大条数
+const eventLogSize = 2000
+
+// EventLog 映射引擎事件日志，只保留最近的事件
+type EventLog struct {
+	mu       sync.Mutex
+	entries  []mapper.TraceEvent // 环形缓冲区
+	next     int                 // 下一条写入的位置
+	full     bool                // 缓冲区是否已写满
+	paused   bool
+	onAppend func(mapper.TraceEvent)
+}
+
+// NewEventLog 创建最多保留 capacity 条事件的日志
+func NewEventLog(capacity int) *EventLog {
+	return &EventLog{entries: make([]mapper.TraceEvent, capacity)}
+}
+
+// Trace 实现 mapper.Tracer，记录一条事件（暂停时丢弃）
+func (l *EventLog) Trace(event mapper.TraceEvent) {
+	l.mu.Lock()
+	if l.paused {
+		l.mu.Unlock()
+		return
+	}
+	l.entries[l.next] = event
+	l.next++
+	if l.next == len(l.entries) {
+		l.next = 0
+		l.full = true
+	}
+	onAppend := l.onAppend
+	l.mu.Unlock()
+
+	if onAppend != nil {
+		onAppend(event)
+	}
+}
+
+// Entries 按时间顺序返回保留的事件
+func (l *EventLog) Entries() []mapper.TraceEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.full {
+		return append([]mapper.TraceEvent(nil), l.entries[:l.next]...)
+	}
+	entries := make([]mapper.TraceEvent, 0, len(l.entries))
+	entries = append(entries, l.entries[l.next:]...)
+	return append(entries, l.entries[:l.next]...)
+}
+
+// Clear 清空日志
+func (l *EventLog) Clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	clear(l.entries)
+	l.next = 0
+	l.full = false
+}
+
+// SetPaused 暂停或继续记录
+func (l *EventLog) SetPaused(paused bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.paused = paused
+}
+
+// Paused 检查是否暂停记录
+func (l *EventLog) Paused() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.paused
+}
+
+// SetOnAppend 设置记录新事件的回调
+//
+// 回调在映射引擎的协程中同步调用，不应阻塞，也不应调用 App 的方法。
+func (l *EventLog) SetOnAppend(callback func(mapper.TraceEvent)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onAppend = callback
+}
+
+// Export 将符合条件的事件按时间顺序逐行写入文件
+func (l *EventLog) Export(path string, filter EventFilter) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("导出事件日志失败: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, event := range filter.Apply(l.Entries()) {
+		fmt.Fprintln(w, event.String())
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("导出事件日志失败: %w", err)
+	}
+	return f.Close()
+}
+
+// EventFilter 事件日志过滤条件（零值不过滤）
+type EventFilter struct {
+	Kinds []mapper.TraceKind // 只保留这些类型（空表示全部）
+	Text  string             // 只保留描述中包含该文字的事件（不区分大小写）
+}
+
+// Match 检查事件是否符合条件
+func (f EventFilter) Match(event mapper.TraceEvent) bool {
+	if len(f.Kinds) > 0 {
+		found := false
+		for _, kind := range f.Kinds {
+			if kind == event.Kind {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Text != "" && !strings.Contains(strings.ToLower(event.String()), strings.ToLower(f.Text)) {
+		return false
+	}
+	return true
+}
+
+// Apply 返回符合条件的事件
+func (f EventFilter) Apply(events []mapper.TraceEvent) []mapper.TraceEvent {
+	var matched []mapper.TraceEvent
+	for _, event := range events {
+		if f.Match(event) {
+			matched = append(matched, event)
+		}
+	}
+	return matched
+}
+
+// EventLog 返回映射引擎的事件日志
+func (a *App) EventLog() *EventLog {
+	return a.eventLog
+}
+
+// tracerEntry 跟踪事件观察者（用指针区分重复添加的同一观察者）
+type tracerEntry struct {
+	tracer mapper.Tracer
+}
+
+// AddTracer 添加映射引擎跟踪事件的观察者（如直播叠加层），返回用于移除的函数
+//
+// 事件日志暂停时观察者仍会收到事件。Trace 的调用约束与 mapper.Tracer 相同：不应阻塞，也不应调用 App 的方法。
+func (a *App) AddTracer(tracer mapper.Tracer) func() {
+	entry := &tracerEntry{tracer: tracer}
+	a.tracersMu.Lock()
+	a.tracers = append(a.tracers, entry)
+	a.tracersMu.Unlock()
+
+	return func() {
+		a.tracersMu.Lock()
+		defer a.tracersMu.Unlock()
+		for i, e := range a.tracers {
+			if e == entry {
+				a.tracers = append(a.tracers[:i:i], a.tracers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// trace 将映射引擎的跟踪事件分发给事件日志和其它观察者
+func (a *App) trace(event mapper.TraceEvent) {
+	a.eventLog.Trace(event)
+
+	a.tracersMu.RLock()
+	defer a.tracersMu.RUnlock()
+	for _, e := range a.tracers {
+		e.tracer.Trace(event)
+	}
+}
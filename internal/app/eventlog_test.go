@@ -0,0 +1,134 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// inputEvent 创建按下指定按键的输入事件
+func inputEvent(b gamepad.Button) mapper.TraceEvent {
+	return mapper.TraceEvent{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Kind: mapper.TraceInput, Button: b, Pressed: true}
+}
+
+// entryButtons 返回日志中各事件的按键
+func entryButtons(l *EventLog) []gamepad.Button {
+	var buttons []gamepad.Button
+	for _, e := range l.Entries() {
+		buttons = append(buttons, e.Button)
+	}
+	return buttons
+}
+
+func TestEventLogKeepsMostRecent(t *testing.T) {
+	l := NewEventLog(3)
+	if got := l.Entries(); len(got) != 0 {
+		t.Fatalf("new log has %d entries", len(got))
+	}
+
+	l.Trace(inputEvent(gamepad.ButtonA))
+	l.Trace(inputEvent(gamepad.ButtonB))
+	if got, want := entryButtons(l), []gamepad.Button{gamepad.ButtonA, gamepad.ButtonB}; !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+
+	// 写满后覆盖最早的事件，仍按时间顺序返回
+	for _, b := range []gamepad.Button{gamepad.ButtonX, gamepad.ButtonY, gamepad.ButtonLB} {
+		l.Trace(inputEvent(b))
+	}
+	if got, want := entryButtons(l), []gamepad.Button{gamepad.ButtonX, gamepad.ButtonY, gamepad.ButtonLB}; !reflect.DeepEqual(got, want) {
+		t.Errorf("entries after wrap = %v, want %v", got, want)
+	}
+
+	l.Clear()
+	l.Trace(inputEvent(gamepad.ButtonRB))
+	if got, want := entryButtons(l), []gamepad.Button{gamepad.ButtonRB}; !reflect.DeepEqual(got, want) {
+		t.Errorf("entries after clear = %v, want %v", got, want)
+	}
+}
+
+func TestEventLogPause(t *testing.T) {
+	l := NewEventLog(10)
+	var appended []gamepad.Button
+	l.SetOnAppend(func(e mapper.TraceEvent) { appended = append(appended, e.Button) })
+
+	l.Trace(inputEvent(gamepad.ButtonA))
+	l.SetPaused(true)
+	if !l.Paused() {
+		t.Fatal("Paused() = false after SetPaused(true)")
+	}
+	l.Trace(inputEvent(gamepad.ButtonB))
+	l.SetPaused(false)
+	l.Trace(inputEvent(gamepad.ButtonX))
+
+	want := []gamepad.Button{gamepad.ButtonA, gamepad.ButtonX}
+	if got := entryButtons(l); !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(appended, want) {
+		t.Errorf("appended = %v, want %v", appended, want)
+	}
+}
+
+func TestEventFilter(t *testing.T) {
+	events := []mapper.TraceEvent{
+		inputEvent(gamepad.ButtonA),
+		{Kind: mapper.TraceMatch, Button: gamepad.ButtonA, Pressed: true, RuleID: "Jump"},
+		{Kind: mapper.TraceSkip, Button: gamepad.ButtonB, Pressed: true, Reason: mapper.SkipNoRule},
+		inputEvent(gamepad.ButtonB),
+	}
+
+	tests := []struct {
+		name   string
+		filter EventFilter
+		want   []int // 符合条件的事件下标
+	}{
+		{"zero value", EventFilter{}, []int{0, 1, 2, 3}},
+		{"kind", EventFilter{Kinds: []mapper.TraceKind{mapper.TraceInput}}, []int{0, 3}},
+		{"kinds", EventFilter{Kinds: []mapper.TraceKind{mapper.TraceMatch, mapper.TraceSkip}}, []int{1, 2}},
+		{"text ignores case", EventFilter{Text: "jump"}, []int{1}},
+		{"kind and text", EventFilter{Kinds: []mapper.TraceKind{mapper.TraceInput}, Text: "B 按下"}, []int{3}},
+		{"no match", EventFilter{Text: "nothing"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want []mapper.TraceEvent
+			for _, i := range tt.want {
+				want = append(want, events[i])
+			}
+			if got := tt.filter.Apply(events); !reflect.DeepEqual(got, want) {
+				t.Errorf("Apply() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestEventLogExport(t *testing.T) {
+	l := NewEventLog(10)
+	l.Trace(inputEvent(gamepad.ButtonA))
+	l.Trace(mapper.TraceEvent{Kind: mapper.TraceReleaseAll})
+	l.Trace(inputEvent(gamepad.ButtonB))
+
+	path := filepath.Join(t.TempDir(), "events.txt")
+	if err := l.Export(path, EventFilter{Kinds: []mapper.TraceKind{mapper.TraceInput}}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "00:00:00.000 输入 A 按下\n00:00:00.000 输入 B 按下\n"
+	if string(data) != want {
+		t.Errorf("exported:\n%s\nwant:\n%s", data, want)
+	}
+
+	if err := l.Export(filepath.Join(t.TempDir(), "missing", "events.txt"), EventFilter{}); err == nil || !strings.Contains(err.Error(), "导出事件日志失败") {
+		t.Errorf("export to missing dir: err = %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"time"
+
+	"gamepad-key-mapper/internal/gamepad"
+)
+
+// WatchInput 订阅手柄状态快照（用于状态显示），每 interval 最多一次
+//
+// 映射未启动时会临时启动手柄监听。返回的函数用于取消订阅，不再使用时必须调用。
+func (a *App) WatchInput(interval time.Duration) (<-chan gamepad.Snapshot, func(), error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.acquireListenerLocked(); err != nil {
+		return nil, nil, err
+	}
+
+	states, cancel := a.listener.WatchState(interval)
+	var stopped bool
+	return states, func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if stopped {
+			return
+		}
+		stopped = true
+		cancel()
+		a.releaseListenerLocked()
+	}, nil
+}
+
+// acquireListenerLocked 映射以外的功能开始使用手柄监听（调用方需持有锁）
+func (a *App) acquireListenerLocked() error {
+	if err := a.listener.Start(); err != nil {
+		return err
+	}
+	a.listenerUsers++
+	return nil
+}
+
+// releaseListenerLocked 映射以外的功能不再使用手柄监听，映射也未启动时停止监听（调用方需持有锁）
+func (a *App) releaseListenerLocked() {
+	a.listenerUsers--
+	if a.listenerUsers == 0 && a.state == StateStopped {
+		a.listener.Stop()
+	}
+}
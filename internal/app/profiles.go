@@ -0,0 +1,286 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gamepad-key-mapper/internal/config"
+	"gamepad-key-mapper/internal/window"
+)
+
+// watchInterval 前台窗口轮询间隔
+const watchInterval = 500 * time.Millisecond
+
+// ActiveProfile 返回当前方案名称
+func (a *App) ActiveProfile() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.cfg.Active().Name
+}
+
+// ProfileNames 返回所有方案名称
+func (a *App) ProfileNames() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.cfg.ProfileNames()
+}
+
+// ActivateProfile 切换到指定方案（先释放所有按住的键）
+func (a *App) ActivateProfile(name string) error {
+	a.mu.Lock()
+	target := a.cfg.Profile(name)
+	if target == nil {
+		a.mu.Unlock()
+		return fmt.Errorf("方案 %s 不存在", name)
+	}
+	if a.cfg.Active() == target {
+		a.mu.Unlock()
+		return nil
+	}
+
+	// 保存当前方案的规则后再切换
+	a.syncActiveProfileLocked()
+	a.cfg.ActiveProfile = name
+	a.mapper.ReplaceRules(target.Rules, target.AxisRules)
+	a.mu.Unlock()
+
+	// 自动保存配置
+	a.autoSave()
+
+	if a.onProfileChange != nil {
+		a.onProfileChange(name)
+	}
+	if a.onRulesChange != nil {
+		a.onRulesChange()
+	}
+
+	return nil
+}
+
+// CreateProfile 创建空方案
+func (a *App) CreateProfile(name string) error {
+	name = strings.TrimSpace(name)
+
+	a.mu.Lock()
+	if err := a.checkProfileNameLocked(name); err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	a.cfg.Profiles = append(a.cfg.Profiles, config.NewProfile(name))
+	a.mu.Unlock()
+
+	return a.saveProfiles()
+}
+
+// RenameProfile 重命名方案
+func (a *App) RenameProfile(oldName, newName string) error {
+	newName = strings.TrimSpace(newName)
+	if oldName == newName {
+		return nil
+	}
+
+	a.mu.Lock()
+	p := a.cfg.Profile(oldName)
+	if p == nil {
+		a.mu.Unlock()
+		return fmt.Errorf("方案 %s 不存在", oldName)
+	}
+	if err := a.checkProfileNameLocked(newName); err != nil {
+		a.mu.Unlock()
+		return err
+	}
+
+	p.Name = newName
+	if a.cfg.ActiveProfile == oldName {
+		a.cfg.ActiveProfile = newName
+	}
+	if a.cfg.DefaultProfile == oldName {
+		a.cfg.DefaultProfile = newName
+	}
+	a.mu.Unlock()
+
+	return a.saveProfiles()
+}
+
+// DuplicateProfile 复制方案（包括规则和匹配条件）
+func (a *App) DuplicateProfile(srcName, newName string) error {
+	newName = strings.TrimSpace(newName)
+
+	a.mu.Lock()
+	src := a.cfg.Profile(srcName)
+	if src == nil {
+		a.mu.Unlock()
+		return fmt.Errorf("方案 %s 不存在", srcName)
+	}
+	if err := a.checkProfileNameLocked(newName); err != nil {
+		a.mu.Unlock()
+		return err
+	}
+
+	// 复制前先同步当前方案，保证复制的是最新规则
+	a.syncActiveProfileLocked()
+	clone, err := src.Clone(newName)
+	if err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	a.cfg.Profiles = append(a.cfg.Profiles, clone)
+	a.mu.Unlock()
+
+	return a.saveProfiles()
+}
+
+// DeleteProfile 删除方案（不能删除最后一个方案，删除当前方案时切换到第一个剩余方案）
+func (a *App) DeleteProfile(name string) error {
+	a.mu.Lock()
+	if len(a.cfg.Profiles) <= 1 {
+		a.mu.Unlock()
+		return fmt.Errorf("至少需要保留一个方案")
+	}
+
+	idx := -1
+	for i, p := range a.cfg.Profiles {
+		if p.Name == name {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		a.mu.Unlock()
+		return fmt.Errorf("方案 %s 不存在", name)
+	}
+
+	wasActive := a.cfg.Active().Name == name
+	a.cfg.Profiles = append(a.cfg.Profiles[:idx], a.cfg.Profiles[idx+1:]...)
+	if a.cfg.DefaultProfile == name {
+		a.cfg.DefaultProfile = ""
+	}
+	if wasActive {
+		// 先释放按住的键，再切换到剩余的第一个方案
+		next := a.cfg.Profiles[0]
+		a.cfg.ActiveProfile = next.Name
+		a.mapper.ReplaceRules(next.Rules, next.AxisRules)
+	}
+	a.mu.Unlock()
+
+	if wasActive && a.onRulesChange != nil {
+		a.onRulesChange()
+	}
+	return a.saveProfiles()
+}
+
+// checkProfileNameLocked 检查方案名称是否可用（调用方需持有锁）
+func (a *App) checkProfileNameLocked(name string) error {
+	if name == "" {
+		return fmt.Errorf("方案名称不能为空")
+	}
+	if a.cfg.Profile(name) != nil {
+		return fmt.Errorf("方案 %s 已存在", name)
+	}
+	return nil
+}
+
+// saveProfiles 保存配置并通知方案列表变更
+func (a *App) saveProfiles() error {
+	err := a.SaveConfig()
+
+	if a.onProfileChange != nil {
+		a.onProfileChange(a.ActiveProfile())
+	}
+	return err
+}
+
+// SetProfileMatch 设置方案的自动切换匹配条件
+func (a *App) SetProfileMatch(name string, matchers []window.Matcher) error {
+	for _, m := range matchers {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("匹配条件 %s 无效: %w", m.String(), err)
+		}
+	}
+
+	a.mu.Lock()
+	p := a.cfg.Profile(name)
+	if p == nil {
+		a.mu.Unlock()
+		return fmt.Errorf("方案 %s 不存在", name)
+	}
+	p.Match = matchers
+	a.mu.Unlock()
+
+	return a.SaveConfig()
+}
+
+// SetDefaultProfile 设置自动切换无匹配时使用的方案
+func (a *App) SetDefaultProfile(name string) error {
+	a.mu.Lock()
+	if a.cfg.Profile(name) == nil {
+		a.mu.Unlock()
+		return fmt.Errorf("方案 %s 不存在", name)
+	}
+	a.cfg.DefaultProfile = name
+	a.mu.Unlock()
+
+	return a.SaveConfig()
+}
+
+// AutoSwitchEnabled 检查是否启用了自动切换
+func (a *App) AutoSwitchEnabled() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.cfg.AutoSwitch
+}
+
+// SetAutoSwitch 启用/禁用根据前台窗口自动切换方案
+func (a *App) SetAutoSwitch(enabled bool) error {
+	a.mu.Lock()
+	a.cfg.AutoSwitch = enabled
+	if a.state != StateStopped {
+		if enabled {
+			a.startWatcherLocked()
+		} else {
+			a.stopWatcherLocked()
+		}
+	}
+	a.mu.Unlock()
+
+	return a.SaveConfig()
+}
+
+// SetWindowSource 设置前台窗口信息来源（用于替换平台实现）
+func (a *App) SetWindowSource(source window.Source) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.windowSource = source
+}
+
+// startWatcherLocked 启动前台窗口监视（调用方需持有锁）
+func (a *App) startWatcherLocked() {
+	if a.watcher != nil && a.watcher.IsRunning() {
+		return
+	}
+	a.watcher = window.NewWatcher(a.windowSource, watchInterval, a.onForegroundChange)
+	a.watcher.Start()
+}
+
+// stopWatcherLocked 停止前台窗口监视（调用方需持有锁）
+func (a *App) stopWatcherLocked() {
+	if a.watcher != nil {
+		a.watcher.Stop()
+		a.watcher = nil
+	}
+}
+
+// onForegroundChange 前台窗口变化时切换到匹配的方案
+func (a *App) onForegroundChange(info window.Info) {
+	a.mu.RLock()
+	name := a.cfg.MatchProfile(info)
+	a.mu.RUnlock()
+
+	if name == "" {
+		return
+	}
+	if err := a.ActivateProfile(name); err != nil {
+		a.reportError(err)
+	}
+}
@@ -0,0 +1,227 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gamepad-key-mapper/internal/config"
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/keyboard"
+	"gamepad-key-mapper/internal/mapper"
+	"gamepad-key-mapper/internal/window"
+)
+
+// newTestApp 创建配置保存到临时目录的应用实例
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	if err := config.SetConfigPath(filepath.Join(t.TempDir(), "config.json")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { config.SetConfigPath("") })
+
+	a, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// staticSource 总是返回同一个窗口的前台窗口信息来源
+type staticSource struct {
+	mu   sync.Mutex
+	info window.Info
+}
+
+func (s *staticSource) Foreground() (window.Info, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.info, nil
+}
+
+func (s *staticSource) set(info window.Info) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.info = info
+}
+
+// eventRecorder 按顺序记录跟踪事件和方案切换
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *eventRecorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func TestWatcherReleasesHeldKeysBeforeSwitching(t *testing.T) {
+	a := newTestApp(t)
+	source := &staticSource{info: window.Info{Process: "explorer.exe"}}
+	a.SetWindowSource(source)
+
+	// 手柄到手柄的规则不产生真实的键盘输出，只通过跟踪事件观察按住状态
+	desktop := config.NewProfile("desktop")
+	desktop.Rules = []*mapper.MappingRule{mapper.NewRuleGamepad("desktop_a", gamepad.ButtonA, []gamepad.Button{gamepad.ButtonB})}
+	game := config.NewProfile("game")
+	game.Rules = []*mapper.MappingRule{mapper.NewRuleGamepad("game_a", gamepad.ButtonA, []gamepad.Button{gamepad.ButtonX})}
+	game.Match = []window.Matcher{{Field: window.MatchProcess, Mode: window.MatchGlob, Pattern: "game*.exe"}}
+
+	cfg := config.NewDefault()
+	cfg.Profiles = []*config.Profile{desktop, game}
+	cfg.ActiveProfile = "desktop"
+	cfg.DefaultProfile = "desktop"
+	a.applyConfig(cfg)
+
+	rec := &eventRecorder{}
+	a.AddTracer(mapper.TracerFunc(func(event mapper.TraceEvent) {
+		switch event.Kind {
+		case mapper.TraceMatch:
+			rec.add(fmt.Sprintf("match %s %v", event.RuleID, event.Pressed))
+		case mapper.TraceSkip:
+			rec.add(fmt.Sprintf("skip %s %v: %s", event.Button, event.Pressed, event.Reason))
+		case mapper.TraceReleaseAll:
+			rec.add("release all")
+		}
+	}))
+	switched := make(chan string, 10)
+	a.SetOnProfileChange(func(name string) {
+		rec.add("switch " + name)
+		switched <- name
+	})
+
+	a.mu.Lock()
+	a.startWatcherLocked()
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.stopWatcherLocked()
+		a.mu.Unlock()
+	}()
+
+	a.mapper.HandleEvent(gamepad.ButtonEvent{Button: gamepad.ButtonA, Pressed: true})
+	source.set(window.Info{Process: "game-x64.exe"})
+
+	select {
+	case name := <-switched:
+		if name != "game" {
+			t.Fatalf("switched to %q, want game", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the watcher to switch profiles")
+	}
+
+	// 切换前按下的 A 在新方案中没有对应的按下，释放被忽略
+	a.mapper.HandleEvent(gamepad.ButtonEvent{Button: gamepad.ButtonA, Pressed: false})
+
+	want := []string{
+		"match desktop_a true",
+		"skip B true: 没有规则",
+		"release all",
+		"switch game",
+		"skip A false: 没有对应的按下",
+	}
+	got := rec.list()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+	if active := a.ActiveProfile(); active != "game" {
+		t.Errorf("active profile = %q, want game", active)
+	}
+}
+
+func TestForegroundChangeFallsBackToDefault(t *testing.T) {
+	a := newTestApp(t)
+
+	game := config.NewProfile("game")
+	game.Match = []window.Matcher{{Field: window.MatchTitle, Mode: window.MatchRegex, Pattern: `^Game\b`}}
+	cfg := config.NewDefault()
+	cfg.Profiles = []*config.Profile{config.NewProfile("first"), game, config.NewProfile("fallback")}
+	cfg.ActiveProfile = "first"
+	cfg.DefaultProfile = "fallback"
+	a.applyConfig(cfg)
+
+	tests := []struct {
+		info window.Info
+		want string
+	}{
+		{window.Info{Process: "game.exe", Title: "Game Window"}, "game"},
+		{window.Info{Process: "notepad.exe", Title: "Untitled"}, "fallback"},
+		{window.Info{Process: "game.exe", Title: "Launcher for Game"}, "fallback"},
+	}
+
+	for _, tt := range tests {
+		a.onForegroundChange(tt.info)
+		if got := a.ActiveProfile(); got != tt.want {
+			t.Errorf("after foreground %+v active profile = %q, want %q", tt.info, got, tt.want)
+		}
+	}
+}
+
+func TestSaveConfigConcurrentWithProfileEdits(t *testing.T) {
+	a := newTestApp(t)
+	rule, err := a.AddMappingRule(mapper.NewRule("", gamepad.ButtonA, keyboard.KeyCode(0x41), keyboard.Modifiers{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.CreateProfile("p0"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 保存在锁外编码配置，同时修改方案名称和规则说明（用 -race 运行时检查数据竞争）
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			if err := a.SaveConfig(); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			if err := a.RenameProfile(fmt.Sprintf("p%d", i), fmt.Sprintf("p%d", i+1)); err != nil {
+				t.Error(err)
+				return
+			}
+			if err := a.SetRuleInfo(rule.ID, fmt.Sprintf("rule %d", i), ""); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	if err := a.SaveConfig(); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Profile("p20") == nil {
+		t.Errorf("saved profiles = %v, want p20", profileNames(cfg))
+	}
+}
+
+// profileNames 返回配置中的方案名称
+func profileNames(cfg *config.Config) []string {
+	var names []string
+	for _, p := range cfg.Profiles {
+		names = append(names, p.Name)
+	}
+	return names
+}
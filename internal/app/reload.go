@@ -0,0 +1,70 @@
+package app
+
+import (
+	"log/slog"
+
+	"gamepad-key-mapper/internal/config"
+)
+
+// WatchConfig 开始监视配置文件，文件被外部修改（手工编辑、同步）时自动重新加载
+func (a *App) WatchConfig() error {
+	path, err := config.GetConfigPath()
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cfgWatcher != nil && a.cfgWatcher.IsRunning() && a.cfgWatcher.Path() == path {
+		return nil
+	}
+	a.stopConfigWatcherLocked()
+
+	watcher := config.NewWatcher(path, a.onConfigFileChange, a.reportError)
+	if err := watcher.Start(); err != nil {
+		return err
+	}
+	a.cfgWatcher = watcher
+	return nil
+}
+
+// StopWatchingConfig 停止监视配置文件
+func (a *App) StopWatchingConfig() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopConfigWatcherLocked()
+}
+
+// stopConfigWatcherLocked 停止配置文件监视（调用方需持有锁）
+func (a *App) stopConfigWatcherLocked() {
+	if a.cfgWatcher != nil {
+		a.cfgWatcher.Stop()
+		a.cfgWatcher = nil
+	}
+}
+
+// onConfigFileChange 应用外部修改后的配置（已通过校验；替换规则时释放所有按住的键）
+func (a *App) onConfigFileChange(cfg *config.Config) {
+	a.applyConfig(cfg)
+
+	if a.onProfileChange != nil {
+		a.onProfileChange(a.ActiveProfile())
+	}
+	if a.onRulesChange != nil {
+		a.onRulesChange()
+	}
+}
+
+// reportError 记录后台错误并通过错误回调报告
+func (a *App) reportError(err error) {
+	slog.Error("app error", "err", err)
+	a.notifyError(err)
+}
+
+// notifyError 通过错误回调报告错误（不记录日志）
+func (a *App) notifyError(err error) {
+	if a.onError != nil {
+		a.onError(err)
+	}
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gamepad-key-mapper/internal/config"
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/keyboard"
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// reloadWait 等待配置监视器处理文件事件的时间（防抖 300 ms 之后再留出余量）
+const reloadWait = time.Second
+
+func TestWatchConfigIgnoresOwnSaves(t *testing.T) {
+	a := newTestApp(t)
+	path, err := config.GetConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SaveConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	var reloads, errs atomic.Int32
+	a.SetOnRulesChange(func() { reloads.Add(1) })
+	a.SetOnError(func(error) { errs.Add(1) })
+	if err := a.WatchConfig(); err != nil {
+		t.Fatal(err)
+	}
+	defer a.StopWatchingConfig()
+
+	// 程序自己的保存不触发重新加载
+	if _, err := a.AddMappingRule(mapper.NewRule("", gamepad.ButtonX, keyboard.KeyCode(0x42), keyboard.Modifiers{})); err != nil {
+		t.Fatal(err)
+	}
+	reloads.Store(0) // 添加规则本身会通知一次
+	if err := a.SaveConfig(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(reloadWait)
+	if n := reloads.Load(); n != 0 {
+		t.Fatalf("reloads after SaveConfig = %d, want 0", n)
+	}
+
+	// 外部修改：分两次写入（中间状态不是合法的配置），防抖后只重新加载一次
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.DecodeFormat(data, config.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Active().Rules = append(cfg.Active().Rules,
+		mapper.NewRule("external", gamepad.ButtonY, keyboard.KeyCode(0x41), keyboard.Modifiers{}))
+	edited, err := config.Marshal(cfg, config.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, edited[:len(edited)/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(path, edited, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(reloadWait)
+	if n := reloads.Load(); n != 1 {
+		t.Fatalf("reloads after external write = %d, want 1", n)
+	}
+	if n := errs.Load(); n != 0 {
+		t.Errorf("errors reported = %d, want 0", n)
+	}
+	if a.mapper.GetRuleByID("external") == nil {
+		t.Error("externally added rule not loaded")
+	}
+
+	// 重新加载后的自动保存也不会再次触发
+	time.Sleep(reloadWait)
+	if n := reloads.Load(); n != 1 {
+		t.Errorf("reloads after settling = %d, want 1", n)
+	}
+}
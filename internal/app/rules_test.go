@@ -0,0 +1,157 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"gamepad-key-mapper/internal/config"
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/keyboard"
+	"gamepad-key-mapper/internal/mapper"
+)
+
+func TestRulesAllowDisabledAlternatesForSameSource(t *testing.T) {
+	a := newTestApp(t)
+
+	primary, err := a.AddRule(gamepad.ButtonA, keyboard.KeyCode(0x41), keyboard.Modifiers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.AddRule(gamepad.ButtonA, keyboard.KeyCode(0x42), keyboard.Modifiers{}); err == nil {
+		t.Fatal("second enabled rule for the same source was accepted")
+	}
+
+	// 停用的备选规则可以添加
+	alt := mapper.NewRule("", gamepad.ButtonA, keyboard.KeyCode(0x42), keyboard.Modifiers{})
+	alt.Enabled = false
+	alt, err = a.AddMappingRule(alt)
+	if err != nil {
+		t.Fatalf("disabled alternate rejected: %v", err)
+	}
+
+	// 启用备选规则前必须先停用原规则
+	if err := a.SetRuleEnabled(alt.ID, true); err == nil || !strings.Contains(err.Error(), "已存在启用的映射规则") {
+		t.Fatalf("enabling alternate: err = %v, want conflict", err)
+	}
+	if err := a.SetRuleEnabled(primary.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SetRuleEnabled(alt.ID, true); err != nil {
+		t.Fatalf("enabling alternate after disabling primary: %v", err)
+	}
+
+	// 修改停用的规则不检查冲突，修改启用的规则仍然检查
+	edited := *primary
+	edited.Enabled = false
+	edited.TargetKeys = []keyboard.KeyCode{0x43}
+	if err := a.UpdateRule(&edited); err != nil {
+		t.Fatalf("updating disabled rule: %v", err)
+	}
+	edited.Enabled = true
+	if err := a.UpdateRule(&edited); err == nil {
+		t.Fatal("update enabled a second rule for the same source")
+	}
+}
+
+func TestManualSaveSkipsAutoSave(t *testing.T) {
+	a := newTestApp(t)
+	a.SetManualSave(true)
+	path, err := config.GetConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.AddRule(gamepad.ButtonA, keyboard.KeyCode(0x41), keyboard.Modifiers{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("config written before SaveConfig: stat err = %v", err)
+	}
+
+	if err := a.SaveConfig(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules := loaded.Active().Rules; len(rules) != 1 || rules[0].SourceKey != gamepad.ButtonA {
+		t.Errorf("saved rules = %v, want the added rule", rules)
+	}
+}
+
+func TestUpdateRuleReleasesHeldOutputs(t *testing.T) {
+	retarget := func(rule *mapper.MappingRule) { rule.TargetButtons = []gamepad.Button{gamepad.ButtonY} }
+	rename := func(rule *mapper.MappingRule) { rule.Name = "renamed" }
+
+	// 手柄到手柄的规则不产生真实的键盘输出，通过跟踪事件观察释放
+	tests := []struct {
+		name string
+		held bool
+		edit func(*mapper.MappingRule)
+		want []string
+	}{
+		{
+			name: "retarget held rule",
+			held: true,
+			edit: retarget,
+			want: []string{
+				"match ab true", "match b true", "skip X true: 没有规则",
+				"match b false", "skip X false: 没有规则", // 修改时释放旧目标
+				"skip A false: 没有对应的按下", // 之后的释放不再作用于新规则
+			},
+		},
+		{
+			name: "rename held rule",
+			held: true,
+			edit: rename,
+			want: []string{
+				"match ab true", "match b true", "skip X true: 没有规则",
+				"match ab false", "match b false", "skip X false: 没有规则", // 按住状态保留，由松开释放
+			},
+		},
+		{
+			name: "retarget released rule",
+			edit: retarget,
+			want: []string{
+				"skip A false: 没有对应的按下",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			a.SetManualSave(true)
+			ab := mapper.NewRuleGamepad("ab", gamepad.ButtonA, []gamepad.Button{gamepad.ButtonB})
+			b := mapper.NewRuleGamepad("b", gamepad.ButtonB, []gamepad.Button{gamepad.ButtonX})
+			a.mapper.SetRules([]*mapper.MappingRule{ab, b})
+
+			rec := &eventRecorder{}
+			a.AddTracer(mapper.TracerFunc(func(event mapper.TraceEvent) {
+				switch event.Kind {
+				case mapper.TraceMatch:
+					rec.add(fmt.Sprintf("match %s %v", event.RuleID, event.Pressed))
+				case mapper.TraceSkip:
+					rec.add(fmt.Sprintf("skip %s %v: %s", event.Button, event.Pressed, event.Reason))
+				}
+			}))
+
+			if tt.held {
+				a.mapper.HandleEvent(gamepad.ButtonEvent{Button: gamepad.ButtonA, Pressed: true})
+			}
+			edited := *ab
+			tt.edit(&edited)
+			if err := a.UpdateRule(&edited); err != nil {
+				t.Fatal(err)
+			}
+			a.mapper.HandleEvent(gamepad.ButtonEvent{Button: gamepad.ButtonA, Pressed: false})
+
+			if got := rec.list(); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("events = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
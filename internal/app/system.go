@@ -0,0 +1,320 @@
+package app
+
+import (
+	"sync"
+	"time"
+
+	"gamepad-key-mapper/internal/config"
+	"gamepad-key-mapper/internal/gamepad"
+)
+
+// systemComboWindow 组合键按键的暂缓时间：按下组合键中的按键后，在此时间内组合键没有成立
+// 时才把按下事件转发给映射引擎
+const systemComboWindow = 200 * time.Millisecond
+
+// systemFilter 系统组合键过滤器（在映射引擎之前处理按键事件）
+//
+// 属于组合键的按键按下后先暂缓转发：组合键在 systemComboWindow 内成立时这些按键被吞掉，
+// 不会触发各自映射的输出；超时或组合键没有成立就松开时，按原顺序补发。组合键成立后
+// 按住达到指定时长触发动作，提前松开时补发这些按键，它们的映射照常生效。
+type systemFilter struct {
+	bindings []config.SystemBinding
+	fire     func(config.SystemAction)
+	forward  func([]gamepad.ButtonEvent) // 转发给映射引擎
+
+	held      map[gamepad.Button]bool // 当前按住的按键
+	pending   []gamepad.ButtonEvent   // 暂缓转发的按下事件（按时间顺序）
+	swallowed map[gamepad.Button]bool // 被组合键吞掉的按键（松开前不转发）
+	armed     int                     // 正在计时的组合键索引（-1 表示无）
+	timer     *time.Timer
+	gen       int // 计时代数，用于忽略已取消的计时器
+	window    *time.Timer
+	windowGen int // 暂缓计时代数
+	mu        sync.Mutex
+
+	// outMu 保证转发顺序与处理顺序一致（暂缓超时在计时器协程中转发）
+	outMu sync.Mutex
+}
+
+// newSystemFilter 创建系统组合键过滤器，forward 接收需要转发给映射引擎的事件
+func newSystemFilter(fire func(config.SystemAction), forward func([]gamepad.ButtonEvent)) *systemFilter {
+	return &systemFilter{
+		fire:      fire,
+		forward:   forward,
+		held:      make(map[gamepad.Button]bool),
+		swallowed: make(map[gamepad.Button]bool),
+		armed:     -1,
+	}
+}
+
+// SetBindings 设置系统组合键（同时重置状态）
+func (f *systemFilter) SetBindings(bindings []config.SystemBinding) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.bindings = bindings
+	f.resetLocked()
+}
+
+// Reset 重置按键状态并取消计时（暂缓的按键不再转发）
+func (f *systemFilter) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resetLocked()
+}
+
+// Process 处理按键事件，需要转发的事件交给 forward
+func (f *systemFilter) Process(event gamepad.ButtonEvent) {
+	f.outMu.Lock()
+	defer f.outMu.Unlock()
+
+	f.mu.Lock()
+	out := f.processLocked(event)
+	f.mu.Unlock()
+
+	if len(out) > 0 {
+		f.forward(out)
+	}
+}
+
+// processLocked 处理按键事件，返回需要转发的事件
+func (f *systemFilter) processLocked(event gamepad.ButtonEvent) []gamepad.ButtonEvent {
+	if !event.Pressed {
+		return f.releaseLocked(event)
+	}
+
+	f.held[event.Button] = true
+
+	if idx := f.matchLocked(event.Button); idx >= 0 {
+		return f.completeLocked(idx, event)
+	}
+	if f.swallowed[event.Button] {
+		return nil
+	}
+	if f.inBindingLocked(event.Button) {
+		f.holdLocked(event)
+		return nil
+	}
+	return []gamepad.ButtonEvent{event}
+}
+
+// releaseLocked 处理释放事件
+func (f *systemFilter) releaseLocked(event gamepad.ButtonEvent) []gamepad.ButtonEvent {
+	delete(f.held, event.Button)
+
+	// 组合键没有成立就松开：补发按下后再释放
+	if press, ok := f.takePendingLocked(event.Button); ok {
+		return []gamepad.ButtonEvent{press, event}
+	}
+
+	if !f.swallowed[event.Button] {
+		return []gamepad.ButtonEvent{event}
+	}
+	delete(f.swallowed, event.Button)
+
+	// 动作已触发：释放同样被吞掉
+	if f.armed < 0 || !containsButton(f.bindings[f.armed].Buttons, event.Button) {
+		return nil
+	}
+
+	// 未按满时长就松开：取消计时，补发组合键的按键，仍按住的按键保持按下
+	var out []gamepad.ButtonEvent
+	for _, btn := range f.bindings[f.armed].Buttons {
+		if btn != event.Button && f.held[btn] && f.swallowed[btn] {
+			delete(f.swallowed, btn)
+			out = append(out, gamepad.ButtonEvent{Button: btn, Pressed: true, PlayerID: event.PlayerID})
+		}
+	}
+	f.cancelLocked()
+	press := event
+	press.Pressed = true
+	return append(out, press, event)
+}
+
+// completeLocked 组合键成立：吞掉组合键的按键并开始计时
+func (f *systemFilter) completeLocked(idx int, event gamepad.ButtonEvent) []gamepad.ButtonEvent {
+	var out []gamepad.ButtonEvent
+	for _, btn := range f.bindings[idx].Buttons {
+		_, wasPending := f.takePendingLocked(btn)
+		if btn != event.Button && !wasPending && !f.swallowed[btn] {
+			// 暂缓超时后已转发过的按键补发释放，避免输出残留
+			out = append(out, gamepad.ButtonEvent{Button: btn, Pressed: false, PlayerID: event.PlayerID})
+		}
+		f.swallowed[btn] = true
+	}
+	if len(f.pending) == 0 {
+		f.stopWindowLocked()
+	}
+
+	f.armLocked(idx)
+	return out
+}
+
+// inBindingLocked 检查按键是否属于某个组合键
+func (f *systemFilter) inBindingLocked(button gamepad.Button) bool {
+	for _, binding := range f.bindings {
+		if containsButton(binding.Buttons, button) {
+			return true
+		}
+	}
+	return false
+}
+
+// holdLocked 暂缓转发按下事件，第一个暂缓的按键开始计时
+func (f *systemFilter) holdLocked(event gamepad.ButtonEvent) {
+	f.pending = append(f.pending, event)
+	if f.window != nil {
+		return
+	}
+
+	gen := f.windowGen
+	f.window = time.AfterFunc(systemComboWindow, func() {
+		f.outMu.Lock()
+		defer f.outMu.Unlock()
+
+		f.mu.Lock()
+		if f.windowGen != gen {
+			f.mu.Unlock()
+			return
+		}
+		out := f.pending
+		f.pending = nil
+		f.window = nil
+		f.windowGen++
+		f.mu.Unlock()
+
+		if len(out) > 0 {
+			f.forward(out)
+		}
+	})
+}
+
+// takePendingLocked 取出按键暂缓的按下事件
+func (f *systemFilter) takePendingLocked(button gamepad.Button) (gamepad.ButtonEvent, bool) {
+	for i, ev := range f.pending {
+		if ev.Button == button {
+			f.pending = append(f.pending[:i:i], f.pending[i+1:]...)
+			return ev, true
+		}
+	}
+	return gamepad.ButtonEvent{}, false
+}
+
+// stopWindowLocked 取消暂缓计时
+func (f *systemFilter) stopWindowLocked() {
+	if f.window != nil {
+		f.window.Stop()
+		f.window = nil
+	}
+	f.windowGen++
+}
+
+// matchLocked 查找包含该按键且所有按键均已按住的组合键
+func (f *systemFilter) matchLocked(button gamepad.Button) int {
+	for i, binding := range f.bindings {
+		if len(binding.Buttons) == 0 || !containsButton(binding.Buttons, button) {
+			continue
+		}
+
+		complete := true
+		for _, btn := range binding.Buttons {
+			if !f.held[btn] {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			return i
+		}
+	}
+	return -1
+}
+
+// armLocked 开始组合键计时
+func (f *systemFilter) armLocked(idx int) {
+	f.cancelLocked()
+
+	f.armed = idx
+	gen := f.gen
+	action := f.bindings[idx].Action
+	hold := time.Duration(f.bindings[idx].HoldMs) * time.Millisecond
+
+	f.timer = time.AfterFunc(hold, func() {
+		f.mu.Lock()
+		if f.gen != gen || f.armed != idx {
+			f.mu.Unlock()
+			return
+		}
+		f.armed = -1
+		f.timer = nil
+		f.mu.Unlock()
+
+		f.fire(action)
+	})
+}
+
+// cancelLocked 取消组合键计时
+func (f *systemFilter) cancelLocked() {
+	if f.timer != nil {
+		f.timer.Stop()
+		f.timer = nil
+	}
+	f.armed = -1
+	f.gen++
+}
+
+// resetLocked 重置所有状态
+func (f *systemFilter) resetLocked() {
+	f.cancelLocked()
+	f.stopWindowLocked()
+	f.pending = nil
+	f.held = make(map[gamepad.Button]bool)
+	f.swallowed = make(map[gamepad.Button]bool)
+}
+
+// containsButton 检查按键列表是否包含指定按键
+func containsButton(buttons []gamepad.Button, button gamepad.Button) bool {
+	for _, btn := range buttons {
+		if btn == button {
+			return true
+		}
+	}
+	return false
+}
+
+// runSystemAction 执行系统组合键动作
+func (a *App) runSystemAction(action config.SystemAction) {
+	switch action {
+	case config.SystemToggleMapping:
+		a.TogglePause()
+	case config.SystemNextProfile:
+		a.cycleProfile(1)
+	case config.SystemPrevProfile:
+		a.cycleProfile(-1)
+	case config.SystemShowWindow:
+		if a.onShowWindow != nil {
+			a.onShowWindow()
+		}
+	}
+}
+
+// cycleProfile 按顺序切换到相邻方案
+func (a *App) cycleProfile(step int) {
+	names := a.ProfileNames()
+	if len(names) < 2 {
+		return
+	}
+
+	current := a.ActiveProfile()
+	idx := 0
+	for i, name := range names {
+		if name == current {
+			idx = i
+			break
+		}
+	}
+
+	next := names[(idx+step+len(names))%len(names)]
+	if err := a.ActivateProfile(next); err != nil {
+		a.reportError(err)
+	}
+}
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"gamepad-key-mapper/internal/config"
+	"gamepad-key-mapper/internal/gamepad"
+)
+
+// filterRecorder 记录系统组合键过滤器转发的事件和触发的动作
+type filterRecorder struct {
+	mu      sync.Mutex
+	events  []string
+	actions []config.SystemAction
+}
+
+func (r *filterRecorder) forward(events []gamepad.ButtonEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, ev := range events {
+		r.events = append(r.events, fmt.Sprintf("%s %s", ev.Button, pressedName(ev.Pressed)))
+	}
+}
+
+func (r *filterRecorder) fire(action config.SystemAction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.actions = append(r.actions, action)
+}
+
+func (r *filterRecorder) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := r.events
+	r.events = nil
+	return events
+}
+
+func (r *filterRecorder) fired() []config.SystemAction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]config.SystemAction(nil), r.actions...)
+}
+
+func pressedName(pressed bool) string {
+	if pressed {
+		return "down"
+	}
+	return "up"
+}
+
+func newTestFilter(holdMs int) (*systemFilter, *filterRecorder) {
+	rec := &filterRecorder{}
+	f := newSystemFilter(rec.fire, rec.forward)
+	f.SetBindings([]config.SystemBinding{{
+		Buttons: []gamepad.Button{gamepad.ButtonBack, gamepad.ButtonStart},
+		HoldMs:  holdMs,
+		Action:  config.SystemToggleMapping,
+	}})
+	return f, rec
+}
+
+func press(f *systemFilter, b gamepad.Button) {
+	f.Process(gamepad.ButtonEvent{Button: b, Pressed: true})
+}
+func release(f *systemFilter, b gamepad.Button) {
+	f.Process(gamepad.ButtonEvent{Button: b, Pressed: false})
+}
+
+func expectEvents(t *testing.T, rec *filterRecorder, want ...string) {
+	t.Helper()
+	if got := rec.take(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("forwarded %q, want %q", got, want)
+	}
+}
+
+// waitWindow 等待暂缓时间结束
+func waitWindow() {
+	time.Sleep(systemComboWindow + 50*time.Millisecond)
+}
+
+func TestSystemFilterForwardsOtherButtons(t *testing.T) {
+	f, rec := newTestFilter(1000)
+
+	press(f, gamepad.ButtonA)
+	release(f, gamepad.ButtonA)
+	expectEvents(t, rec, "A down", "A up")
+}
+
+func TestSystemFilterTapReplaysPress(t *testing.T) {
+	f, rec := newTestFilter(1000)
+
+	press(f, gamepad.ButtonBack)
+	expectEvents(t, rec)
+	release(f, gamepad.ButtonBack)
+	expectEvents(t, rec, "View down", "View up")
+
+	waitWindow()
+	expectEvents(t, rec)
+}
+
+func TestSystemFilterReplaysAfterWindow(t *testing.T) {
+	f, rec := newTestFilter(1000)
+
+	press(f, gamepad.ButtonBack)
+	waitWindow()
+	expectEvents(t, rec, "View down")
+
+	release(f, gamepad.ButtonBack)
+	expectEvents(t, rec, "View up")
+}
+
+func TestSystemFilterSwallowsCompletedCombo(t *testing.T) {
+	f, rec := newTestFilter(30)
+
+	press(f, gamepad.ButtonBack)
+	press(f, gamepad.ButtonStart)
+	time.Sleep(100 * time.Millisecond)
+	release(f, gamepad.ButtonStart)
+	release(f, gamepad.ButtonBack)
+
+	waitWindow()
+	expectEvents(t, rec)
+	if got := rec.fired(); len(got) != 1 || got[0] != config.SystemToggleMapping {
+		t.Errorf("fired %v, want one toggle", got)
+	}
+}
+
+func TestSystemFilterEarlyReleaseReplaysCombo(t *testing.T) {
+	f, rec := newTestFilter(10000)
+
+	press(f, gamepad.ButtonBack)
+	press(f, gamepad.ButtonStart)
+	expectEvents(t, rec)
+
+	release(f, gamepad.ButtonBack)
+	expectEvents(t, rec, "Menu down", "View down", "View up")
+	release(f, gamepad.ButtonStart)
+	expectEvents(t, rec, "Menu up")
+
+	if got := rec.fired(); len(got) != 0 {
+		t.Errorf("fired %v after early release, want none", got)
+	}
+}
+
+func TestSystemFilterReleasesAlreadyForwardedButton(t *testing.T) {
+	f, rec := newTestFilter(10000)
+
+	press(f, gamepad.ButtonBack)
+	waitWindow()
+	expectEvents(t, rec, "View down")
+
+	// 暂缓超时后才成立的组合键：已转发的按键补发释放
+	press(f, gamepad.ButtonStart)
+	expectEvents(t, rec, "View up")
+
+	f.Reset()
+	waitWindow()
+	expectEvents(t, rec)
+}
+
+func TestSystemFilterResetDropsPending(t *testing.T) {
+	f, rec := newTestFilter(1000)
+
+	press(f, gamepad.ButtonStart)
+	f.Reset()
+	waitWindow()
+	expectEvents(t, rec)
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// Diagnostics 检查当前方案的规则，返回发现的问题
+func (a *App) Diagnostics() []mapper.Diagnostic {
+	diags := mapper.ValidateRules(a.mapper.GetRules())
+	return append(diags, mapper.ValidateAxisRules(a.mapper.GetAxisRules())...)
+}
+
+// checkNewRule 检查即将添加或修改的规则（与现有规则一起检查，同ID的旧规则被替换），
+// 有错误时拒绝；警告不影响保存
+func (a *App) checkNewRule(rule *mapper.MappingRule) error {
+	var rules []*mapper.MappingRule
+	for _, r := range a.mapper.GetRules() {
+		if r.ID != rule.ID {
+			rules = append(rules, r)
+		}
+	}
+	rules = append(rules, rule)
+	for _, d := range mapper.DiagnosticsFor(mapper.ValidateRules(rules), rule.ID) {
+		if d.Severity == mapper.SeverityError {
+			return fmt.Errorf("规则无效: %s", d.Message)
+		}
+	}
+	return nil
+}
+
+// logDiagnostics 记录加载的规则中存在的问题
+func logDiagnostics(profile string, rules []*mapper.MappingRule, axisRules []*mapper.AxisRule) {
+	diags := append(mapper.ValidateRules(rules), mapper.ValidateAxisRules(axisRules)...)
+	for _, d := range diags {
+		slog.Warn("rule diagnostic", "profile", profile, "rule", d.RuleID, "severity", d.Severity, "code", d.Code, "message", d.Message)
+	}
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gamepad-key-mapper/internal/config"
+)
+
+// backupCommand 管理配置备份
+func backupCommand(env *env, args []string) error {
+	return dispatch(env, "backup", map[string]subcommand{
+		"list":    {"[-json]", backupList},
+		"restore": {"<序号|文件>", backupRestore},
+	}, args)
+}
+
+// backupInfo 备份列表的 JSON 输出
+type backupInfo struct {
+	Index int       `json:"index"` // 序号（1 为最新）
+	Path  string    `json:"path"`
+	Time  time.Time `json:"time"`
+	Size  int64     `json:"size"`
+}
+
+// backupList 列出配置备份（最新的在前）
+func backupList(env *env, args []string) error {
+	fs := newFlagSet(env, "backup list")
+	asJSON := fs.Bool("json", false, "以 JSON 输出")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("多余的参数: %v", fs.Args())
+	}
+
+	path, err := config.GetConfigPath()
+	if err != nil {
+		return err
+	}
+	backups, err := config.ListBackups(path)
+	if err != nil {
+		return err
+	}
+
+	infos := make([]backupInfo, 0, len(backups))
+	for i, b := range backups {
+		infos = append(infos, backupInfo{Index: i + 1, Path: b.Path, Time: b.Time, Size: b.Size})
+	}
+
+	if *asJSON {
+		return writeJSON(env.stdout, infos)
+	}
+	if len(infos) == 0 {
+		fmt.Fprintf(env.stdout, "%s 没有备份\n", path)
+		return nil
+	}
+	t := newTable(env.stdout)
+	fmt.Fprintln(t, "序号\t时间\t大小\t文件")
+	for _, b := range infos {
+		fmt.Fprintf(t, "%d\t%s\t%d\t%s\n", b.Index, b.Time.Format("2006-01-02 15:04:05"), b.Size, filepath.Base(b.Path))
+	}
+	return t.Flush()
+}
+
+// backupRestore 用备份替换配置文件（当前配置先被备份）
+//
+// 运行中的实例通过配置文件热加载应用恢复的配置，并恢复因配置文件无法读取而暂停的保存。
+func backupRestore(env *env, args []string) error {
+	fs := newFlagSet(env, "backup restore")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usagef("需要一个备份序号或文件参数（见 gkm backup list）")
+	}
+
+	path, err := config.GetConfigPath()
+	if err != nil {
+		return err
+	}
+	backupPath, err := findBackup(path, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.RestoreBackup(path, backupPath)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(env.stdout, "已从 %s 恢复配置（%d 个方案，当前方案 %s）\n",
+		filepath.Base(backupPath), len(cfg.Profiles), cfg.ActiveProfile)
+	return nil
+}
+
+// findBackup 按序号（1 为最新）或文件名查找备份
+func findBackup(configPath, arg string) (string, error) {
+	backups, err := config.ListBackups(configPath)
+	if err != nil {
+		return "", err
+	}
+
+	if n, err := strconv.Atoi(arg); err == nil {
+		if n < 1 || n > len(backups) {
+			return "", usagef("备份序号 %d 超出范围（共 %d 个备份）", n, len(backups))
+		}
+		return backups[n-1].Path, nil
+	}
+	for _, b := range backups {
+		if arg == b.Path || arg == filepath.Base(b.Path) {
+			return b.Path, nil
+		}
+	}
+	return "", fmt.Errorf("找不到备份 %s（见 gkm backup list）", arg)
+}
@@ -0,0 +1,209 @@
+// Package cli 命令行工具 gkm 的命令实现（不依赖图形界面，可在没有 cgo/OpenGL 的环境中编译）
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"gamepad-key-mapper/internal/config"
+	"gamepad-key-mapper/internal/logging"
+)
+
+// 退出码
+const (
+	ExitOK    = 0 // 成功
+	ExitError = 1 // 执行失败
+	ExitUsage = 2 // 参数错误
+)
+
+// command 子命令
+type command struct {
+	summary string
+	run     func(env *env, args []string) error
+	daemon  bool // 长时间运行的命令，日志同时输出到标准错误（其它命令只写日志文件，避免干扰输出）
+}
+
+// commands 所有子命令
+var commands = map[string]command{
+	"run":      {summary: "无界面运行映射，收到 Ctrl+C 或 SIGTERM 时释放所有按键并退出", run: runCommand, daemon: true},
+	"status":   {summary: "显示运行中实例的状态", run: statusCommand},
+	"start":    {summary: "启动运行中实例的映射", run: startCommand},
+	"stop":     {summary: "停止运行中实例的映射", run: stopCommand},
+	"pause":    {summary: "暂停运行中实例的映射", run: pauseCommand},
+	"resume":   {summary: "继续运行中实例已暂停的映射", run: resumeCommand},
+	"rules":    {summary: "管理当前方案的规则（list/add/remove/enable/disable）", run: rulesCommand},
+	"profile":  {summary: "管理方案（list/use/export/import）", run: profileCommand},
+	"validate": {summary: "检查配置文件，有错误时退出码为 1", run: validateCommand},
+	"backup":   {summary: "列出和恢复配置备份（list/restore），配置文件无法读取时使用", run: backupCommand},
+	"buttons":  {summary: "列出手柄按键名称", run: buttonsCommand},
+	"keys":     {summary: "列出键盘按键名称", run: keysCommand},
+}
+
+// env 命令的运行环境
+type env struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// errHelp 用户请求了帮助信息（已输出）
+var errHelp = errors.New("help requested")
+
+// usageError 参数错误（退出码为 ExitUsage）
+type usageError struct {
+	msg string
+}
+
+// Error 返回错误描述
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// usagef 创建参数错误
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// Main 解析命令行并执行子命令，返回退出码
+func Main(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("gkm", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", "", "配置文件路径（按扩展名识别 .json/.yaml/.yml/.toml）")
+	fs.Usage = func() {
+		printUsage(stderr, fs)
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+
+	if fs.NArg() == 0 {
+		printUsage(stderr, fs)
+		return ExitUsage
+	}
+	name := fs.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "未知的命令: %s\n\n", name)
+		printUsage(stderr, fs)
+		return ExitUsage
+	}
+
+	if *configPath != "" {
+		if err := config.SetConfigPath(*configPath); err != nil {
+			fmt.Fprintln(stderr, "无效的配置文件路径:", err)
+			return ExitUsage
+		}
+	}
+
+	// 日志文件写在配置文件旁边
+	var logDir string
+	if path, err := config.GetConfigPath(); err == nil {
+		logDir = filepath.Dir(path)
+	}
+	var console io.Writer
+	if cmd.daemon {
+		console = stderr
+	}
+	closeLog, err := logging.Setup(logDir, console)
+	if err != nil && cmd.daemon {
+		fmt.Fprintln(stderr, err)
+	}
+	defer closeLog()
+
+	if err := cmd.run(&env{stdout: stdout, stderr: stderr}, fs.Args()[1:]); err != nil {
+		if errors.Is(err, errHelp) {
+			return ExitOK
+		}
+		fmt.Fprintf(stderr, "gkm %s: %v\n", name, err)
+		var usage *usageError
+		if errors.As(err, &usage) {
+			return ExitUsage
+		}
+		return ExitError
+	}
+	return ExitOK
+}
+
+// printUsage 输出帮助信息
+func printUsage(w io.Writer, fs *flag.FlagSet) {
+	fmt.Fprintln(w, "用法: gkm [-config 路径] <命令> [参数]")
+	fmt.Fprintln(w, "各命令的参数见 gkm <命令> -h；大多数命令支持 -json 输出。退出码：0 成功，1 失败，2 参数错误")
+	fmt.Fprintln(w, "有运行中的实例（图形界面或 gkm run）时，rules、profile list/use 和 status 通过控制接口操作，修改立即生效")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "命令:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "选项:")
+	fs.PrintDefaults()
+}
+
+// newFlagSet 创建子命令的参数解析器（错误输出到 stderr）
+func newFlagSet(env *env, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("gkm "+name, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	return fs
+}
+
+// parseFlags 解析子命令参数，参数错误时返回 usageError
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return errHelp
+		}
+		return usagef("%v", err)
+	}
+	return nil
+}
+
+// subcommand 二级子命令（如 rules list）
+type subcommand struct {
+	usage string
+	run   func(env *env, args []string) error
+}
+
+// dispatch 执行二级子命令
+func dispatch(env *env, group string, subs map[string]subcommand, args []string) error {
+	if len(args) == 0 {
+		printSubUsage(env.stderr, group, subs)
+		return usagef("缺少 %s 的子命令", group)
+	}
+	if args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		printSubUsage(env.stderr, group, subs)
+		return errHelp
+	}
+	sub, ok := subs[args[0]]
+	if !ok {
+		printSubUsage(env.stderr, group, subs)
+		return usagef("未知的子命令: %s %s", group, args[0])
+	}
+	return sub.run(env, args[1:])
+}
+
+// printSubUsage 输出二级子命令的用法
+func printSubUsage(w io.Writer, group string, subs map[string]subcommand) {
+	names := make([]string, 0, len(subs))
+	for name := range subs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "用法:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  gkm %s %s %s\n", group, name, subs[name].usage)
+	}
+}
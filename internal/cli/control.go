@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+
+	"gamepad-key-mapper/internal/ipc"
+)
+
+// stateText 映射状态的中文名称
+var stateText = map[string]string{
+	ipc.StateStopped: "已停止",
+	ipc.StateRunning: "运行中",
+	ipc.StatePaused:  "已暂停",
+}
+
+// statusCommand 显示运行中实例的状态（没有实例时显示配置中的当前方案）
+func statusCommand(env *env, args []string) error {
+	fs := newFlagSet(env, "status")
+	asJSON := fs.Bool("json", false, "以 JSON 输出")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("多余的参数: %v", fs.Args())
+	}
+
+	c, err := openController()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	status, err := c.Status()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return writeJSON(env.stdout, status)
+	}
+	printStatus(env, status)
+	return nil
+}
+
+// printStatus 输出状态
+func printStatus(env *env, status *ipc.Status) {
+	if status.PID == 0 {
+		fmt.Fprintln(env.stdout, "状态: 没有运行中的实例")
+	} else {
+		fmt.Fprintf(env.stdout, "状态: %s（进程 %d）\n", stateText[status.State], status.PID)
+	}
+	fmt.Fprintf(env.stdout, "方案: %s（%d 条规则）\n", status.Profile, status.Rules)
+}
+
+// startCommand 启动运行中实例的映射
+func startCommand(env *env, args []string) error {
+	return controlCommand(env, "start", args, (*ipc.Client).Start)
+}
+
+// stopCommand 停止运行中实例的映射（实例继续运行）
+func stopCommand(env *env, args []string) error {
+	return controlCommand(env, "stop", args, (*ipc.Client).Stop)
+}
+
+// pauseCommand 暂停运行中实例的映射
+func pauseCommand(env *env, args []string) error {
+	return controlCommand(env, "pause", args, (*ipc.Client).Pause)
+}
+
+// resumeCommand 继续运行中实例已暂停的映射
+func resumeCommand(env *env, args []string) error {
+	return controlCommand(env, "resume", args, (*ipc.Client).Resume)
+}
+
+// controlCommand 对运行中的实例执行操作并输出新的状态
+func controlCommand(env *env, name string, args []string, op func(*ipc.Client) (*ipc.Status, error)) error {
+	fs := newFlagSet(env, name)
+	asJSON := fs.Bool("json", false, "以 JSON 输出新的状态")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("多余的参数: %v", fs.Args())
+	}
+
+	client, err := dialInstance()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	status, err := op(client)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return writeJSON(env.stdout, status)
+	}
+	printStatus(env, status)
+	return nil
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"gamepad-key-mapper/internal/app"
+	"gamepad-key-mapper/internal/ipc"
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// controller 规则和方案的操作
+//
+// 有运行中的实例时通过控制接口操作（修改立即生效，由实例保存配置），否则直接修改配置文件。
+type controller interface {
+	Status() (*ipc.Status, error)
+	Rules() (*ipc.RuleList, error)
+	AddRule(rule *mapper.MappingRule) (*mapper.MappingRule, error)
+	RemoveRule(id string) error
+	SetRuleEnabled(id string, enabled bool) error
+	Profiles() (*ipc.ProfileList, error)
+	UseProfile(name string) error
+	Close() error
+}
+
+// openController 连接运行中的实例，没有实例时加载配置文件
+func openController() (controller, error) {
+	client, err := ipc.DialDefault()
+	if err == nil {
+		return client, nil
+	}
+	if !errors.Is(err, ipc.ErrNotRunning) {
+		return nil, err
+	}
+
+	a, err := openApp()
+	if err != nil {
+		return nil, err
+	}
+	a.SetManualSave(true) // 每次修改由 localController 保存一次
+	return &localController{app: a}, nil
+}
+
+// dialInstance 连接运行中的实例（启动、停止等只能由运行中的实例执行的操作）
+func dialInstance() (*ipc.Client, error) {
+	client, err := ipc.DialDefault()
+	if errors.Is(err, ipc.ErrNotRunning) {
+		return nil, errors.New("没有运行中的实例（可以用 gkm run 无界面运行）")
+	}
+	return client, err
+}
+
+// localController 直接修改配置文件，每次修改后立即保存
+type localController struct {
+	app *app.App
+}
+
+// Status 返回配置中的状态（没有运行中的实例，映射为停止状态）
+func (c *localController) Status() (*ipc.Status, error) {
+	return &ipc.Status{
+		State:       ipc.StateStopped,
+		Profile:     c.app.ActiveProfile(),
+		Rules:       len(c.app.GetRules()),
+		ExecAllowed: c.app.ExecAllowed(),
+	}, nil
+}
+
+// Rules 返回当前方案的规则
+func (c *localController) Rules() (*ipc.RuleList, error) {
+	return &ipc.RuleList{Profile: c.app.ActiveProfile(), Rules: c.app.GetRules()}, nil
+}
+
+// AddRule 添加规则并保存
+func (c *localController) AddRule(rule *mapper.MappingRule) (*mapper.MappingRule, error) {
+	added, err := c.app.AddMappingRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	return added, c.app.SaveConfig()
+}
+
+// RemoveRule 删除规则并保存
+func (c *localController) RemoveRule(id string) error {
+	if !c.app.RemoveRule(id) {
+		return fmt.Errorf("规则不存在: %s", id)
+	}
+	return c.app.SaveConfig()
+}
+
+// SetRuleEnabled 启用或停用规则并保存
+func (c *localController) SetRuleEnabled(id string, enabled bool) error {
+	if err := c.app.SetRuleEnabled(id, enabled); err != nil {
+		return err
+	}
+	return c.app.SaveConfig()
+}
+
+// Profiles 返回所有方案
+func (c *localController) Profiles() (*ipc.ProfileList, error) {
+	return &ipc.ProfileList{Active: c.app.ActiveProfile(), Profiles: c.app.ProfileNames()}, nil
+}
+
+// UseProfile 切换当前方案并保存
+func (c *localController) UseProfile(name string) error {
+	if err := c.app.ActivateProfile(name); err != nil {
+		return err
+	}
+	return c.app.SaveConfig()
+}
+
+// Close 无需释放资源
+func (c *localController) Close() error {
+	return nil
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/keyboard"
+)
+
+// nameInfo 按键名称列表的 JSON 输出
+type nameInfo struct {
+	Name    string `json:"name"`           // 配置文件和命令行中使用的名称
+	Display string `json:"display"`        // 界面显示的名称
+	Code    uint32 `json:"code,omitempty"` // 键盘按键的虚拟键码
+}
+
+// buttonsCommand 列出手柄按键名称
+func buttonsCommand(env *env, args []string) error {
+	var names []nameInfo
+	for _, btn := range gamepad.AllButtons() {
+		names = append(names, nameInfo{Name: btn.Name(), Display: btn.String()})
+	}
+	return listNames(env, "buttons", args, names)
+}
+
+// keysCommand 列出键盘按键名称
+func keysCommand(env *env, args []string) error {
+	var names []nameInfo
+	for _, key := range keyboard.NamedKeys() {
+		names = append(names, nameInfo{Name: key.Name(), Display: key.String(), Code: uint32(key)})
+	}
+	return listNames(env, "keys", args, names)
+}
+
+// listNames 输出按键名称列表
+func listNames(env *env, name string, args []string, names []nameInfo) error {
+	fs := newFlagSet(env, name)
+	asJSON := fs.Bool("json", false, "以 JSON 输出")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("多余的参数: %v", fs.Args())
+	}
+
+	if *asJSON {
+		return writeJSON(env.stdout, names)
+	}
+	t := newTable(env.stdout)
+	for _, n := range names {
+		if n.Code != 0 {
+			fmt.Fprintf(t, "%s\t0x%02X\t%s\n", n.Name, n.Code, n.Display)
+		} else {
+			fmt.Fprintf(t, "%s\t%s\n", n.Name, n.Display)
+		}
+	}
+	return t.Flush()
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"gamepad-key-mapper/internal/app"
+	"gamepad-key-mapper/internal/config"
+)
+
+// writeJSON 以缩进格式输出 JSON
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// newTable 创建按列对齐的文本输出
+func newTable(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+}
+
+// yesNo 返回是/否
+func yesNo(b bool) string {
+	if b {
+		return "是"
+	}
+	return "否"
+}
+
+// openApp 创建应用并加载配置
+//
+// 配置文件无法读取时返回错误（不使用默认配置继续，避免之后的保存覆盖用户数据）。
+func openApp() (*app.App, error) {
+	a, err := app.New()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.LoadConfig(); err != nil {
+		var loadErr *config.LoadError
+		if errors.As(err, &loadErr) {
+			return nil, fmt.Errorf("%w（可用 gkm backup list 和 gkm backup restore 恢复备份）", err)
+		}
+		return nil, err
+	}
+	return a, nil
+}
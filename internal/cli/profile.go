@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"fmt"
+
+	"gamepad-key-mapper/internal/app"
+	"gamepad-key-mapper/internal/config"
+)
+
+// profileCommand 管理方案
+func profileCommand(env *env, args []string) error {
+	return dispatch(env, "profile", map[string]subcommand{
+		"list":   {"[-json]", profileList},
+		"use":    {"<方案>", profileUse},
+		"export": {"[-author 作者] [-game 游戏] [-controller 手柄] [-desc 说明] <方案> <文件>", profileExport},
+		"import": {"[-mode new|merge|replace] [-target 方案] [-overwrite] [-json] <文件>", profileImport},
+	}, args)
+}
+
+// profileInfo 方案列表的 JSON 输出
+type profileInfo struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
+
+// profileList 列出所有方案
+func profileList(env *env, args []string) error {
+	fs := newFlagSet(env, "profile list")
+	asJSON := fs.Bool("json", false, "以 JSON 输出")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("多余的参数: %v", fs.Args())
+	}
+
+	c, err := openController()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	list, err := c.Profiles()
+	if err != nil {
+		return err
+	}
+	var profiles []profileInfo
+	for _, name := range list.Profiles {
+		profiles = append(profiles, profileInfo{Name: name, Active: name == list.Active})
+	}
+
+	if *asJSON {
+		return writeJSON(env.stdout, profiles)
+	}
+	for _, p := range profiles {
+		marker := " "
+		if p.Active {
+			marker = "*"
+		}
+		fmt.Fprintf(env.stdout, "%s %s\n", marker, p.Name)
+	}
+	return nil
+}
+
+// profileUse 切换当前方案
+func profileUse(env *env, args []string) error {
+	fs := newFlagSet(env, "profile use")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usagef("需要一个方案名称参数")
+	}
+
+	c, err := openController()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.UseProfile(fs.Arg(0)); err != nil {
+		return err
+	}
+	fmt.Fprintf(env.stdout, "当前方案: %s\n", fs.Arg(0))
+	return nil
+}
+
+// profileExport 将方案导出为方案包文件
+func profileExport(env *env, args []string) error {
+	fs := newFlagSet(env, "profile export")
+	var meta config.BundleMeta
+	fs.StringVar(&meta.Author, "author", "", "作者")
+	fs.StringVar(&meta.Game, "game", "", "适用的游戏/程序")
+	fs.StringVar(&meta.Controller, "controller", "", "手柄类型")
+	fs.StringVar(&meta.Description, "desc", "", "说明")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return usagef("需要方案名称和文件路径两个参数")
+	}
+
+	a, err := openApp()
+	if err != nil {
+		return err
+	}
+	meta.Name = fs.Arg(0)
+	if err := a.ExportProfile(fs.Arg(0), fs.Arg(1), meta); err != nil {
+		return err
+	}
+	fmt.Fprintf(env.stdout, "方案已导出到 %s\n", fs.Arg(1))
+	return nil
+}
+
+// importResult 导入结果的 JSON 输出
+type importResult struct {
+	Profile  string   `json:"profile"`
+	Added    int      `json:"added"`
+	Replaced int      `json:"replaced"`
+	Skipped  int      `json:"skipped"`
+	Issues   []string `json:"issues,omitempty"` // 其它工具的配置中无法转换的内容
+
+	ExecDisabled int `json:"exec_disabled"` // 导入后被停用的命令规则数
+}
+
+// importModes 导入方式的命令行名称
+var importModes = map[string]app.ImportMode{
+	"new":     app.ImportAsNew,
+	"merge":   app.ImportMerge,
+	"replace": app.ImportReplace,
+}
+
+// profileImport 导入方案包（或 AntiMicroX/JoyToKey 配置）
+func profileImport(env *env, args []string) error {
+	fs := newFlagSet(env, "profile import")
+	mode := fs.String("mode", "new", "导入方式：new 新建方案、merge 合并到方案、replace 替换方案的规则")
+	target := fs.String("target", "", "目标方案（新建时为新方案名称，默认使用方案包名称；合并/替换时默认为当前方案）")
+	overwrite := fs.Bool("overwrite", false, "合并时源按键冲突使用导入的规则（默认保留已有规则）")
+	allowExec := fs.Bool("allow-exec", false, "导入的命令规则保持启用（默认导入后停用，只在信任来源时使用）")
+	asJSON := fs.Bool("json", false, "以 JSON 输出导入结果")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usagef("需要一个文件路径参数")
+	}
+	opts := app.ImportOptions{Target: *target, AllowExec: *allowExec}
+	var ok bool
+	if opts.Mode, ok = importModes[*mode]; !ok {
+		return usagef("无效的导入方式 %q（可用 new、merge、replace）", *mode)
+	}
+	if *overwrite {
+		opts.Conflict = app.ConflictOverwrite
+	}
+
+	a, err := openApp()
+	if err != nil {
+		return err
+	}
+
+	path := fs.Arg(0)
+	var bundle *config.Bundle
+	var report *config.ImportReport
+	if config.IsForeignFormat(path) {
+		bundle, report, err = a.ReadForeign(path)
+	} else {
+		bundle, err = a.ReadBundle(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	result, err := a.ImportBundle(bundle, opts) // 导入后已保存配置
+	if err != nil {
+		return err
+	}
+
+	out := importResult{
+		Profile:      result.Profile,
+		Added:        result.Added,
+		Replaced:     result.Replaced,
+		Skipped:      result.Skipped,
+		ExecDisabled: result.ExecDisabled,
+	}
+	if report != nil {
+		for _, issue := range report.Issues {
+			out.Issues = append(out.Issues, issue.String())
+		}
+	}
+	if *asJSON {
+		return writeJSON(env.stdout, out)
+	}
+
+	fmt.Fprintf(env.stdout, "已导入到方案 %s：新增 %d 条，覆盖 %d 条，跳过 %d 条\n", out.Profile, out.Added, out.Replaced, out.Skipped)
+	if out.ExecDisabled > 0 {
+		fmt.Fprintf(env.stderr, "%d 条命令规则已停用，确认命令内容后可用 gkm rules enable <规则ID> 启用\n", out.ExecDisabled)
+	}
+	if len(out.Issues) > 0 {
+		fmt.Fprintf(env.stderr, "以下 %d 项未能转换:\n", len(out.Issues))
+		for _, issue := range out.Issues {
+			fmt.Fprintln(env.stderr, "  "+issue)
+		}
+	}
+	return nil
+}
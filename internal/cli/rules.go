@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/keyboard"
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// rulesCommand 管理当前方案的规则
+func rulesCommand(env *env, args []string) error {
+	return dispatch(env, "rules", map[string]subcommand{
+		"list":    {"[-json]", rulesList},
+		"add":     {"[-type keyboard|gamepad|exec] [-name 名称] [-desc 说明] [-disabled] [-json] <源按键> <目标>", rulesAdd},
+		"remove":  {"<规则ID>", rulesRemove},
+		"enable":  {"<规则ID>", rulesEnable},
+		"disable": {"<规则ID>", rulesDisable},
+	}, args)
+}
+
+// rulesList 列出当前方案的规则
+func rulesList(env *env, args []string) error {
+	fs := newFlagSet(env, "rules list")
+	asJSON := fs.Bool("json", false, "以 JSON 输出")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("多余的参数: %v", fs.Args())
+	}
+
+	c, err := openController()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	list, err := c.Rules()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return writeJSON(env.stdout, list.Rules)
+	}
+
+	fmt.Fprintf(env.stdout, "方案: %s\n", list.Profile)
+	t := newTable(env.stdout)
+	fmt.Fprintln(t, "ID\t启用\t规则\t名称")
+	for _, rule := range list.Rules {
+		fmt.Fprintf(t, "%s\t%s\t%s\t%s\n", rule.ID, yesNo(rule.Enabled), rule.String(), rule.Name)
+	}
+	return t.Flush()
+}
+
+// rulesAdd 添加规则
+//
+// 目标的写法按类型区分：键盘为快捷键（如 Ctrl+Shift+Esc），手柄为 + 或 , 分隔的按键（如 B+Y），
+// 命令为 shell 命令行。
+func rulesAdd(env *env, args []string) error {
+	fs := newFlagSet(env, "rules add")
+	targetType := fs.String("type", "keyboard", "目标类型：keyboard、gamepad 或 exec")
+	name := fs.String("name", "", "规则名称")
+	desc := fs.String("desc", "", "规则说明")
+	disabled := fs.Bool("disabled", false, "添加后先停用")
+	asJSON := fs.Bool("json", false, "以 JSON 输出添加的规则")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return usagef("需要源按键和目标两个参数")
+	}
+
+	source, err := gamepad.ParseButton(fs.Arg(0))
+	if err != nil {
+		return usagef("无效的源按键: %v", err)
+	}
+	kind, err := mapper.ParseTargetType(*targetType)
+	if err != nil {
+		return usagef("%v", err)
+	}
+
+	var rule *mapper.MappingRule
+	switch kind {
+	case mapper.TargetKeyboard:
+		keys, mods, err := keyboard.ParseShortcut(fs.Arg(1))
+		if err != nil {
+			return usagef("快捷键格式错误: %v", err)
+		}
+		rule = mapper.NewRuleMultiKeys("", source, keys, mods)
+	case mapper.TargetGamepad:
+		targets, err := parseButtons(fs.Arg(1))
+		if err != nil {
+			return usagef("%v", err)
+		}
+		rule = mapper.NewRuleGamepad("", source, targets)
+	case mapper.TargetExec:
+		rule = mapper.NewRuleExec("", source, &mapper.ExecAction{Mode: mapper.ExecShell, Command: fs.Arg(1), OnPress: true})
+	}
+	rule.Name = *name
+	rule.Description = *desc
+	rule.Enabled = !*disabled
+
+	c, err := openController()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	rule, err = c.AddRule(rule)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return writeJSON(env.stdout, rule)
+	}
+	fmt.Fprintf(env.stdout, "已添加规则 %s: %s\n", rule.ID, rule.String())
+
+	// 提示新规则的问题（查询失败不影响添加结果）
+	if list, err := c.Rules(); err == nil {
+		for _, d := range mapper.DiagnosticsFor(mapper.ValidateRules(list.Rules), rule.ID) {
+			fmt.Fprintln(env.stderr, d.String())
+		}
+	}
+	if kind == mapper.TargetExec {
+		if status, err := c.Status(); err == nil && !status.ExecAllowed {
+			fmt.Fprintln(env.stderr, "提示: 配置未开启 allow_exec，命令规则不会执行")
+		}
+	}
+	return nil
+}
+
+// parseButtons 解析 + 或 , 分隔的手柄按键列表
+func parseButtons(s string) ([]gamepad.Button, error) {
+	var buttons []gamepad.Button
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '+' || r == ',' }) {
+		btn, err := gamepad.ParseButton(part)
+		if err != nil {
+			return nil, fmt.Errorf("无效的目标按键: %w", err)
+		}
+		buttons = append(buttons, btn)
+	}
+	if len(buttons) == 0 {
+		return nil, fmt.Errorf("请至少指定一个目标按键")
+	}
+	return buttons, nil
+}
+
+// rulesRemove 删除规则
+func rulesRemove(env *env, args []string) error {
+	id, err := ruleIDArg(env, "rules remove", args)
+	if err != nil {
+		return err
+	}
+
+	c, err := openController()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.RemoveRule(id); err != nil {
+		return err
+	}
+	fmt.Fprintf(env.stdout, "已删除规则 %s\n", id)
+	return nil
+}
+
+// rulesEnable 启用规则
+func rulesEnable(env *env, args []string) error {
+	return rulesSetEnabled(env, "enable", args, true)
+}
+
+// rulesDisable 停用规则
+func rulesDisable(env *env, args []string) error {
+	return rulesSetEnabled(env, "disable", args, false)
+}
+
+// rulesSetEnabled 启用或停用规则
+func rulesSetEnabled(env *env, name string, args []string, enabled bool) error {
+	id, err := ruleIDArg(env, "rules "+name, args)
+	if err != nil {
+		return err
+	}
+
+	c, err := openController()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.SetRuleEnabled(id, enabled); err != nil {
+		return err
+	}
+	if enabled {
+		fmt.Fprintf(env.stdout, "已启用规则 %s\n", id)
+	} else {
+		fmt.Fprintf(env.stdout, "已停用规则 %s\n", id)
+	}
+	return nil
+}
+
+// ruleIDArg 解析只有一个规则ID参数的命令
+func ruleIDArg(env *env, name string, args []string) (string, error) {
+	fs := newFlagSet(env, name)
+	if err := parseFlags(fs, args); err != nil {
+		return "", err
+	}
+	if fs.NArg() != 1 {
+		return "", usagef("需要一个规则ID参数")
+	}
+	return fs.Arg(0), nil
+}
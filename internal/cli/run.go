@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"gamepad-key-mapper/internal/app"
+	"gamepad-key-mapper/internal/config"
+	"gamepad-key-mapper/internal/ipc"
+	"gamepad-key-mapper/internal/overlay"
+)
+
+// runCommand 无界面运行映射，直到收到 SIGINT/SIGTERM
+func runCommand(env *env, args []string) error {
+	fs := newFlagSet(env, "run")
+	profile := fs.String("profile", "", "启动时切换到的方案（会保存为当前方案，默认使用配置中的当前方案）")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("多余的参数: %v", fs.Args())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return runHeadless(ctx, *profile)
+}
+
+// runHeadless 加载配置并启动映射，ctx 结束时停止映射（释放所有按住的键）
+func runHeadless(ctx context.Context, profile string) error {
+	application, err := app.New()
+	if err != nil {
+		return err
+	}
+
+	// 配置文件损坏时已回退为默认配置（原文件已备份），继续运行；恢复备份或修复文件之前不保存
+	if err := application.LoadConfig(); err != nil {
+		var loadErr *config.LoadError
+		if !errors.As(err, &loadErr) {
+			return err
+		}
+		slog.Warn("using default config, saving paused until the file is fixed or restored with gkm backup restore", "err", err)
+	}
+	if profile != "" {
+		if err := application.ActivateProfile(profile); err != nil {
+			return err
+		}
+	}
+
+	// 配置文件被外部修改时自动重新加载（失败只影响热加载）
+	if err := application.WatchConfig(); err != nil {
+		slog.Warn("config hot reload disabled", "err", err)
+	}
+	defer application.StopWatchingConfig()
+
+	// 本地控制接口（失败不影响映射）
+	server := ipc.NewServer(application)
+	if err := server.ListenDefault(); err != nil {
+		slog.Warn("control api disabled", "err", err)
+	}
+	defer server.Close()
+
+	// 直播叠加层（配置中开启时，失败不影响映射）
+	if addr := application.OverlayAddr(); addr != "" {
+		overlayServer := overlay.NewServer(application)
+		if err := overlayServer.Listen(addr); err != nil {
+			slog.Warn("overlay disabled", "err", err)
+		}
+		defer overlayServer.Close()
+	}
+
+	if err := application.Start(); err != nil {
+		return fmt.Errorf("启动映射失败: %w", err)
+	}
+	slog.Info("headless mapping started", "profile", application.ActiveProfile(), "pid", os.Getpid())
+
+	<-ctx.Done()
+
+	application.Stop()
+	slog.Info("headless mapping stopped")
+	return nil
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"gamepad-key-mapper/internal/config"
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// validateResult 配置检查结果的 JSON 输出
+type validateResult struct {
+	File        string              `json:"file"`
+	Valid       bool                `json:"valid"`            // 没有错误（可以有警告）
+	Errors      []string            `json:"errors,omitempty"` // 无法解析或无法应用的问题
+	Diagnostics []profileDiagnostic `json:"diagnostics,omitempty"`
+}
+
+// profileDiagnostic 带方案名称的规则检查结果
+type profileDiagnostic struct {
+	Profile string `json:"profile"`
+	mapper.Diagnostic
+}
+
+// validateCommand 检查配置文件（不修改文件），有错误时返回错误
+func validateCommand(env *env, args []string) error {
+	fs := newFlagSet(env, "validate")
+	asJSON := fs.Bool("json", false, "以 JSON 输出")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usagef("需要一个配置文件路径参数")
+	}
+
+	path := fs.Arg(0)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	result := validateResult{File: path}
+	cfg, err := config.DecodeFormat(data, config.FormatFromPath(path))
+	if err == nil {
+		err = cfg.Validate()
+		for _, p := range cfg.Profiles {
+			// 命令行没有虚拟手柄设备
+			diags := append(mapper.ValidateRules(p.Rules), mapper.ValidateAxisRules(p.AxisRules)...)
+			for _, d := range diags {
+				result.Diagnostics = append(result.Diagnostics, profileDiagnostic{Profile: p.Name, Diagnostic: d})
+			}
+		}
+	}
+	result.Errors = splitErrors(err)
+
+	errorCount := len(result.Errors)
+	for _, d := range result.Diagnostics {
+		if d.Severity == mapper.SeverityError {
+			errorCount++
+		}
+	}
+	result.Valid = errorCount == 0
+
+	if *asJSON {
+		if err := writeJSON(env.stdout, result); err != nil {
+			return err
+		}
+	} else {
+		for _, e := range result.Errors {
+			fmt.Fprintf(env.stdout, "错误: %s\n", e)
+		}
+		for _, d := range result.Diagnostics {
+			fmt.Fprintf(env.stdout, "方案 %s: %s\n", d.Profile, d.Diagnostic.String())
+		}
+		if result.Valid {
+			fmt.Fprintf(env.stdout, "%s: 检查通过（%d 个警告）\n", path, len(result.Diagnostics))
+		}
+	}
+
+	if !result.Valid {
+		return fmt.Errorf("%s 有 %d 个错误", path, errorCount)
+	}
+	return nil
+}
+
+// splitErrors 将 errors.Join 合并的错误拆分为多条
+func splitErrors(err error) []string {
+	if err == nil {
+		return nil
+	}
+	var messages []string
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			messages = append(messages, splitErrors(e)...)
+		}
+		return messages
+	}
+	return []string{err.Error()}
+}
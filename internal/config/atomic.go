@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// renameFile 重命名文件（测试中替换以模拟重命名前崩溃）
+var renameFile = os.Rename
+
+// writeFileAtomic 原子地写入文件：先写入同目录的临时文件并刷盘，再重命名覆盖目标文件
+//
+// 写入过程中崩溃或断电时，目标文件要么保持旧内容，要么是完整的新内容。
+func writeFileAtomic(path string, data []byte, perm os.FileMode) (err error) {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	// 任何一步失败都清理临时文件
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	if err = renameFile(tmpPath, path); err != nil {
+		return err
+	}
+
+	// 刷新目录项，保证重命名本身落盘（部分平台不支持，忽略错误）
+	if d, dirErr := os.Open(dir); dirErr == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
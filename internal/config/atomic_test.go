@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tempFiles 返回目录中残留的临时文件
+func tempFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, ".*.tmp-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestWriteFileAtomicReplacesContent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(path, []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Errorf("content = %q, want %q", data, "new")
+	}
+	if tmp := tempFiles(t, dir); len(tmp) != 0 {
+		t.Errorf("temporary files left behind: %v", tmp)
+	}
+}
+
+func TestWriteFileAtomicFailureKeepsOriginal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟临时文件写完、重命名之前崩溃或断电
+	crash := errors.New("power lost")
+	renameFile = func(string, string) error { return crash }
+	t.Cleanup(func() { renameFile = os.Rename })
+
+	if err := writeFileAtomic(path, []byte("new content that never lands"), 0644); !errors.Is(err, crash) {
+		t.Fatalf("writeFileAtomic error = %v, want %v", err, crash)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "old" {
+		t.Errorf("content after failed write = %q, want the original", data)
+	}
+	if tmp := tempFiles(t, dir); len(tmp) != 0 {
+		t.Errorf("temporary files left behind: %v", tmp)
+	}
+}
+
+func TestPartialTempFileDoesNotAffectConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gamepad-key-mapper.json")
+
+	cfg := NewDefault()
+	cfg.AllowExec = true
+	if err := SaveFile(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// 上次写入到一半时进程被杀，留下截断的临时文件
+	partial := filepath.Join(dir, ".gamepad-key-mapper.json.tmp-12345")
+	if err := os.WriteFile(partial, []byte(`{"version": 3, "prof`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile with a stale partial temp file: %v", err)
+	}
+	if !loaded.AllowExec {
+		t.Error("loaded config lost its settings")
+	}
+
+	loaded.AllowExec = false
+	if err := SaveFile(path, loaded); err != nil {
+		t.Fatalf("SaveFile with a stale partial temp file: %v", err)
+	}
+	if reloaded, err := LoadFile(path); err != nil || reloaded.AllowExec {
+		t.Errorf("reloaded config = %+v, %v; want the saved change", reloaded, err)
+	}
+}
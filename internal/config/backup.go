@@ -0,0 +1,170 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxBackups 保留的配置备份数量
+const MaxBackups = 10
+
+// minBackupInterval 自动保存时两次备份的最小间隔
+//
+// 界面中的每次修改都会自动保存，逐次备份会让几次连续修改就挤掉所有较早的快照；
+// 间隔内的保存不再备份，备份之间至少相隔这段时间。
+var minBackupInterval = 5 * time.Minute
+
+// backupDirName 备份目录名（位于配置文件所在目录）
+const backupDirName = "backups"
+
+// backupTimeFormat 备份文件名中的时间格式
+const backupTimeFormat = "20060102-150405.000"
+
+// Backup 配置备份快照
+type Backup struct {
+	Path string    // 备份文件路径
+	Time time.Time // 备份时间
+	Size int64     // 文件大小
+}
+
+// BackupDir 返回配置文件对应的备份目录
+func BackupDir(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), backupDirName)
+}
+
+// backupPrefix 返回备份文件名前缀
+func backupPrefix(configPath string) string {
+	return strings.TrimSuffix(filepath.Base(configPath), filepath.Ext(configPath)) + "."
+}
+
+// ListBackups 列出配置文件的所有备份（最新的在前）
+func ListBackups(configPath string) ([]Backup, error) {
+	dir := BackupDir(configPath)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	prefix := backupPrefix(configPath)
+	ext := filepath.Ext(configPath)
+
+	var backups []Backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		t, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue // 不是本程序生成的备份
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, Backup{
+			Path: filepath.Join(dir, name),
+			Time: t,
+			Size: info.Size(),
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Time.After(backups[j].Time)
+	})
+	return backups, nil
+}
+
+// backupCurrent 在覆盖前备份现有配置文件，并清理多余的旧备份
+//
+// 内容未变化时跳过；force 为 false 时，距最新的备份不到 minBackupInterval 也跳过。
+func backupCurrent(configPath string, newData []byte, force bool) error {
+	old, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if bytes.Equal(old, newData) {
+		return nil
+	}
+
+	if !force {
+		backups, err := ListBackups(configPath)
+		if err != nil {
+			return err
+		}
+		if len(backups) > 0 && time.Since(backups[0].Time) < minBackupInterval {
+			return nil
+		}
+	}
+
+	_, err = writeBackup(configPath, old)
+	return err
+}
+
+// writeBackup 将内容写入备份目录作为新的备份，并清理多余的旧备份，返回备份路径
+func writeBackup(configPath string, data []byte) (string, error) {
+	dir := BackupDir(configPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, backupPrefix(configPath)+time.Now().Format(backupTimeFormat)+filepath.Ext(configPath))
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return "", err
+	}
+
+	return path, pruneBackups(configPath, MaxBackups)
+}
+
+// pruneBackups 只保留最新的 keep 个备份
+func pruneBackups(configPath string, keep int) error {
+	backups, err := ListBackups(configPath)
+	if err != nil {
+		return err
+	}
+
+	for i := keep; i < len(backups); i++ {
+		if err := os.Remove(backups[i].Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreBackup 用备份替换配置文件（当前配置总是先被备份），返回恢复后的配置
+func RestoreBackup(configPath string, backupPath string) (*Config, error) {
+	// 只允许恢复本配置的备份目录中的文件
+	if filepath.Dir(filepath.Clean(backupPath)) != filepath.Clean(BackupDir(configPath)) {
+		return nil, fmt.Errorf("%s 不是该配置的备份", backupPath)
+	}
+
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := DecodeFormat(data, FormatFromPath(backupPath))
+	if err != nil {
+		return nil, fmt.Errorf("备份 %s 无法使用: %w", filepath.Base(backupPath), err)
+	}
+
+	if err := saveFile(configPath, cfg, true); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
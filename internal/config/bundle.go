@@ -0,0 +1,166 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// BundleKind 方案包文件的类型标识
+const BundleKind = "gamepad-key-mapper/profile"
+
+// BundleMeta 方案包的描述信息
+type BundleMeta struct {
+	Name        string    `json:"name"`                  // 方案名称
+	Author      string    `json:"author,omitempty"`      // 作者
+	Game        string    `json:"game,omitempty"`        // 适用的游戏/程序
+	Controller  string    `json:"controller,omitempty"`  // 手柄类型（如 Xbox Series、Xbox Elite 2）
+	Description string    `json:"description,omitempty"` // 说明
+	Created     time.Time `json:"created"`               // 导出时间
+}
+
+// Bundle 可分享的单个方案（独立文件，不包含全局设置）
+type Bundle struct {
+	Kind    string     `json:"kind"`    // 固定为 BundleKind
+	Version int        `json:"version"` // 规则格式版本（与配置文件 version 相同）
+	Meta    BundleMeta `json:"meta"`
+	Profile *Profile   `json:"profile"`
+}
+
+// NewBundle 将方案打包（深拷贝，元数据中的名称为空时使用方案名称）
+func NewBundle(p *Profile, meta BundleMeta) (*Bundle, error) {
+	if meta.Name == "" {
+		meta.Name = p.Name
+	}
+	if meta.Created.IsZero() {
+		meta.Created = time.Now()
+	}
+
+	clone, err := p.Clone(meta.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Bundle{
+		Kind:    BundleKind,
+		Version: CurrentVersion,
+		Meta:    meta,
+		Profile: clone,
+	}, nil
+}
+
+// ExecRules 返回方案包中的命令规则（来自他人的命令可以执行任意程序，导入前需要用户确认）
+func (b *Bundle) ExecRules() []*mapper.MappingRule {
+	var rules []*mapper.MappingRule
+	for _, rule := range b.Profile.Rules {
+		if rule.TargetType == mapper.TargetExec {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// SaveBundle 保存方案包（格式由扩展名决定）
+func SaveBundle(path string, b *Bundle) error {
+	data, err := marshalAs(b, FormatFromPath(path), nil)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// LoadBundle 读取方案包（格式由扩展名决定）
+func LoadBundle(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBundle(data, FormatFromPath(path))
+}
+
+// DecodeBundle 解析方案包，旧版本的规则会迁移到当前格式
+func DecodeBundle(data []byte, format Format) (*Bundle, error) {
+	jsonData, err := toJSON(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		Kind    string          `json:"kind"`
+		Version *int            `json:"version"`
+		Meta    BundleMeta      `json:"meta"`
+		Profile json.RawMessage `json:"profile"`
+	}
+	if err := json.Unmarshal(jsonData, &raw); err != nil {
+		return nil, fmt.Errorf("解析方案包失败: %w", err)
+	}
+	if raw.Kind != BundleKind {
+		return nil, errors.New("不是方案包文件")
+	}
+	if raw.Version == nil {
+		return nil, errors.New("方案包缺少 version 字段")
+	}
+	if len(raw.Profile) == 0 || string(raw.Profile) == "null" {
+		return nil, errors.New("方案包中没有方案")
+	}
+
+	profile, err := migrateBundleProfile(raw.Profile, *raw.Version)
+	if err != nil {
+		return nil, err
+	}
+	if errs := profile.validate(); len(errs) > 0 {
+		return nil, fmt.Errorf("方案包内容无效: %w", errors.Join(errs...))
+	}
+
+	if raw.Meta.Name == "" {
+		raw.Meta.Name = profile.Name
+	}
+	return &Bundle{
+		Kind:    BundleKind,
+		Version: CurrentVersion,
+		Meta:    raw.Meta,
+		Profile: profile,
+	}, nil
+}
+
+// migrateBundleProfile 借用配置文件的迁移链升级方案包中的规则
+func migrateBundleProfile(profile json.RawMessage, version int) (*Profile, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(profile, &doc); err != nil {
+		return nil, fmt.Errorf("解析方案包失败: %w", err)
+	}
+
+	// 版本 2 之前规则直接位于配置顶层
+	var wrapped map[string]any
+	if version < 2 {
+		wrapped = map[string]any{
+			"version":    version,
+			"rules":      doc["rules"],
+			"axis_rules": doc["axis_rules"],
+		}
+	} else {
+		wrapped = map[string]any{
+			"version":  version,
+			"profiles": []any{doc},
+		}
+	}
+
+	data, err := json.Marshal(wrapped)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	p := cfg.Profiles[0]
+	if name, ok := doc["name"].(string); ok {
+		p.Name = name
+	}
+	return p, nil
+}
@@ -1,21 +1,110 @@
 package config
 
 import (
+	"gamepad-key-mapper/internal/gamepad"
 	"gamepad-key-mapper/internal/mapper"
 )
 
 // Config 应用配置
 type Config struct {
-	Rules          []*mapper.MappingRule `json:"rules"`
-	MinimizeToTray bool                  `json:"minimize_to_tray"`
-	StartMinimized bool                  `json:"start_minimized"`
+	Version        int        `json:"version"`         // 配置格式版本
+	Profiles       []*Profile `json:"profiles"`        // 规则配置方案
+	ActiveProfile  string     `json:"active_profile"`  // 当前使用的方案名称
+	DefaultProfile string     `json:"default_profile"` // 自动切换无匹配时使用的方案（空为第一个方案）
+	AutoSwitch     bool       `json:"auto_switch"`     // 根据前台窗口自动切换方案
+	MinimizeToTray bool       `json:"minimize_to_tray"`
+	StartMinimized bool       `json:"start_minimized"`
+
+	// SystemBindings 系统组合键（如按住 View+Menu 1秒暂停映射）
+	SystemBindings []SystemBinding `json:"system_bindings"`
+
+	// AllowExec 是否允许执行命令规则（配置可能来自他人分享，默认关闭）
+	AllowExec bool `json:"allow_exec"`
+
+	// LogLevel 日志级别（debug/info/warn/error，空为 info）
+	LogLevel string `json:"log_level"`
+
+	// Overlay 是否开启直播叠加层服务（推送手柄输入和规则触发，供 OBS 浏览器源显示）
+	Overlay bool `json:"overlay"`
+
+	// OverlayAddr 直播叠加层服务的监听地址（空为 DefaultOverlayAddr）
+	OverlayAddr string `json:"overlay_addr,omitempty"`
+}
+
+// DefaultOverlayAddr 直播叠加层服务的默认监听地址（只允许本机访问）
+const DefaultOverlayAddr = "127.0.0.1:9876"
+
+// OverlayListenAddr 返回直播叠加层服务的监听地址（未开启时为空）
+func (c *Config) OverlayListenAddr() string {
+	if !c.Overlay {
+		return ""
+	}
+	if c.OverlayAddr == "" {
+		return DefaultOverlayAddr
+	}
+	return c.OverlayAddr
 }
 
 // NewDefault 创建默认配置
 func NewDefault() *Config {
 	return &Config{
-		Rules:          make([]*mapper.MappingRule, 0),
+		Version:        CurrentVersion,
+		Profiles:       []*Profile{NewProfile(DefaultProfileName)},
+		ActiveProfile:  DefaultProfileName,
+		SystemBindings: DefaultSystemBindings(),
 		MinimizeToTray: true,
 		StartMinimized: false,
+		AllowExec:      false,
+		LogLevel:       "info",
+	}
+}
+
+// Clone 深拷贝配置（保存时在锁外编码，不能与方案的修改共享数据）
+func (c *Config) Clone() (*Config, error) {
+	clone := *c
+	clone.Profiles = make([]*Profile, len(c.Profiles))
+	for i, p := range c.Profiles {
+		cp, err := p.Clone(p.Name)
+		if err != nil {
+			return nil, err
+		}
+		clone.Profiles[i] = cp
+	}
+	if c.SystemBindings != nil {
+		clone.SystemBindings = make([]SystemBinding, len(c.SystemBindings))
+		for i, binding := range c.SystemBindings {
+			binding.Buttons = append([]gamepad.Button(nil), binding.Buttons...)
+			clone.SystemBindings[i] = binding
+		}
+	}
+	return &clone, nil
+}
+
+// normalize 补全缺省设置并保证至少有一个有效的当前方案
+func (c *Config) normalize() {
+	// 去掉空的方案条目（如手工编辑留下的 null）
+	profiles := c.Profiles[:0]
+	for _, p := range c.Profiles {
+		if p != nil {
+			profiles = append(profiles, p)
+		}
+	}
+	c.Profiles = profiles
+
+	if len(c.Profiles) == 0 {
+		c.Profiles = []*Profile{NewProfile(DefaultProfileName)}
+	}
+
+	for _, p := range c.Profiles {
+		if p.Rules == nil {
+			p.Rules = make([]*mapper.MappingRule, 0)
+		}
+		if p.AxisRules == nil {
+			p.AxisRules = make([]*mapper.AxisRule, 0)
+		}
+	}
+
+	if c.Profile(c.ActiveProfile) == nil {
+		c.ActiveProfile = c.Profiles[0].Name
 	}
 }
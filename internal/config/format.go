@@ -0,0 +1,280 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format 配置文件格式
+type Format int
+
+const (
+	FormatJSON Format = iota // JSON（默认）
+	FormatYAML               // YAML（.yaml / .yml）
+	FormatTOML               // TOML（.toml）
+)
+
+// String 返回格式名称
+func (f Format) String() string {
+	switch f {
+	case FormatJSON:
+		return "JSON"
+	case FormatYAML:
+		return "YAML"
+	case FormatTOML:
+		return "TOML"
+	default:
+		return "未知"
+	}
+}
+
+// FormatFromPath 根据文件扩展名识别配置格式（无法识别时为 JSON）
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatJSON
+	}
+}
+
+// DecodeFormat 按指定格式解析配置
+//
+// YAML 和 TOML 先转换为 JSON 再经过 Decode，版本识别和迁移对所有格式一致。
+func DecodeFormat(data []byte, format Format) (*Config, error) {
+	jsonData, err := toJSON(data, format)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(jsonData)
+}
+
+// toJSON 将指定格式的文档转换为 JSON
+func toJSON(data []byte, format Format) ([]byte, error) {
+	var doc any
+	switch format {
+	case FormatYAML:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("解析 YAML 失败: %w", err)
+		}
+	case FormatTOML:
+		var table map[string]any
+		if err := toml.Unmarshal(data, &table); err != nil {
+			return nil, fmt.Errorf("解析 TOML 失败: %w", err)
+		}
+		doc = table
+	default:
+		return data, nil
+	}
+
+	if doc == nil {
+		return nil, fmt.Errorf("配置内容为空")
+	}
+	jsonData, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("配置包含无法识别的值: %w", err)
+	}
+	return jsonData, nil
+}
+
+// Marshal 按指定格式序列化配置
+func Marshal(cfg *Config, format Format) ([]byte, error) {
+	return marshalAs(cfg, format, nil)
+}
+
+// marshalAs 按指定格式序列化任意值（经由 JSON，字段名和取值与 JSON 一致）
+//
+// YAML 格式会保留 previous 中同一位置的注释。
+func marshalAs(v any, format Format, previous []byte) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatYAML:
+		return jsonToYAML(data, previous)
+	case FormatTOML:
+		return jsonToTOML(data)
+	default:
+		return data, nil
+	}
+}
+
+// jsonToYAML 将 JSON 转换为块格式的 YAML（保持字段顺序）
+func jsonToYAML(data []byte, previous []byte) ([]byte, error) {
+	// JSON 本身就是合法的 YAML，解析为节点树可以保留字段顺序
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	blockStyle(&doc)
+
+	// 旧文件无法解析时只是丢失注释，不影响保存
+	var old yaml.Node
+	if len(previous) > 0 && yaml.Unmarshal(previous, &old) == nil {
+		carryComments(&old, &doc)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// blockStyle 清除 JSON 带来的流式和引号样式（编码器只在必要时加引号）
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	// YAML 1.1 解析器会把 yes/no/on/off 当作布尔值，保留引号以兼容其它工具
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && isYAML11Bool(node.Value) {
+		node.Style = yaml.DoubleQuotedStyle
+	}
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// isYAML11Bool 检查字符串在 YAML 1.1 中是否会被解析为布尔值
+func isYAML11Bool(s string) bool {
+	switch strings.ToLower(s) {
+	case "y", "yes", "n", "no", "on", "off", "true", "false":
+		return true
+	}
+	return false
+}
+
+// carryComments 将旧节点树中的注释复制到新节点树的对应位置
+//
+// 映射按键名对应；列表中的映射元素按 id 或 name 字段对应（规则可能被重新排序），
+// 其它列表元素按下标对应。
+func carryComments(old, node *yaml.Node) {
+	if old == nil || node == nil {
+		return
+	}
+	node.HeadComment = old.HeadComment
+	node.LineComment = old.LineComment
+	node.FootComment = old.FootComment
+
+	switch {
+	case node.Kind == yaml.DocumentNode && old.Kind == yaml.DocumentNode:
+		if len(node.Content) > 0 && len(old.Content) > 0 {
+			carryComments(old.Content[0], node.Content[0])
+		}
+	case node.Kind == yaml.MappingNode && old.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			for j := 0; j+1 < len(old.Content); j += 2 {
+				if old.Content[j].Value == key.Value {
+					carryComments(old.Content[j], key)
+					carryComments(old.Content[j+1], node.Content[i+1])
+					break
+				}
+			}
+		}
+	case node.Kind == yaml.SequenceNode && old.Kind == yaml.SequenceNode:
+		for i, item := range node.Content {
+			if match := matchSequenceItem(old, item, i); match != nil {
+				carryComments(match, item)
+			}
+		}
+	}
+}
+
+// matchSequenceItem 在旧列表中查找与新元素对应的元素
+func matchSequenceItem(old *yaml.Node, item *yaml.Node, index int) *yaml.Node {
+	if item.Kind == yaml.MappingNode {
+		for _, field := range []string{"id", "name"} {
+			value := mappingValue(item, field)
+			if value == "" {
+				continue
+			}
+			for _, candidate := range old.Content {
+				if mappingValue(candidate, field) == value {
+					return candidate
+				}
+			}
+			return nil
+		}
+	}
+	if index < len(old.Content) {
+		return old.Content[index]
+	}
+	return nil
+}
+
+// mappingValue 返回映射节点中指定字段的标量值
+func mappingValue(node *yaml.Node, field string) string {
+	if node.Kind != yaml.MappingNode {
+		return ""
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == field && node.Content[i+1].Kind == yaml.ScalarNode {
+			return node.Content[i+1].Value
+		}
+	}
+	return ""
+}
+
+// jsonToTOML 将 JSON 转换为 TOML
+func jsonToTOML(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = "  "
+	if err := enc.Encode(tomlValue(doc)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// tomlValue 转换为 TOML 可表示的值：整数保持为整数，null 字段省略
+func tomlValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			if item == nil {
+				continue // TOML 没有 null
+			}
+			out[k] = tomlValue(item)
+		}
+		return out
+	case []any:
+		out := make([]any, 0, len(val))
+		for _, item := range val {
+			if item != nil {
+				out = append(out, tomlValue(item))
+			}
+		}
+		return out
+	case json.Number:
+		if n, err := val.Int64(); err == nil {
+			return n
+		}
+		f, _ := val.Float64()
+		return f
+	default:
+		return v
+	}
+}
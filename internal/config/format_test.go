@@ -0,0 +1,120 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/keyboard"
+	"gamepad-key-mapper/internal/mapper"
+	"gamepad-key-mapper/internal/window"
+)
+
+// richConfig 返回用到各类字段的配置（各种规则、曲线小数、中文名称、空列表）
+func richConfig() *Config {
+	cfg := NewDefault()
+
+	game := NewProfile("游戏")
+	game.Rules = []*mapper.MappingRule{
+		mapper.NewRule("k1", gamepad.ButtonA, keyboard.KeyCode(0x20), keyboard.Modifiers{}),
+		mapper.NewRuleMultiKeys("k2", gamepad.ButtonRB, []keyboard.KeyCode{keyboard.KeyF1, keyboard.KeyCode(0x07)}, keyboard.Modifiers{Ctrl: true, Shift: true}),
+		mapper.NewRuleGamepad("g1", gamepad.ButtonPaddle1, []gamepad.Button{gamepad.ButtonB, gamepad.ButtonY}),
+		mapper.NewRuleExec("e1", gamepad.ButtonXbox, &mapper.ExecAction{
+			Mode: mapper.ExecShell, Command: `echo "hi" # 注释`, Env: []string{"A=1"}, OnPress: true, Timeout: 5,
+		}),
+	}
+	game.Rules[1].Name = "截图"
+	game.Rules[1].Description = "多行\n说明"
+	game.Rules[2].Enabled = false
+	game.AxisRules = []*mapper.AxisRule{{
+		ID: "a1", Source: gamepad.AxisRightX, Target: mapper.AxisTargetMouseX, Deadzone: 0.15, OuterRange: 0.9,
+		Curve: mapper.ResponseCurve{Type: mapper.CurveCustom, Points: []mapper.CurvePoint{{X: 0.5, Y: 0.25}}},
+		Scale: 12.5, Enabled: true,
+	}}
+	game.Match = []window.Matcher{{Field: window.MatchProcess, Mode: window.MatchExact, Pattern: "game.exe"}}
+
+	cfg.Profiles = append(cfg.Profiles, game)
+	cfg.ActiveProfile = "游戏"
+	cfg.DefaultProfile = DefaultProfileName
+	cfg.AutoSwitch = true
+	cfg.AllowExec = true
+	cfg.Overlay = true
+	cfg.OverlayAddr = "127.0.0.1:9000"
+	return cfg
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	chains := [][]Format{
+		{FormatJSON},
+		{FormatYAML},
+		{FormatTOML},
+		{FormatJSON, FormatYAML, FormatTOML, FormatJSON},
+		{FormatTOML, FormatYAML, FormatJSON},
+	}
+
+	for _, chain := range chains {
+		var names []string
+		for _, f := range chain {
+			names = append(names, f.String())
+		}
+		t.Run(strings.Join(names, "→"), func(t *testing.T) {
+			want := richConfig()
+			cfg := richConfig()
+			for _, format := range chain {
+				data, err := Marshal(cfg, format)
+				if err != nil {
+					t.Fatalf("Marshal %s: %v", format, err)
+				}
+				cfg, err = DecodeFormat(data, format)
+				if err != nil {
+					t.Fatalf("DecodeFormat %s: %v\n%s", format, err, data)
+				}
+			}
+			if !reflect.DeepEqual(cfg, want) {
+				got, _ := Marshal(cfg, FormatJSON)
+				expected, _ := Marshal(want, FormatJSON)
+				t.Errorf("config changed after round trip:\n%s\nwant\n%s", got, expected)
+			}
+		})
+	}
+}
+
+func TestSaveFileKeepsYAMLComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gamepad-key-mapper.yaml")
+	if err := SaveFile(path, richConfig()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := "# 我的手柄配置\n" + strings.Replace(string(data), "auto_switch: true", "auto_switch: true # 按窗口切换", 1)
+	if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.MinimizeToTray = false
+	if err := SaveFile(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, comment := range []string{"# 我的手柄配置", "auto_switch: true # 按窗口切换"} {
+		if !strings.Contains(string(data), comment) {
+			t.Errorf("saved YAML lost %q:\n%s", comment, data)
+		}
+	}
+	if !strings.Contains(string(data), "minimize_to_tray: false") {
+		t.Errorf("saved YAML missing the edit:\n%s", data)
+	}
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/keyboard"
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// ImportIssue 导入时无法转换的内容
+type ImportIssue struct {
+	Source  string // 在原文件中的位置（如 "set 1 / button 7"、"Joystick 1 / Button13"）
+	Message string // 原因
+}
+
+// String 返回问题描述
+func (i ImportIssue) String() string {
+	return i.Source + ": " + i.Message
+}
+
+// ImportReport 从其它映射工具导入的结果报告
+type ImportReport struct {
+	Format   string        // 原文件格式（如 "AntiMicroX"）
+	Imported int           // 成功转换的规则数
+	Issues   []ImportIssue // 不支持或无法识别的内容（不影响其它规则导入）
+}
+
+// addIssue 记录一个无法转换的内容
+func (r *ImportReport) addIssue(source string, format string, args ...any) {
+	r.Issues = append(r.Issues, ImportIssue{Source: source, Message: fmt.Sprintf(format, args...)})
+}
+
+// ForeignFormatExtensions 可导入的其它映射工具配置文件扩展名
+var ForeignFormatExtensions = []string{".amgp", ".cfg"}
+
+// IsForeignFormat 根据扩展名判断是否为其它映射工具的配置文件
+func IsForeignFormat(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range ForeignFormatExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportForeign 导入其它映射工具的配置文件（根据扩展名识别格式），转换为方案包
+//
+// 支持 AntiMicroX（.amgp）和 JoyToKey（.cfg）。只有文件无法读取或解析时返回错误，
+// 不支持的功能记录在报告中。
+func ImportForeign(path string) (*Bundle, *ImportReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var profile *Profile
+	var report *ImportReport
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".amgp":
+		profile, report, err = ImportAntiMicroX(f)
+	case ".cfg":
+		profile, report, err = ImportJoyToKey(f)
+	default:
+		return nil, nil, fmt.Errorf("不支持的文件类型: %s", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 方案名称使用文件名（.gamecontroller.amgp 这样的双扩展名一并去掉）
+	name := filepath.Base(path)
+	for ext := filepath.Ext(name); ext != ""; ext = filepath.Ext(name) {
+		name = strings.TrimSuffix(name, ext)
+	}
+	profile.Name = name
+
+	return &Bundle{
+		Kind:    BundleKind,
+		Version: CurrentVersion,
+		Meta:    BundleMeta{Name: name, Description: "从 " + report.Format + " 导入"},
+		Profile: profile,
+	}, report, nil
+}
+
+// importedRule 创建导入的键盘映射规则（只有修饰键时把修饰键本身作为目标键）
+func importedRule(profile *Profile, source gamepad.Button, keys []keyboard.KeyCode, mods keyboard.Modifiers) {
+	if len(keys) == 0 {
+		keys = mods.Keys()
+		mods = keyboard.Modifiers{}
+	}
+
+	id := fmt.Sprintf("rule_import_%d", len(profile.Rules)+1)
+	profile.Rules = append(profile.Rules, mapper.NewRuleMultiKeys(id, source, keys, mods))
+}
@@ -0,0 +1,332 @@
+package config
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/keyboard"
+)
+
+// amgpFile AntiMicroX 配置文件（根元素为 gamecontroller 或旧版的 joystick）
+type amgpFile struct {
+	XMLName xml.Name
+	Sets    []amgpSet `xml:"sets>set"`
+}
+
+// amgpSet AntiMicroX 的按键组（程序中可切换的一套映射）
+type amgpSet struct {
+	Index    int        `xml:"index,attr"`
+	Buttons  []amgpBtn  `xml:"button"`
+	Axes     []amgpAxis `xml:"axis"`
+	Triggers []amgpAxis `xml:"trigger"`
+	Sticks   []amgpPart `xml:"stick"`
+	DPads    []amgpPart `xml:"dpad"`
+}
+
+// amgpAxis 轴（正负方向各有一个虚拟按键）
+type amgpAxis struct {
+	Index          int       `xml:"index,attr"`
+	AxisButtons    []amgpBtn `xml:"axisbutton"`
+	TriggerButtons []amgpBtn `xml:"triggerbutton"`
+}
+
+// amgpPart 摇杆或十字键（每个方向一个虚拟按键）
+type amgpPart struct {
+	Index        int       `xml:"index,attr"`
+	StickButtons []amgpBtn `xml:"stickbutton"`
+	DPadButtons  []amgpBtn `xml:"dpadbutton"`
+}
+
+// amgpBtn 按键及其动作
+type amgpBtn struct {
+	Index  int        `xml:"index,attr"`
+	Turbo  bool       `xml:"turbo"`
+	Toggle bool       `xml:"toggle"`
+	Slots  []amgpSlot `xml:"slots>slot"`
+}
+
+// amgpSlot 按键的单个动作
+type amgpSlot struct {
+	Code string `xml:"code"`
+	Mode string `xml:"mode"`
+}
+
+// sdlButtons SDL GameController 按键顺序（AntiMicroX 的 button index 从 1 开始）
+var sdlButtons = []gamepad.Button{
+	gamepad.ButtonA,
+	gamepad.ButtonB,
+	gamepad.ButtonX,
+	gamepad.ButtonY,
+	gamepad.ButtonBack,
+	gamepad.ButtonXbox,
+	gamepad.ButtonStart,
+	gamepad.ButtonLeftThumb,
+	gamepad.ButtonRightThumb,
+	gamepad.ButtonLB,
+	gamepad.ButtonRB,
+	gamepad.ButtonDPadUp,
+	gamepad.ButtonDPadDown,
+	gamepad.ButtonDPadLeft,
+	gamepad.ButtonDPadRight,
+	gamepad.ButtonShare,
+	gamepad.ButtonPaddle1,
+	gamepad.ButtonPaddle2,
+	gamepad.ButtonPaddle3,
+	gamepad.ButtonPaddle4,
+}
+
+// sdlAxisButtons SDL 轴（index 从 1 开始）的负方向和正方向对应的按键，0 表示不支持
+var sdlAxisButtons = [][2]gamepad.Button{
+	{gamepad.ButtonLeftStickLeft, gamepad.ButtonLeftStickRight},
+	{gamepad.ButtonLeftStickUp, gamepad.ButtonLeftStickDown},
+	{gamepad.ButtonRightStickLeft, gamepad.ButtonRightStickRight},
+	{gamepad.ButtonRightStickUp, gamepad.ButtonRightStickDown},
+	{0, gamepad.ButtonLT},
+	{0, gamepad.ButtonRT},
+}
+
+// amgpStickButtons 摇杆方向（stickbutton index：1 上、3 右、5 下、7 左，偶数为斜向）
+var amgpStickButtons = map[int][2]gamepad.Button{
+	1: {gamepad.ButtonLeftStickUp, gamepad.ButtonRightStickUp},
+	3: {gamepad.ButtonLeftStickRight, gamepad.ButtonRightStickRight},
+	5: {gamepad.ButtonLeftStickDown, gamepad.ButtonRightStickDown},
+	7: {gamepad.ButtonLeftStickLeft, gamepad.ButtonRightStickLeft},
+}
+
+// amgpDPadButtons 十字键方向（dpadbutton index 为方向位：1 上、2 右、4 下、8 左）
+var amgpDPadButtons = map[int]gamepad.Button{
+	1: gamepad.ButtonDPadUp,
+	2: gamepad.ButtonDPadRight,
+	4: gamepad.ButtonDPadDown,
+	8: gamepad.ButtonDPadLeft,
+}
+
+// ImportAntiMicroX 导入 AntiMicroX 配置（.gamecontroller.amgp）
+//
+// 只导入第 1 组中映射到键盘的按键；鼠标、宏、连发等功能记录在报告中。
+func ImportAntiMicroX(r io.Reader) (*Profile, *ImportReport, error) {
+	var file amgpFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, nil, fmt.Errorf("解析 AntiMicroX 配置失败: %w", err)
+	}
+	if file.XMLName.Local != "gamecontroller" && file.XMLName.Local != "joystick" {
+		return nil, nil, fmt.Errorf("不是 AntiMicroX 配置文件")
+	}
+
+	report := &ImportReport{Format: "AntiMicroX"}
+	profile := NewProfile("")
+
+	if file.XMLName.Local == "joystick" {
+		report.addIssue("joystick", "旧版原始手柄配置，按键编号按标准手柄顺序解释，可能与原配置不一致")
+	}
+
+	for i, set := range file.Sets {
+		first := set.Index == 1 || (i == 0 && set.Index == 0)
+		if !first {
+			if !set.isEmpty() {
+				report.addIssue(fmt.Sprintf("set %d", set.Index), "只导入第 1 组按键，其它组已忽略")
+			}
+			continue
+		}
+		importAmgpSet(profile, report, set)
+	}
+
+	report.Imported = len(profile.Rules)
+	return profile, report, nil
+}
+
+// isEmpty 检查按键组是否没有任何动作
+func (s amgpSet) isEmpty() bool {
+	var buttons []amgpBtn
+	buttons = append(buttons, s.Buttons...)
+	for _, axis := range append(s.Axes, s.Triggers...) {
+		buttons = append(buttons, axis.AxisButtons...)
+		buttons = append(buttons, axis.TriggerButtons...)
+	}
+	for _, part := range append(s.Sticks, s.DPads...) {
+		buttons = append(buttons, part.StickButtons...)
+		buttons = append(buttons, part.DPadButtons...)
+	}
+	for _, btn := range buttons {
+		if len(btn.Slots) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// importAmgpSet 导入一组按键
+func importAmgpSet(profile *Profile, report *ImportReport, set amgpSet) {
+	prefix := fmt.Sprintf("set %d / ", set.Index)
+
+	for _, btn := range set.Buttons {
+		where := prefix + fmt.Sprintf("button %d", btn.Index)
+		if btn.Index < 1 || btn.Index > len(sdlButtons) {
+			if len(btn.Slots) > 0 {
+				report.addIssue(where, "不支持的按键编号")
+			}
+			continue
+		}
+		importAmgpButton(profile, report, where, sdlButtons[btn.Index-1], btn)
+	}
+
+	for _, axis := range append(set.Axes, set.Triggers...) {
+		for _, btn := range append(axis.AxisButtons, axis.TriggerButtons...) {
+			where := prefix + fmt.Sprintf("axis %d / button %d", axis.Index, btn.Index)
+			var source gamepad.Button
+			if axis.Index >= 1 && axis.Index <= len(sdlAxisButtons) && (btn.Index == 1 || btn.Index == 2) {
+				source = sdlAxisButtons[axis.Index-1][btn.Index-1]
+			}
+			if source == 0 {
+				if len(btn.Slots) > 0 {
+					report.addIssue(where, "不支持的轴方向")
+				}
+				continue
+			}
+			importAmgpButton(profile, report, where, source, btn)
+		}
+	}
+
+	for _, stick := range set.Sticks {
+		for _, btn := range stick.StickButtons {
+			where := prefix + fmt.Sprintf("stick %d / button %d", stick.Index, btn.Index)
+			dirs, ok := amgpStickButtons[btn.Index]
+			if !ok || stick.Index < 1 || stick.Index > 2 {
+				if len(btn.Slots) > 0 {
+					report.addIssue(where, "不支持斜向或未知摇杆方向")
+				}
+				continue
+			}
+			importAmgpButton(profile, report, where, dirs[stick.Index-1], btn)
+		}
+	}
+
+	for _, dpad := range set.DPads {
+		for _, btn := range dpad.DPadButtons {
+			where := prefix + fmt.Sprintf("dpad %d / button %d", dpad.Index, btn.Index)
+			source, ok := amgpDPadButtons[btn.Index]
+			if !ok || dpad.Index != 1 {
+				if len(btn.Slots) > 0 {
+					report.addIssue(where, "不支持斜向或未知十字键方向")
+				}
+				continue
+			}
+			importAmgpButton(profile, report, where, source, btn)
+		}
+	}
+}
+
+// importAmgpButton 将一个按键的键盘动作转换为规则（多个动作视为组合键）
+func importAmgpButton(profile *Profile, report *ImportReport, where string, source gamepad.Button, btn amgpBtn) {
+	if len(btn.Slots) == 0 {
+		return
+	}
+
+	var keys []keyboard.KeyCode
+	var mods keyboard.Modifiers
+	for _, slot := range btn.Slots {
+		mode := strings.ToLower(strings.TrimSpace(slot.Mode))
+		if mode != "" && mode != "keyboard" {
+			report.addIssue(where, "不支持的动作类型 %s", slot.Mode)
+			continue
+		}
+
+		code, err := strconv.ParseInt(strings.TrimSpace(slot.Code), 0, 64)
+		if err != nil {
+			report.addIssue(where, "无法识别的按键码 %s", slot.Code)
+			continue
+		}
+		if mod, ok := qtModifiers[int(code)]; ok {
+			mod(&mods)
+			continue
+		}
+		key, ok := qtKeyToKeyCode(int(code))
+		if !ok {
+			report.addIssue(where, "无法识别的按键码 %s", slot.Code)
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	if btn.Turbo {
+		report.addIssue(where, "不支持连发，已按普通按住导入")
+	}
+	if btn.Toggle {
+		report.addIssue(where, "不支持切换模式，已按普通按住导入")
+	}
+
+	if len(keys) == 0 && mods.IsEmpty() {
+		return
+	}
+	importedRule(profile, source, keys, mods)
+}
+
+// qtModifiers Qt 修饰键码
+var qtModifiers = map[int]func(*keyboard.Modifiers){
+	0x01000020: func(m *keyboard.Modifiers) { m.Shift = true },
+	0x01000021: func(m *keyboard.Modifiers) { m.Ctrl = true },
+	0x01000022: func(m *keyboard.Modifiers) { m.Win = true },
+	0x01000023: func(m *keyboard.Modifiers) { m.Alt = true },
+}
+
+// qtSpecialKeys Qt 特殊键码到 Windows 虚拟键码
+var qtSpecialKeys = map[int]keyboard.KeyCode{
+	0x01000000: keyboard.KeyEscape,
+	0x01000001: keyboard.KeyTab,
+	0x01000003: keyboard.KeyBackspace,
+	0x01000004: keyboard.KeyEnter,
+	0x01000005: keyboard.KeyEnter,
+	0x01000006: keyboard.KeyInsert,
+	0x01000007: keyboard.KeyDelete,
+	0x01000008: 0x13, // Pause
+	0x01000009: 0x2C, // Print Screen
+	0x01000010: keyboard.KeyHome,
+	0x01000011: keyboard.KeyEnd,
+	0x01000012: keyboard.KeyLeft,
+	0x01000013: keyboard.KeyUp,
+	0x01000014: keyboard.KeyRight,
+	0x01000015: keyboard.KeyDown,
+	0x01000016: keyboard.KeyPageUp,
+	0x01000017: keyboard.KeyPageDown,
+	0x01000024: 0x14, // Caps Lock
+	0x01000025: 0x90, // Num Lock
+	0x01000026: 0x91, // Scroll Lock
+}
+
+// qtPunctuation Qt 标点键码（ASCII）到 Windows OEM 虚拟键码（美式键盘布局）
+var qtPunctuation = map[int]keyboard.KeyCode{
+	';':  0xBA,
+	'=':  0xBB,
+	',':  0xBC,
+	'-':  0xBD,
+	'.':  0xBE,
+	'/':  0xBF,
+	'`':  0xC0,
+	'[':  0xDB,
+	'\\': 0xDC,
+	']':  0xDD,
+	'\'': 0xDE,
+}
+
+// qtKeyToKeyCode 将 Qt 键码转换为 Windows 虚拟键码
+func qtKeyToKeyCode(code int) (keyboard.KeyCode, bool) {
+	switch {
+	case code >= 'A' && code <= 'Z', code >= '0' && code <= '9', code == ' ':
+		return keyboard.KeyCode(code), true
+	case code >= 'a' && code <= 'z':
+		return keyboard.KeyCode(code - 'a' + 'A'), true
+	case code >= 0x01000030 && code <= 0x0100003B: // F1-F12
+		return keyboard.KeyF1 + keyboard.KeyCode(code-0x01000030), true
+	}
+	if key, ok := qtSpecialKeys[code]; ok {
+		return key, true
+	}
+	if key, ok := qtPunctuation[code]; ok {
+		return key, true
+	}
+	return 0, false
+}
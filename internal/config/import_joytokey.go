@@ -0,0 +1,237 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/keyboard"
+)
+
+// joyToKeyButtons JoyToKey 中 XInput 手柄的按键编号（Button01 起）
+var joyToKeyButtons = []gamepad.Button{
+	gamepad.ButtonA,
+	gamepad.ButtonB,
+	gamepad.ButtonX,
+	gamepad.ButtonY,
+	gamepad.ButtonLB,
+	gamepad.ButtonRB,
+	gamepad.ButtonBack,
+	gamepad.ButtonStart,
+	gamepad.ButtonLeftThumb,
+	gamepad.ButtonRightThumb,
+	gamepad.ButtonLT,
+	gamepad.ButtonRT,
+}
+
+// joyToKeyAxes JoyToKey 中 XInput 手柄的轴（Axis1 起）负方向（n）和正方向（p）
+var joyToKeyAxes = [][2]gamepad.Button{
+	{gamepad.ButtonLeftStickLeft, gamepad.ButtonLeftStickRight},   // X
+	{gamepad.ButtonLeftStickUp, gamepad.ButtonLeftStickDown},      // Y
+	{gamepad.ButtonRT, gamepad.ButtonLT},                          // Z（两个扳机共用）
+	{gamepad.ButtonRightStickUp, gamepad.ButtonRightStickDown},    // R
+	{gamepad.ButtonRightStickLeft, gamepad.ButtonRightStickRight}, // U
+}
+
+// joyToKeyPOV 十字键方向（POV1-1 起，顺时针 8 个方向，偶数为斜向）
+var joyToKeyPOV = map[int]gamepad.Button{
+	1: gamepad.ButtonDPadUp,
+	3: gamepad.ButtonDPadRight,
+	5: gamepad.ButtonDPadDown,
+	7: gamepad.ButtonDPadLeft,
+}
+
+// joyToKeyKeyPattern 按键条目名称
+var joyToKeyKeyPattern = regexp.MustCompile(`^(?i)(?:Button(\d+)|Axis(\d+)([np])|POV(\d+)-(\d+))$`)
+
+// ImportJoyToKey 导入 JoyToKey 配置（.cfg）
+//
+// 只导入第 1 个手柄中映射到键盘的按键；鼠标、连发、组切换等功能记录在报告中。
+func ImportJoyToKey(r io.Reader) (*Profile, *ImportReport, error) {
+	sections, err := parseINI(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("解析 JoyToKey 配置失败: %w", err)
+	}
+	if _, ok := sections["config"]; !ok {
+		return nil, nil, fmt.Errorf("不是 JoyToKey 配置文件")
+	}
+
+	report := &ImportReport{Format: "JoyToKey"}
+	profile := NewProfile("")
+
+	var names []string
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !strings.HasPrefix(name, "joystick") {
+			continue
+		}
+		if name != "joystick 1" {
+			report.addIssue(sections[name].title, "只导入第 1 个手柄，其它手柄已忽略")
+			continue
+		}
+		importJoyToKeySection(profile, report, sections[name])
+	}
+
+	report.Imported = len(profile.Rules)
+	return profile, report, nil
+}
+
+// importJoyToKeySection 导入一个手柄的按键
+func importJoyToKeySection(profile *Profile, report *ImportReport, section *iniSection) {
+	for _, entry := range section.entries {
+		m := joyToKeyKeyPattern.FindStringSubmatch(entry.key)
+		if m == nil {
+			continue // 其它设置（如阈值）
+		}
+		where := section.title + " / " + entry.key
+
+		var source gamepad.Button
+		switch {
+		case m[1] != "":
+			n, _ := strconv.Atoi(m[1])
+			if n >= 1 && n <= len(joyToKeyButtons) {
+				source = joyToKeyButtons[n-1]
+			}
+		case m[2] != "":
+			n, _ := strconv.Atoi(m[2])
+			if n >= 1 && n <= len(joyToKeyAxes) {
+				dir := 0
+				if strings.EqualFold(m[3], "p") {
+					dir = 1
+				}
+				source = joyToKeyAxes[n-1][dir]
+			}
+		default:
+			pov, _ := strconv.Atoi(m[4])
+			dir, _ := strconv.Atoi(m[5])
+			if pov == 1 {
+				source = joyToKeyPOV[dir]
+			}
+		}
+
+		keys, mods, ok := parseJoyToKeyValue(report, where, entry.value)
+		if !ok {
+			continue
+		}
+		if source == 0 {
+			report.addIssue(where, "不支持的按键或方向")
+			continue
+		}
+		importedRule(profile, source, keys, mods)
+	}
+}
+
+// parseJoyToKeyValue 解析按键条目的值（"类型, 键1, 键2, 键3, ..."，键为十六进制虚拟键码）
+//
+// 返回 ok=false 表示该条目没有可导入的键盘动作。
+func parseJoyToKeyValue(report *ImportReport, where string, value string) ([]keyboard.KeyCode, keyboard.Modifiers, bool) {
+	fields := strings.Split(value, ",")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+
+	kind, err := strconv.Atoi(fields[0])
+	if err != nil {
+		report.addIssue(where, "无法识别的条目 %s", value)
+		return nil, keyboard.Modifiers{}, false
+	}
+	switch kind {
+	case 0:
+		return nil, keyboard.Modifiers{}, false // 未分配
+	case 1:
+	default:
+		report.addIssue(where, "不支持的动作类型 %d（只支持键盘）", kind)
+		return nil, keyboard.Modifiers{}, false
+	}
+
+	var keys []keyboard.KeyCode
+	var mods keyboard.Modifiers
+	for i := 1; i < len(fields) && i <= 3; i++ {
+		code, err := strconv.ParseUint(fields[i], 16, 8)
+		if err != nil {
+			report.addIssue(where, "无法识别的按键码 %s", fields[i])
+			continue
+		}
+		switch keyboard.KeyCode(code) {
+		case 0:
+			// 空位
+		case keyboard.KeyShift, keyboard.KeyLShift, keyboard.KeyRShift:
+			mods.Shift = true
+		case keyboard.KeyCtrl, keyboard.KeyLCtrl, keyboard.KeyRCtrl:
+			mods.Ctrl = true
+		case keyboard.KeyAlt, keyboard.KeyLAlt, keyboard.KeyRAlt:
+			mods.Alt = true
+		case keyboard.KeyLWin, keyboard.KeyRWin:
+			mods.Win = true
+		default:
+			keys = append(keys, keyboard.KeyCode(code))
+		}
+	}
+
+	if len(keys) == 0 && mods.IsEmpty() {
+		return nil, keyboard.Modifiers{}, false
+	}
+	return keys, mods, true
+}
+
+// iniSection INI 文件中的一节
+type iniSection struct {
+	title   string
+	entries []iniEntry
+}
+
+// iniEntry INI 文件中的一个键值对
+type iniEntry struct {
+	key   string
+	value string
+}
+
+// parseINI 解析 INI 文件，节名转换为小写作为键（保留原标题用于报告）
+func parseINI(r io.Reader) (map[string]*iniSection, error) {
+	sections := make(map[string]*iniSection)
+	var current *iniSection
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if lineNo == 1 {
+			line = strings.TrimPrefix(line, "\uFEFF") // UTF-8 BOM
+		}
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("第 %d 行: 节名缺少 ]", lineNo)
+			}
+			title := strings.TrimSpace(line[1 : len(line)-1])
+			current = &iniSection{title: title}
+			sections[strings.ToLower(title)] = current
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("第 %d 行: 缺少 =", lineNo)
+		}
+		if current == nil {
+			return nil, fmt.Errorf("第 %d 行: 条目不在任何节中", lineNo)
+		}
+		current.entries = append(current.entries, iniEntry{
+			key:   strings.TrimSpace(key),
+			value: strings.TrimSpace(value),
+		})
+	}
+
+	return sections, scanner.Err()
+}
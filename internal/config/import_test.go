@@ -0,0 +1,146 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gamepad-key-mapper/internal/keyboard"
+)
+
+// importedRules 将导入的规则格式化为 "源按键 -> 快捷键"，便于与期望值比较
+func importedRules(p *Profile) []string {
+	var lines []string
+	for _, r := range p.Rules {
+		lines = append(lines, r.SourceKey.Name()+" -> "+keyboard.FormatShortcut(r.TargetKeys, r.Modifiers))
+	}
+	return lines
+}
+
+// importIssues 将报告中的问题格式化为字符串
+func importIssues(r *ImportReport) []string {
+	var lines []string
+	for _, issue := range r.Issues {
+		lines = append(lines, issue.String())
+	}
+	return lines
+}
+
+func TestImportForeignFixtures(t *testing.T) {
+	tests := []struct {
+		file   string
+		name   string
+		format string
+		rules  []string
+		issues []string
+	}{
+		{
+			file:   "xbox.gamecontroller.amgp",
+			name:   "xbox",
+			format: "AntiMicroX",
+			rules: []string{
+				"A -> Space",
+				"B -> Ctrl+C",
+				"X -> R",
+				"Menu -> F5",
+				"RightStickRight -> L",
+				"LT -> Shift",
+				"LeftStickUp -> W",
+				"DPadUp -> Up",
+			},
+			issues: []string{
+				"set 1 / button 3: 不支持连发，已按普通按住导入",
+				"set 1 / button 4: 不支持的动作类型 mousebutton",
+				"set 1 / button 25: 不支持的按键编号",
+				"set 1 / stick 1 / button 2: 不支持斜向或未知摇杆方向",
+				"set 2: 只导入第 1 组按键，其它组已忽略",
+			},
+		},
+		{
+			file:   "racing.cfg",
+			name:   "racing",
+			format: "JoyToKey",
+			rules: []string{
+				"LeftStickLeft -> Left",
+				"LeftStickRight -> Right",
+				"RT -> Shift",
+				"DPadUp -> W",
+				"A -> Space",
+				"B -> Ctrl+C",
+				"Menu -> Escape",
+			},
+			issues: []string{
+				"Joystick 1 / POV1-2: 不支持的按键或方向",
+				"Joystick 1 / Button03: 不支持的动作类型 2（只支持键盘）",
+				"Joystick 1 / Button20: 不支持的按键或方向",
+				"Joystick 2: 只导入第 1 个手柄，其它手柄已忽略",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			bundle, report, err := ImportForeign(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if bundle.Meta.Name != tt.name || bundle.Profile.Name != tt.name {
+				t.Errorf("name = %q / %q, want %q", bundle.Meta.Name, bundle.Profile.Name, tt.name)
+			}
+			if report.Format != tt.format {
+				t.Errorf("format = %q, want %q", report.Format, tt.format)
+			}
+			if got := importedRules(bundle.Profile); !reflect.DeepEqual(got, tt.rules) {
+				t.Errorf("rules:\n got %q\nwant %q", got, tt.rules)
+			}
+			if report.Imported != len(tt.rules) {
+				t.Errorf("imported = %d, want %d", report.Imported, len(tt.rules))
+			}
+			if got := importIssues(report); !reflect.DeepEqual(got, tt.issues) {
+				t.Errorf("issues:\n got %q\nwant %q", got, tt.issues)
+			}
+
+			// 导入的方案应能保存为方案包并完整读回（读取时会校验规则）
+			path := filepath.Join(t.TempDir(), tt.name+".json")
+			if err := SaveBundle(path, bundle); err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := LoadBundle(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := importedRules(decoded.Profile); !reflect.DeepEqual(got, tt.rules) {
+				t.Errorf("round-trip rules:\n got %q\nwant %q", got, tt.rules)
+			}
+		})
+	}
+}
+
+func TestImportForeignRejectsOtherFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"other.amgp", `<profile><sets/></profile>`, "不是 AntiMicroX 配置文件"},
+		{"broken.amgp", `<gamecontroller><sets>`, "解析 AntiMicroX 配置失败"},
+		{"other.cfg", "[Settings]\nKey=1\n", "不是 JoyToKey 配置文件"},
+		{"broken.cfg", "[Config\n", "解析 JoyToKey 配置失败"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if strings.HasSuffix(tt.name, ".amgp") {
+				_, _, err = ImportAntiMicroX(strings.NewReader(tt.content))
+			} else {
+				_, _, err = ImportJoyToKey(strings.NewReader(tt.content))
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
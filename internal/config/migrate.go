@@ -0,0 +1,155 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CurrentVersion 当前配置文件格式版本
+//
+// 版本历史：
+//
+//	0: 最早的格式，规则只有单个 target_key，source_key 为 uint16
+//	1: 规则支持多目标键（target_keys）、手柄目标（target_buttons）和 target_type
+//	2: 规则按方案（profiles）保存
+//	3: 按键、键盘键和目标类型以名称保存（如 "A"、"F5"、"keyboard"），修饰键保存为 "Ctrl+Shift"
+const CurrentVersion = 3
+
+// migration 将配置从 from 版本升级到 from+1 版本
+type migration struct {
+	from    int
+	migrate func(doc map[string]any) error
+}
+
+// migrations 迁移链（按版本顺序依次执行）
+var migrations = []migration{
+	{from: 0, migrate: migrateV0ToV1},
+	{from: 1, migrate: migrateV1ToV2},
+	{from: 2, migrate: migrateV2ToV3},
+}
+
+// Decode 解析配置数据，自动识别版本并迁移到当前格式
+func Decode(data []byte) (*Config, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析配置失败: %w", err)
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("配置内容为空")
+	}
+
+	version, err := detectVersion(doc)
+	if err != nil {
+		return nil, err
+	}
+	if version > CurrentVersion {
+		return nil, fmt.Errorf("配置文件版本 %d 高于当前程序支持的版本 %d，请升级程序", version, CurrentVersion)
+	}
+
+	for _, m := range migrations {
+		if m.from < version {
+			continue
+		}
+		if err := m.migrate(doc); err != nil {
+			return nil, fmt.Errorf("从版本 %d 迁移配置失败: %w", m.from, err)
+		}
+	}
+	doc["version"] = CurrentVersion
+
+	// 没有系统组合键设置的配置来自加入该功能之前的版本，不自动启用默认组合键：
+	// 组合键中的按键按下后要暂缓一小段时间，会改变 View、Menu 已有映射的响应。
+	// 新建的配置（NewDefault）仍使用默认组合键。
+	if doc["system_bindings"] == nil {
+		doc["system_bindings"] = []any{}
+	}
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// 在默认配置上解析，文件中缺失的设置保留默认值
+	cfg := NewDefault()
+	if err := json.Unmarshal(migrated, cfg); err != nil {
+		return nil, fmt.Errorf("解析配置失败: %w", err)
+	}
+
+	cfg.normalize()
+	return cfg, nil
+}
+
+// detectVersion 识别配置版本（旧文件没有 version 字段，根据结构推断）
+func detectVersion(doc map[string]any) (int, error) {
+	if v, ok := doc["version"]; ok {
+		n, ok := v.(float64)
+		if !ok || n < 0 || n != float64(int(n)) {
+			return 0, fmt.Errorf("无效的配置版本: %v", v)
+		}
+		return int(n), nil
+	}
+
+	if _, ok := doc["profiles"]; ok {
+		return 2, nil
+	}
+
+	// 含有 target_key 的规则属于版本 0
+	rules, _ := doc["rules"].([]any)
+	for _, r := range rules {
+		rule, _ := r.(map[string]any)
+		if _, ok := rule["target_key"]; ok {
+			return 0, nil
+		}
+	}
+
+	return 1, nil
+}
+
+// migrateV0ToV1 单个 target_key 转换为 target_keys 列表
+func migrateV0ToV1(doc map[string]any) error {
+	rules, _ := doc["rules"].([]any)
+	for i, r := range rules {
+		rule, ok := r.(map[string]any)
+		if !ok {
+			return fmt.Errorf("第 %d 条规则格式无效", i+1)
+		}
+
+		if key, ok := rule["target_key"]; ok {
+			if _, exists := rule["target_keys"]; !exists {
+				rule["target_keys"] = []any{key}
+			}
+			delete(rule, "target_key")
+		}
+		if _, ok := rule["target_type"]; !ok {
+			rule["target_type"] = 0 // 版本 0 只有键盘目标
+		}
+	}
+	return nil
+}
+
+// migrateV1ToV2 扁平规则列表移入默认方案
+func migrateV1ToV2(doc map[string]any) error {
+	profile := map[string]any{
+		"name":       DefaultProfileName,
+		"rules":      orEmpty(doc["rules"]),
+		"axis_rules": orEmpty(doc["axis_rules"]),
+	}
+	delete(doc, "rules")
+	delete(doc, "axis_rules")
+
+	doc["profiles"] = []any{profile}
+	doc["active_profile"] = DefaultProfileName
+	return nil
+}
+
+// migrateV2ToV3 结构不变；旧的数值按键在解析时直接识别，保存时改写为名称
+func migrateV2ToV3(doc map[string]any) error {
+	return nil
+}
+
+// orEmpty 将缺失或为 null 的列表替换为空列表
+func orEmpty(v any) any {
+	if v == nil {
+		return []any{}
+	}
+	return v
+}
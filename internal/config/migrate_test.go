@@ -0,0 +1,64 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"gamepad-key-mapper/internal/gamepad"
+)
+
+func TestDecodeSystemBindings(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []SystemBinding
+	}{
+		{
+			name: "legacy flat config",
+			data: `{"rules": []}`,
+			want: []SystemBinding{},
+		},
+		{
+			name: "profiles without bindings",
+			data: `{"version": 3, "profiles": [{"name": "默认", "rules": []}]}`,
+			want: []SystemBinding{},
+		},
+		{
+			name: "null bindings",
+			data: `{"version": 3, "system_bindings": null}`,
+			want: []SystemBinding{},
+		},
+		{
+			name: "explicitly disabled",
+			data: `{"version": 3, "system_bindings": []}`,
+			want: []SystemBinding{},
+		},
+		{
+			name: "configured bindings",
+			data: `{"version": 3, "system_bindings": [{"buttons": ["View", "Menu"], "hold_ms": 1000, "action": 0}]}`,
+			want: DefaultSystemBindings(),
+		},
+		{
+			name: "custom bindings",
+			data: `{"version": 3, "system_bindings": [{"buttons": ["LB", "RB"], "hold_ms": 500, "action": 1}]}`,
+			want: []SystemBinding{{Buttons: []gamepad.Button{gamepad.ButtonLB, gamepad.ButtonRB}, HoldMs: 500, Action: SystemNextProfile}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Decode([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cfg.SystemBindings, tt.want) {
+				t.Errorf("SystemBindings = %+v, want %+v", cfg.SystemBindings, tt.want)
+			}
+		})
+	}
+
+	// 新建的配置默认启用组合键
+	if got := NewDefault().SystemBindings; !reflect.DeepEqual(got, DefaultSystemBindings()) {
+		t.Errorf("NewDefault().SystemBindings = %+v, want defaults", got)
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"encoding/json"
+
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/mapper"
+	"gamepad-key-mapper/internal/window"
+)
+
+// DefaultProfileName 默认方案名称
+const DefaultProfileName = "默认"
+
+// Profile 一组命名的映射规则
+type Profile struct {
+	Name      string                `json:"name"`
+	Rules     []*mapper.MappingRule `json:"rules"`
+	AxisRules []*mapper.AxisRule    `json:"axis_rules"`
+
+	// Match 自动切换匹配条件（任一条件满足即激活）
+	Match []window.Matcher `json:"match,omitempty"`
+}
+
+// NewProfile 创建空方案
+func NewProfile(name string) *Profile {
+	return &Profile{
+		Name:      name,
+		Rules:     make([]*mapper.MappingRule, 0),
+		AxisRules: make([]*mapper.AxisRule, 0),
+	}
+}
+
+// Matches 检查窗口是否满足方案的自动切换条件
+func (p *Profile) Matches(info window.Info) bool {
+	for _, m := range p.Match {
+		if m.Match(info) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasConflict 检查方案中是否已有其它启用的规则使用该源按键（与 Mapper.HasConflict 相同）
+func (p *Profile) HasConflict(source gamepad.Button, excludeID string) bool {
+	for _, rule := range p.Rules {
+		if rule.Enabled && rule.SourceKey == source && rule.ID != excludeID {
+			return true
+		}
+	}
+	return false
+}
+
+// Profile 根据名称查找方案
+func (c *Config) Profile(name string) *Profile {
+	for _, p := range c.Profiles {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Active 返回当前使用的方案
+func (c *Config) Active() *Profile {
+	if p := c.Profile(c.ActiveProfile); p != nil {
+		return p
+	}
+	if len(c.Profiles) > 0 {
+		return c.Profiles[0]
+	}
+	return nil
+}
+
+// ProfileNames 返回所有方案名称
+func (c *Config) ProfileNames() []string {
+	names := make([]string, len(c.Profiles))
+	for i, p := range c.Profiles {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// MatchProfile 根据前台窗口选择方案（按顺序第一个匹配的方案，无匹配时使用默认方案）
+func (c *Config) MatchProfile(info window.Info) string {
+	for _, p := range c.Profiles {
+		if p.Matches(info) {
+			return p.Name
+		}
+	}
+
+	if c.Profile(c.DefaultProfile) != nil {
+		return c.DefaultProfile
+	}
+	if len(c.Profiles) > 0 {
+		return c.Profiles[0].Name
+	}
+	return ""
+}
+
+// Clone 深拷贝方案并使用新名称
+func (p *Profile) Clone(name string) (*Profile, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var clone Profile
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, err
+	}
+	clone.Name = name
+	return &clone, nil
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// ShareCodePrefix 分享码前缀（数字为编码格式版本，与规则格式版本无关）
+const ShareCodePrefix = "GKM1:"
+
+// maxShareCodePayload 分享码解压后的最大长度（防止恶意构造的压缩数据）
+const maxShareCodePayload = 1 << 20
+
+// sharePayload 分享码中的内容（只包含方案本身，字段名尽量短）
+type sharePayload struct {
+	Version int             `json:"v"` // 规则格式版本（与配置文件 version 相同）
+	Profile json.RawMessage `json:"p"` // 方案
+}
+
+// EncodeShareCode 将方案编码为可粘贴到聊天中的分享码
+//
+// 格式：前缀 + base64url(deflate(紧凑 JSON) + CRC32(紧凑 JSON))。
+// 同一方案总是得到相同的分享码。
+func EncodeShareCode(p *Profile) (string, error) {
+	profile, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(sharePayload{Version: CurrentVersion, Profile: profile})
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	sum := make([]byte, 4)
+	binary.BigEndian.PutUint32(sum, crc32.ChecksumIEEE(payload))
+	buf.Write(sum)
+
+	return ShareCodePrefix + base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecodeShareCode 解析分享码，返回方案包（旧版本的规则会迁移到当前格式）
+//
+// 分享码中的空白字符（聊天软件自动换行）会被忽略，其它任何错误都会拒绝。
+func DecodeShareCode(code string) (*Bundle, error) {
+	code = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, code)
+
+	if !strings.HasPrefix(code, ShareCodePrefix) {
+		if strings.HasPrefix(code, "GKM") {
+			return nil, errors.New("分享码版本不受支持，请升级程序")
+		}
+		return nil, errors.New("不是有效的分享码")
+	}
+
+	raw, err := base64.RawURLEncoding.Strict().DecodeString(code[len(ShareCodePrefix):])
+	if err != nil {
+		return nil, errors.New("分享码已损坏（可能复制不完整）")
+	}
+	if len(raw) < 5 {
+		return nil, errors.New("分享码已损坏（可能复制不完整）")
+	}
+	compressed, sum := raw[:len(raw)-4], binary.BigEndian.Uint32(raw[len(raw)-4:])
+
+	payload, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(compressed)), maxShareCodePayload+1))
+	if err != nil {
+		return nil, errors.New("分享码已损坏（可能复制不完整）")
+	}
+	if len(payload) > maxShareCodePayload {
+		return nil, errors.New("分享码内容过大")
+	}
+	if crc32.ChecksumIEEE(payload) != sum {
+		return nil, errors.New("分享码校验失败（可能被修改或复制不完整）")
+	}
+
+	var share sharePayload
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&share); err != nil {
+		return nil, fmt.Errorf("分享码内容无效: %w", err)
+	}
+	if dec.More() {
+		return nil, errors.New("分享码内容无效: 多余的数据")
+	}
+	if len(share.Profile) == 0 || string(share.Profile) == "null" {
+		return nil, errors.New("分享码中没有方案")
+	}
+
+	profile, err := migrateBundleProfile(share.Profile, share.Version)
+	if err != nil {
+		return nil, fmt.Errorf("分享码内容无效: %w", err)
+	}
+	if errs := profile.validate(); len(errs) > 0 {
+		return nil, fmt.Errorf("分享码内容无效: %w", errors.Join(errs...))
+	}
+
+	return &Bundle{
+		Kind:    BundleKind,
+		Version: CurrentVersion,
+		Meta:    BundleMeta{Name: profile.Name},
+		Profile: profile,
+	}, nil
+}
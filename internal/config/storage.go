@@ -1,15 +1,52 @@
 package config
 
 import (
-	"encoding/json"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 )
 
 const configFileName = "gamepad-key-mapper.json"
 
+// configExtensions 自动查找配置文件时依次尝试的扩展名
+var configExtensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+var (
+	configPathOverride string
+	configPathMu       sync.RWMutex
+)
+
+// SetConfigPath 指定配置文件路径（为空时恢复默认查找），格式由扩展名决定
+func SetConfigPath(path string) error {
+	if path != "" {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		path = abs
+	}
+
+	configPathMu.Lock()
+	defer configPathMu.Unlock()
+	configPathOverride = path
+	return nil
+}
+
 // GetConfigPath 获取配置文件路径
+//
+// 未通过 SetConfigPath 指定时，在配置目录中查找已存在的
+// gamepad-key-mapper.json/.yaml/.yml/.toml，都不存在时使用 JSON。
 func GetConfigPath() (string, error) {
+	configPathMu.RLock()
+	override := configPathOverride
+	configPathMu.RUnlock()
+	if override != "" {
+		return override, nil
+	}
+
 	// 优先使用用户配置目录
 	configDir, err := os.UserConfigDir()
 	if err != nil {
@@ -18,7 +55,7 @@ func GetConfigPath() (string, error) {
 		if err != nil {
 			return "", err
 		}
-		return filepath.Join(filepath.Dir(execPath), configFileName), nil
+		return findConfigFile(filepath.Join(filepath.Dir(execPath), configFileName)), nil
 	}
 
 	// 创建应用专属目录
@@ -27,7 +64,38 @@ func GetConfigPath() (string, error) {
 		return "", err
 	}
 
-	return filepath.Join(appDir, configFileName), nil
+	return findConfigFile(filepath.Join(appDir, configFileName)), nil
+}
+
+// findConfigFile 返回已存在的同名配置文件（按 configExtensions 顺序），都不存在时返回 defaultPath
+func findConfigFile(defaultPath string) string {
+	base := strings.TrimSuffix(defaultPath, filepath.Ext(defaultPath))
+	for _, ext := range configExtensions {
+		if _, err := os.Stat(base + ext); err == nil {
+			return base + ext
+		}
+	}
+	return defaultPath
+}
+
+// LoadError 配置文件无法读取（原文件已备份，调用方得到的是默认配置）
+type LoadError struct {
+	Path       string // 配置文件路径
+	BackupPath string // 备份路径（备份失败时为空）
+	Err        error  // 原始错误
+}
+
+// Error 返回错误描述
+func (e *LoadError) Error() string {
+	if e.BackupPath == "" {
+		return fmt.Sprintf("配置文件 %s 无法读取，已使用默认配置: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("配置文件 %s 无法读取，已备份到 %s 并使用默认配置: %v", e.Path, e.BackupPath, e.Err)
+}
+
+// Unwrap 返回原始错误
+func (e *LoadError) Unwrap() error {
+	return e.Err
 }
 
 // Load 加载配置
@@ -36,25 +104,40 @@ func Load() (*Config, error) {
 	if err != nil {
 		return NewDefault(), nil
 	}
+	return LoadFile(path)
+}
 
+// LoadFile 从指定路径加载配置（格式由扩展名决定）
+//
+// 文件不存在时返回默认配置；文件损坏或版本不受支持时，原文件备份后返回
+// 默认配置和 *LoadError，由调用方提示用户，并在用户恢复备份或确认之前不要保存。
+func LoadFile(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// 配置文件不存在，返回默认配置
+			slog.Info("config file not found, using defaults", "path", path)
 			return NewDefault(), nil
 		}
 		return nil, err
 	}
 
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		// 配置文件损坏，备份并返回默认配置
-		backupPath := path + ".backup"
-		os.Rename(path, backupPath)
-		return NewDefault(), nil
+	cfg, err := DecodeFormat(data, FormatFromPath(path))
+	if err != nil {
+		// 配置文件无法使用，备份后返回默认配置，避免之后的保存覆盖用户数据
+		// （写入备份目录，可以和其它备份一样在界面中列出和恢复）
+		loadErr := &LoadError{Path: path, Err: err}
+		backupPath, backupErr := writeBackup(path, data)
+		loadErr.BackupPath = backupPath
+		if backupErr != nil {
+			slog.Error("backing up unusable config failed", "path", path, "err", backupErr)
+		}
+		slog.Error("config file unusable, using defaults", "path", path, "backup", loadErr.BackupPath, "err", err)
+		return NewDefault(), loadErr
 	}
 
-	return &cfg, nil
+	slog.Info("config loaded", "path", path, "profiles", len(cfg.Profiles))
+	return cfg, nil
 }
 
 // Save 保存配置
@@ -63,38 +146,46 @@ func Save(cfg *Config) error {
 	if err != nil {
 		return err
 	}
+	return SaveFile(path, cfg)
+}
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
-	if err != nil {
-		return err
-	}
+// saveMu 串行化配置写入（备份轮换和重命名不能交错）
+var saveMu sync.Mutex
 
-	return os.WriteFile(path, data, 0644)
+// SaveFile 保存配置到指定路径（格式由扩展名决定，原子写入，覆盖前按间隔备份旧文件）
+//
+// YAML 文件中用户写的注释会尽量保留到对应位置。
+func SaveFile(path string, cfg *Config) error {
+	return saveFile(path, cfg, false)
 }
 
-// SaveRules 仅保存规则（保留其他设置）
-func SaveRules(rules []*Rule) error {
-	cfg, err := Load()
+// saveFile 保存配置，forceBackup 为 true 时不受备份间隔限制
+func saveFile(path string, cfg *Config, forceBackup bool) error {
+	cfg.Version = CurrentVersion
+
+	saveMu.Lock()
+	defer saveMu.Unlock()
+
+	format := FormatFromPath(path)
+
+	var previous []byte
+	if format == FormatYAML {
+		previous, _ = os.ReadFile(path)
+	}
+
+	data, err := marshalAs(cfg, format, previous)
 	if err != nil {
-		cfg = NewDefault()
+		return err
 	}
 
-	// 类型转换 - 这里需要注意Rule和MappingRule的关系
-	// 实际使用时应该直接使用mapper.MappingRule
-	return Save(cfg)
-}
+	if err := backupCurrent(path, data, forceBackup); err != nil {
+		return fmt.Errorf("备份配置失败: %w", err)
+	}
 
-// Rule 是一个别名，用于方便导入
-type Rule = struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	SourceKey uint16 `json:"source_key"`
-	TargetKey int    `json:"target_key"`
-	Modifiers struct {
-		Ctrl  bool `json:"ctrl"`
-		Alt   bool `json:"alt"`
-		Shift bool `json:"shift"`
-		Win   bool `json:"win"`
-	} `json:"modifiers"`
-	Enabled bool `json:"enabled"`
+	recordWrite(path, data)
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return err
+	}
+	slog.Debug("config saved", "path", path)
+	return nil
 }
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadFileBacksUpUnusableConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gamepad-key-mapper.json")
+	broken := []byte(`{"version": 3, "profiles": [`)
+	if err := os.WriteFile(path, broken, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("LoadFile error = %v, want *LoadError", err)
+	}
+	if cfg == nil || len(cfg.Profiles) != 1 {
+		t.Fatalf("LoadFile returned %+v, want the default config", cfg)
+	}
+
+	backups, err := ListBackups(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 || backups[0].Path != loadErr.BackupPath {
+		t.Fatalf("backups = %+v, want one backup at %s", backups, loadErr.BackupPath)
+	}
+	data, err := os.ReadFile(backups[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(broken) {
+		t.Errorf("backup content = %q, want %q", data, broken)
+	}
+}
+
+// withBackupInterval 在测试期间修改备份间隔
+func withBackupInterval(t *testing.T, interval time.Duration) {
+	t.Helper()
+	old := minBackupInterval
+	minBackupInterval = interval
+	t.Cleanup(func() { minBackupInterval = old })
+}
+
+func TestSaveFileRateLimitsBackups(t *testing.T) {
+	withBackupInterval(t, time.Hour)
+	path := filepath.Join(t.TempDir(), "gamepad-key-mapper.json")
+
+	cfg := NewDefault()
+	for i := 0; i < 2*MaxBackups; i++ {
+		cfg.LogLevel = []string{"debug", "info"}[i%2]
+		if err := SaveFile(path, cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := ListBackups(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("%d backups after quick successive saves, want 1", len(backups))
+	}
+}
+
+func TestSaveFileSkipsUnchangedBackup(t *testing.T) {
+	withBackupInterval(t, 0)
+	path := filepath.Join(t.TempDir(), "gamepad-key-mapper.json")
+
+	cfg := NewDefault()
+	for i := 0; i < 3; i++ {
+		if err := SaveFile(path, cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if backups, err := ListBackups(path); err != nil || len(backups) != 0 {
+		t.Fatalf("backups = %v, %v; want none for unchanged content", backups, err)
+	}
+}
+
+func TestRestoreBackupAlwaysBacksUpCurrent(t *testing.T) {
+	withBackupInterval(t, time.Hour)
+	path := filepath.Join(t.TempDir(), "gamepad-key-mapper.json")
+
+	cfg := NewDefault()
+	if err := SaveFile(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfg.AllowExec = true
+	if err := SaveFile(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	backups, err := ListBackups(path)
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("backups = %v, %v; want 1", backups, err)
+	}
+
+	restored, err := RestoreBackup(path, backups[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.AllowExec {
+		t.Error("restored config kept the newer setting")
+	}
+	if backups, err := ListBackups(path); err != nil || len(backups) != 2 {
+		t.Fatalf("backups after restore = %v, %v; want 2", backups, err)
+	}
+}
+
+func TestConcurrentSaves(t *testing.T) {
+	withBackupInterval(t, 0)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gamepad-key-mapper.json")
+
+	const writers = 8
+	const savesPerWriter = 5
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*savesPerWriter)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < savesPerWriter; i++ {
+				cfg := NewDefault()
+				cfg.ActiveProfile = DefaultProfileName
+				cfg.OverlayAddr = fmt.Sprintf("127.0.0.1:%d", 10000+w*100+i)
+				errs <- SaveFile(path, cfg)
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent SaveFile: %v", err)
+		}
+	}
+
+	// 最终文件是某一次完整的保存
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("config unreadable after concurrent saves: %v", err)
+	}
+	if !strings.HasPrefix(cfg.OverlayAddr, "127.0.0.1:") {
+		t.Errorf("overlay addr = %q, want one of the saved values", cfg.OverlayAddr)
+	}
+	if tmp := tempFiles(t, dir); len(tmp) != 0 {
+		t.Errorf("temporary files left behind: %v", tmp)
+	}
+
+	// 每个备份都是完整的配置，数量不超过上限
+	backups, err := ListBackups(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) == 0 || len(backups) > MaxBackups {
+		t.Fatalf("%d backups, want 1..%d", len(backups), MaxBackups)
+	}
+	for _, b := range backups {
+		data, err := os.ReadFile(b.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := DecodeFormat(data, FormatJSON); err != nil {
+			t.Errorf("backup %s is not a complete config: %v", filepath.Base(b.Path), err)
+		}
+	}
+}
+
+func TestUnknownButtonSurvivesSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gamepad-key-mapper.json")
+	legacy := `{"version": 2, "active_profile": "默认", "profiles": [{"name": "默认", "rules": [
+		{"id": "r1", "source_key": 12345, "target_type": 0, "target_keys": [65], "enabled": true}]}]}`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 旧配置中无法识别的按键数值保存后再次读取，配置不应被判定为损坏
+	for i := 0; i < 2; i++ {
+		cfg, err := LoadFile(path)
+		if err != nil {
+			t.Fatalf("load %d: %v", i+1, err)
+		}
+		rules := cfg.Active().Rules
+		if len(rules) != 1 || uint32(rules[0].SourceKey) != 12345 {
+			t.Fatalf("load %d: rules = %+v, want source 12345", i+1, rules)
+		}
+		if err := SaveFile(path, cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
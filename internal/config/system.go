@@ -0,0 +1,49 @@
+package config
+
+import (
+	"gamepad-key-mapper/internal/gamepad"
+)
+
+// SystemAction 系统组合键动作
+type SystemAction int
+
+const (
+	SystemToggleMapping SystemAction = iota // 暂停/恢复映射
+	SystemNextProfile                       // 切换到下一个方案
+	SystemPrevProfile                       // 切换到上一个方案
+	SystemShowWindow                        // 显示主窗口
+)
+
+// String 返回动作名称
+func (a SystemAction) String() string {
+	switch a {
+	case SystemToggleMapping:
+		return "暂停/恢复映射"
+	case SystemNextProfile:
+		return "下一个方案"
+	case SystemPrevProfile:
+		return "上一个方案"
+	case SystemShowWindow:
+		return "显示窗口"
+	default:
+		return "Unknown"
+	}
+}
+
+// SystemBinding 系统组合键（在映射之前处理，触发按键不会传给映射规则）
+type SystemBinding struct {
+	Buttons []gamepad.Button `json:"buttons"` // 需要同时按住的按键
+	HoldMs  int              `json:"hold_ms"` // 需要按住的时长（毫秒）
+	Action  SystemAction     `json:"action"`  // 触发的动作
+}
+
+// DefaultSystemBindings 默认系统组合键
+func DefaultSystemBindings() []SystemBinding {
+	return []SystemBinding{
+		{
+			Buttons: []gamepad.Button{gamepad.ButtonBack, gamepad.ButtonStart},
+			HoldMs:  1000,
+			Action:  SystemToggleMapping,
+		},
+	}
+}
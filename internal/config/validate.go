@@ -0,0 +1,103 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"gamepad-key-mapper/internal/logging"
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// Validate 检查配置内容是否可以应用（手工编辑或外部同步的配置在应用前检查）
+func (c *Config) Validate() error {
+	var errs []error
+
+	names := make(map[string]bool)
+	for i, p := range c.Profiles {
+		if p.Name == "" {
+			errs = append(errs, fmt.Errorf("第 %d 个方案没有名称", i+1))
+		} else if names[p.Name] {
+			errs = append(errs, fmt.Errorf("方案名称重复: %s", p.Name))
+		}
+		names[p.Name] = true
+
+		errs = append(errs, p.validate()...)
+	}
+
+	if c.DefaultProfile != "" && c.Profile(c.DefaultProfile) == nil {
+		errs = append(errs, fmt.Errorf("默认方案 %s 不存在", c.DefaultProfile))
+	}
+
+	for i, b := range c.SystemBindings {
+		if len(b.Buttons) == 0 {
+			errs = append(errs, fmt.Errorf("第 %d 个系统组合键没有按键", i+1))
+		}
+	}
+
+	if _, err := logging.ParseLevel(c.LogLevel); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.OverlayAddr != "" {
+		if _, _, err := net.SplitHostPort(c.OverlayAddr); err != nil {
+			errs = append(errs, fmt.Errorf("无效的叠加层地址 %q（格式如 %s）", c.OverlayAddr, DefaultOverlayAddr))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate 检查方案中的规则
+func (p *Profile) validate() []error {
+	var errs []error
+
+	ids := make(map[string]bool)
+	for i, rule := range p.Rules {
+		if rule == nil {
+			errs = append(errs, fmt.Errorf("方案 %s 的第 %d 条规则为空", p.Name, i+1))
+			continue
+		}
+		if rule.ID != "" && ids[rule.ID] {
+			errs = append(errs, fmt.Errorf("方案 %s 中规则 ID 重复: %s", p.Name, rule.ID))
+		}
+		ids[rule.ID] = true
+
+		if err := validateRule(rule); err != nil {
+			errs = append(errs, fmt.Errorf("方案 %s 的第 %d 条规则: %w", p.Name, i+1, err))
+		}
+	}
+
+	for i, rule := range p.AxisRules {
+		if rule == nil {
+			errs = append(errs, fmt.Errorf("方案 %s 的第 %d 条模拟量规则为空", p.Name, i+1))
+		}
+	}
+
+	for _, m := range p.Match {
+		if err := m.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("方案 %s 的窗口匹配规则无效: %w", p.Name, err))
+		}
+	}
+
+	return errs
+}
+
+// validateRule 检查单条规则的目标是否完整
+func validateRule(rule *mapper.MappingRule) error {
+	switch rule.TargetType {
+	case mapper.TargetKeyboard:
+		if len(rule.TargetKeys) == 0 {
+			return errors.New("没有目标按键")
+		}
+	case mapper.TargetGamepad:
+		if len(rule.TargetButtons) == 0 {
+			return errors.New("没有目标手柄按键")
+		}
+	case mapper.TargetExec:
+		if rule.Exec == nil || rule.Exec.Command == "" {
+			return errors.New("没有要执行的命令")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay 文件变化后等待的时间（编辑器和同步工具常常连续写入多次）
+const reloadDelay = 300 * time.Millisecond
+
+// writtenHashes 本程序最近写入各配置文件的内容摘要（用于忽略自己的保存）
+var writtenHashes = make(map[string][sha256.Size]byte)
+
+// recordWrite 记录本程序写入的配置内容（调用方需持有 saveMu）
+func recordWrite(path string, data []byte) {
+	writtenHashes[filepath.Clean(path)] = sha256.Sum256(data)
+}
+
+// isOwnWrite 检查文件内容是否是本程序最近一次写入的
+func isOwnWrite(path string, sum [sha256.Size]byte) bool {
+	saveMu.Lock()
+	defer saveMu.Unlock()
+	own, ok := writtenHashes[filepath.Clean(path)]
+	return ok && own == sum
+}
+
+// Watcher 配置文件监视器（文件被外部修改时重新加载）
+//
+// 监视的是配置文件所在目录，这样编辑器或同步工具以「写临时文件再重命名」
+// 的方式替换文件时也能收到通知。本程序自己保存的内容不会触发回调。
+type Watcher struct {
+	path     string
+	onChange func(*Config)
+	onError  func(error)
+
+	fsw     *fsnotify.Watcher
+	timer   *time.Timer
+	last    [sha256.Size]byte // 最近一次处理过的内容摘要
+	running bool
+	mu      sync.Mutex
+}
+
+// NewWatcher 创建配置文件监视器
+//
+// 新文件解析并校验通过后调用 onChange；解析或校验失败时调用 onError，不应用新配置。
+func NewWatcher(path string, onChange func(*Config), onError func(error)) *Watcher {
+	return &Watcher{
+		path:     filepath.Clean(path),
+		onChange: onChange,
+		onError:  onError,
+	}
+}
+
+// Start 开始监视
+func (w *Watcher) Start() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.running {
+		return nil
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("无法监视配置文件: %w", err)
+	}
+	if err := fsw.Add(filepath.Dir(w.path)); err != nil {
+		fsw.Close()
+		return fmt.Errorf("无法监视配置文件: %w", err)
+	}
+
+	// 记录当前内容，启动前已加载的配置不会被重复应用
+	if data, err := os.ReadFile(w.path); err == nil {
+		w.last = sha256.Sum256(data)
+	}
+
+	w.fsw = fsw
+	w.running = true
+	go w.eventLoop(fsw)
+	return nil
+}
+
+// Stop 停止监视
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.running {
+		return
+	}
+
+	w.fsw.Close()
+	w.fsw = nil
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.running = false
+}
+
+// IsRunning 检查是否正在运行
+func (w *Watcher) IsRunning() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.running
+}
+
+// Path 返回监视的配置文件路径
+func (w *Watcher) Path() string {
+	return w.path
+}
+
+// eventLoop 处理文件系统事件（Close 后两个通道都会关闭）
+func (w *Watcher) eventLoop(fsw *fsnotify.Watcher) {
+	for {
+		select {
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != w.path {
+				continue
+			}
+			if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Rename) {
+				w.schedule()
+			}
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return
+			}
+			w.onError(fmt.Errorf("监视配置文件出错: %w", err))
+		}
+	}
+}
+
+// schedule 重新开始防抖计时
+func (w *Watcher) schedule() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.running {
+		return
+	}
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(reloadDelay, w.reload)
+}
+
+// reload 读取并校验配置文件，内容确实变化时回调
+func (w *Watcher) reload() {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		// 文件被删除或正在被替换，等待下一次事件
+		return
+	}
+	sum := sha256.Sum256(data)
+
+	w.mu.Lock()
+	if !w.running || sum == w.last {
+		w.mu.Unlock()
+		return
+	}
+	w.last = sum
+	w.mu.Unlock()
+
+	if isOwnWrite(w.path, sum) {
+		return
+	}
+
+	cfg, err := DecodeFormat(data, FormatFromPath(w.path))
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		w.onError(fmt.Errorf("配置文件 %s 已修改但无法应用，继续使用当前配置: %w", filepath.Base(w.path), err))
+		return
+	}
+
+	slog.Info("config file changed externally, reloading", "path", w.path)
+	w.onChange(cfg)
+}
@@ -0,0 +1,76 @@
+package gamepad
+
+// Axis 表示手柄模拟轴
+type Axis int
+
+// 模拟轴常量
+const (
+	AxisLeftX        Axis = iota // 左摇杆X轴（右为正）
+	AxisLeftY                    // 左摇杆Y轴（上为正）
+	AxisRightX                   // 右摇杆X轴（右为正）
+	AxisRightY                   // 右摇杆Y轴（上为正）
+	AxisLeftTrigger              // 左扳机
+	AxisRightTrigger             // 右扳机
+)
+
+// String 返回轴名称
+func (a Axis) String() string {
+	switch a {
+	case AxisLeftX:
+		return "左摇杆 X"
+	case AxisLeftY:
+		return "左摇杆 Y"
+	case AxisRightX:
+		return "右摇杆 X"
+	case AxisRightY:
+		return "右摇杆 Y"
+	case AxisLeftTrigger:
+		return "LT"
+	case AxisRightTrigger:
+		return "RT"
+	default:
+		return "Unknown"
+	}
+}
+
+// AllAxes 返回所有模拟轴
+func AllAxes() []Axis {
+	return []Axis{
+		AxisLeftX, AxisLeftY,
+		AxisRightX, AxisRightY,
+		AxisLeftTrigger, AxisRightTrigger,
+	}
+}
+
+// IsTrigger 检查是否为扳机轴（取值范围 0~1）
+func (a Axis) IsTrigger() bool {
+	return a == AxisLeftTrigger || a == AxisRightTrigger
+}
+
+// Axis 返回指定轴的归一化值（摇杆为 -1~1，扳机为 0~1）
+func (g XInputGamepad) Axis(a Axis) float64 {
+	switch a {
+	case AxisLeftX:
+		return normalizeThumb(g.ThumbLX)
+	case AxisLeftY:
+		return normalizeThumb(g.ThumbLY)
+	case AxisRightX:
+		return normalizeThumb(g.ThumbRX)
+	case AxisRightY:
+		return normalizeThumb(g.ThumbRY)
+	case AxisLeftTrigger:
+		return float64(g.LeftTrigger) / 255
+	case AxisRightTrigger:
+		return float64(g.RightTrigger) / 255
+	default:
+		return 0
+	}
+}
+
+// normalizeThumb 将摇杆原始值转换为 -1~1
+func normalizeThumb(v int16) float64 {
+	if v < -32767 {
+		return -1 // -32768 比 32767 多一格，统一截断保证对称
+	}
+	return float64(v) / 32767
+}
@@ -2,6 +2,7 @@ package gamepad
 
 import (
 	"context"
+	"log/slog"
 	"sync"
 	"time"
 )
@@ -13,14 +14,29 @@ type ButtonEvent struct {
 	PlayerID int  // 手柄ID (0-3)
 }
 
+// Snapshot 手柄状态快照（用于界面显示）
+type Snapshot struct {
+	Connected bool          // 手柄是否已连接
+	Gamepad   XInputGamepad // 原始状态（未连接时为零值）
+}
+
+// stateWatcher 状态快照订阅者
+type stateWatcher struct {
+	ch       chan Snapshot
+	interval time.Duration
+	lastSent time.Time
+}
+
 // Listener 手柄事件监听器
 type Listener struct {
 	pollInterval time.Duration
 	controllerID int
 	eventChan    chan ButtonEvent
+	axisChan     chan XInputGamepad
 	prevState    uint16 // 上一次的按键状态
 	prevLT       bool   // 上一次左扳机状态
 	prevRT       bool   // 上一次右扳机状态
+	connected    bool   // 上一次轮询时手柄是否已连接
 
 	// 摇杆方向状态
 	prevLeftStickUp    bool
@@ -35,6 +51,15 @@ type Listener struct {
 	running bool
 	mu      sync.Mutex
 	cancel  context.CancelFunc
+	done    chan struct{} // 轮询协程退出时关闭
+
+	// 原始事件旁路（用于捕获按键），返回 true 表示事件已被处理，不再发送到事件通道
+	tap   func(ButtonEvent) bool
+	tapMu sync.Mutex
+
+	// 状态快照订阅者
+	watchers   map[*stateWatcher]struct{}
+	watchersMu sync.Mutex
 }
 
 // NewListener 创建新的监听器
@@ -43,6 +68,7 @@ func NewListener(controllerID int) *Listener {
 		pollInterval: 10 * time.Millisecond, // 100Hz 轮询
 		controllerID: controllerID,
 		eventChan:    make(chan ButtonEvent, 64),
+		axisChan:     make(chan XInputGamepad, 8),
 	}
 }
 
@@ -51,6 +77,44 @@ func (l *Listener) Events() <-chan ButtonEvent {
 	return l.eventChan
 }
 
+// Axes 返回模拟量状态通道（每次轮询发送一次，消费不及时会丢弃）
+func (l *Listener) Axes() <-chan XInputGamepad {
+	return l.axisChan
+}
+
+// SetTap 设置原始事件旁路（nil 表示取消）
+//
+// 旁路在轮询协程中同步调用，不应阻塞。返回 true 的事件不会发送到 Events 通道。
+func (l *Listener) SetTap(tap func(ButtonEvent) bool) {
+	l.tapMu.Lock()
+	defer l.tapMu.Unlock()
+	l.tap = tap
+}
+
+// WatchState 订阅手柄状态快照，每 interval 最多发送一次（消费不及时会丢弃）
+//
+// 只在监听运行时发送。返回的函数用于取消订阅，取消后通道会被关闭。
+func (l *Listener) WatchState(interval time.Duration) (<-chan Snapshot, func()) {
+	w := &stateWatcher{ch: make(chan Snapshot, 1), interval: interval}
+
+	l.watchersMu.Lock()
+	if l.watchers == nil {
+		l.watchers = make(map[*stateWatcher]struct{})
+	}
+	l.watchers[w] = struct{}{}
+	l.watchersMu.Unlock()
+
+	var once sync.Once
+	return w.ch, func() {
+		once.Do(func() {
+			l.watchersMu.Lock()
+			delete(l.watchers, w)
+			l.watchersMu.Unlock()
+			close(w.ch)
+		})
+	}
+}
+
 // Start 开始监听
 func (l *Listener) Start() error {
 	l.mu.Lock()
@@ -64,14 +128,22 @@ func (l *Listener) Start() error {
 		l.mu.Unlock()
 		return err
 	}
+	l.startLocked()
+	l.mu.Unlock()
+	return nil
+}
 
+// startLocked 重置状态并启动轮询协程（调用方需持有锁）
+func (l *Listener) startLocked() {
 	// 重新创建事件通道（因为可能已被关闭）
 	l.eventChan = make(chan ButtonEvent, 64)
-	
+	l.axisChan = make(chan XInputGamepad, 8)
+
 	// 重置状态
 	l.prevState = 0
 	l.prevLT = false
 	l.prevRT = false
+	l.connected = false
 	l.prevLeftStickUp = false
 	l.prevLeftStickDown = false
 	l.prevLeftStickLeft = false
@@ -83,14 +155,16 @@ func (l *Listener) Start() error {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	l.cancel = cancel
+	l.done = make(chan struct{})
 	l.running = true
-	l.mu.Unlock()
 
-	go l.pollLoop(ctx)
-	return nil
+	go l.pollLoop(ctx, l.done)
 }
 
-// Stop 停止监听
+// Stop 停止监听，等待轮询协程退出后返回
+//
+// 返回后事件通道已关闭，紧接着调用 Start 不会与旧的轮询协程同时运行。
+// 不能在旁路（SetTap）等轮询协程内部的回调中调用。
 func (l *Listener) Stop() {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -102,6 +176,7 @@ func (l *Listener) Stop() {
 	if l.cancel != nil {
 		l.cancel()
 	}
+	<-l.done
 	l.running = false
 }
 
@@ -113,10 +188,12 @@ func (l *Listener) IsRunning() bool {
 }
 
 // pollLoop 轮询循环
-func (l *Listener) pollLoop(ctx context.Context) {
+func (l *Listener) pollLoop(ctx context.Context, done chan struct{}) {
+	defer close(done)
 	ticker := time.NewTicker(l.pollInterval)
 	defer ticker.Stop()
 	defer close(l.eventChan) // 停止时关闭通道
+	defer close(l.axisChan)
 
 	for {
 		select {
@@ -132,9 +209,19 @@ func (l *Listener) pollLoop(ctx context.Context) {
 func (l *Listener) poll() {
 	state, err := GetState(l.controllerID)
 	if err != nil {
-		// 手柄可能未连接，静默忽略
+		// 手柄可能未连接，只在断开时记录一次
+		if l.connected {
+			l.connected = false
+			slog.Info("controller disconnected", "controller", l.controllerID, "err", err)
+		}
+		l.publishState(Snapshot{})
 		return
 	}
+	if !l.connected {
+		l.connected = true
+		slog.Info("controller connected", "controller", l.controllerID)
+	}
+	l.publishState(Snapshot{Connected: true, Gamepad: state.Gamepad})
 
 	// 检测普通按键变化
 	l.pollButtons(state)
@@ -144,6 +231,9 @@ func (l *Listener) poll() {
 
 	// 检测摇杆方向变化
 	l.pollSticks(state)
+
+	// 发送模拟量状态
+	l.sendAxes(state.Gamepad)
 }
 
 // pollButtons 检测普通按键变化
@@ -246,6 +336,13 @@ func (l *Listener) sendEvent(button Button, pressed bool) {
 		PlayerID: l.controllerID,
 	}
 
+	l.tapMu.Lock()
+	tap := l.tap
+	l.tapMu.Unlock()
+	if tap != nil && tap(event) {
+		return
+	}
+
 	// 非阻塞发送
 	select {
 	case l.eventChan <- event:
@@ -253,3 +350,32 @@ func (l *Listener) sendEvent(button Button, pressed bool) {
 		// 通道满了，丢弃事件
 	}
 }
+
+// publishState 按各订阅者的频率发送状态快照
+func (l *Listener) publishState(snapshot Snapshot) {
+	l.watchersMu.Lock()
+	defer l.watchersMu.Unlock()
+
+	now := time.Now()
+	for w := range l.watchers {
+		if now.Sub(w.lastSent) < w.interval {
+			continue
+		}
+		select {
+		case w.ch <- snapshot:
+			w.lastSent = now
+		default:
+			// 界面还没取走上一次的快照，丢弃本次
+		}
+	}
+}
+
+// sendAxes 发送模拟量状态到通道
+func (l *Listener) sendAxes(gp XInputGamepad) {
+	// 非阻塞发送
+	select {
+	case l.axisChan <- gp:
+	default:
+		// 通道满了，丢弃本次状态
+	}
+}
@@ -0,0 +1,48 @@
+package gamepad
+
+import (
+	"testing"
+	"time"
+)
+
+// startForTest 跳过 XInput 加载启动轮询（非 Windows 平台上 GetState 总是失败，轮询只发布未连接状态）
+func startForTest(l *Listener) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.startLocked()
+}
+
+func TestListenerStopWaitsForPollLoop(t *testing.T) {
+	l := NewListener(0)
+	l.pollInterval = time.Millisecond
+
+	for i := 0; i < 3; i++ {
+		startForTest(l)
+		events, axes := l.Events(), l.Axes()
+		time.Sleep(5 * time.Millisecond)
+
+		l.Stop()
+		if l.IsRunning() {
+			t.Fatal("IsRunning after Stop = true")
+		}
+		// Stop 返回时旧的轮询协程已经退出并关闭了通道
+		select {
+		case _, ok := <-events:
+			if ok {
+				t.Fatal("received event from stopped listener")
+			}
+		default:
+			t.Fatal("event channel still open after Stop returned")
+		}
+		select {
+		case _, ok := <-axes:
+			if ok {
+				t.Fatal("received axes from stopped listener")
+			}
+		default:
+			t.Fatal("axis channel still open after Stop returned")
+		}
+	}
+
+	l.Stop() // 未运行时直接返回
+}
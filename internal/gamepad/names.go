@@ -0,0 +1,165 @@
+package gamepad
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// buttonNames 按键的规范名称（用于配置文件和命令行）
+var buttonNames = map[Button]string{
+	ButtonA:               "A",
+	ButtonB:               "B",
+	ButtonX:               "X",
+	ButtonY:               "Y",
+	ButtonLB:              "LB",
+	ButtonRB:              "RB",
+	ButtonLT:              "LT",
+	ButtonRT:              "RT",
+	ButtonStart:           "Menu",
+	ButtonBack:            "View",
+	ButtonXbox:            "Xbox",
+	ButtonShare:           "Share",
+	ButtonLeftThumb:       "LS",
+	ButtonRightThumb:      "RS",
+	ButtonDPadUp:          "DPadUp",
+	ButtonDPadDown:        "DPadDown",
+	ButtonDPadLeft:        "DPadLeft",
+	ButtonDPadRight:       "DPadRight",
+	ButtonPaddle1:         "P1",
+	ButtonPaddle2:         "P2",
+	ButtonPaddle3:         "P3",
+	ButtonPaddle4:         "P4",
+	ButtonLeftStickUp:     "LeftStickUp",
+	ButtonLeftStickDown:   "LeftStickDown",
+	ButtonLeftStickLeft:   "LeftStickLeft",
+	ButtonLeftStickRight:  "LeftStickRight",
+	ButtonRightStickUp:    "RightStickUp",
+	ButtonRightStickDown:  "RightStickDown",
+	ButtonRightStickLeft:  "RightStickLeft",
+	ButtonRightStickRight: "RightStickRight",
+}
+
+// buttonAliases 按键别名（键为规范化后的小写形式）
+var buttonAliases = map[string]Button{
+	"start":           ButtonStart,
+	"back":            ButtonBack,
+	"select":          ButtonBack,
+	"guide":           ButtonXbox,
+	"home":            ButtonXbox,
+	"leftbumper":      ButtonLB,
+	"rightbumper":     ButtonRB,
+	"leftshoulder":    ButtonLB,
+	"rightshoulder":   ButtonRB,
+	"l1":              ButtonLB,
+	"r1":              ButtonRB,
+	"lefttrigger":     ButtonLT,
+	"righttrigger":    ButtonRT,
+	"l2":              ButtonLT,
+	"r2":              ButtonRT,
+	"leftthumb":       ButtonLeftThumb,
+	"rightthumb":      ButtonRightThumb,
+	"leftstick":       ButtonLeftThumb,
+	"rightstick":      ButtonRightThumb,
+	"lsb":             ButtonLeftThumb,
+	"rsb":             ButtonRightThumb,
+	"l3":              ButtonLeftThumb,
+	"r3":              ButtonRightThumb,
+	"up":              ButtonDPadUp,
+	"down":            ButtonDPadDown,
+	"left":            ButtonDPadLeft,
+	"right":           ButtonDPadRight,
+	"dpup":            ButtonDPadUp,
+	"dpdown":          ButtonDPadDown,
+	"dpleft":          ButtonDPadLeft,
+	"dpright":         ButtonDPadRight,
+	"paddle1":         ButtonPaddle1,
+	"paddle2":         ButtonPaddle2,
+	"paddle3":         ButtonPaddle3,
+	"paddle4":         ButtonPaddle4,
+	"lsup":            ButtonLeftStickUp,
+	"lsdown":          ButtonLeftStickDown,
+	"lsleft":          ButtonLeftStickLeft,
+	"lsright":         ButtonLeftStickRight,
+	"rsup":            ButtonRightStickUp,
+	"rsdown":          ButtonRightStickDown,
+	"rsleft":          ButtonRightStickLeft,
+	"rsright":         ButtonRightStickRight,
+	"leftstickup":     ButtonLeftStickUp,
+	"leftstickdown":   ButtonLeftStickDown,
+	"leftstickleft":   ButtonLeftStickLeft,
+	"leftstickright":  ButtonLeftStickRight,
+	"rightstickup":    ButtonRightStickUp,
+	"rightstickdown":  ButtonRightStickDown,
+	"rightstickleft":  ButtonRightStickLeft,
+	"rightstickright": ButtonRightStickRight,
+}
+
+// Name 返回按键的规范名称（未知按键返回十六进制值）
+func (b Button) Name() string {
+	if name, ok := buttonNames[b]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%X", uint32(b))
+}
+
+// ParseButton 解析按键名称（忽略大小写、空格、下划线和连字符，支持别名和数值）
+func ParseButton(s string) (Button, error) {
+	key := normalizeName(s)
+	if key == "" {
+		return 0, fmt.Errorf("empty button name")
+	}
+
+	for btn, name := range buttonNames {
+		if strings.ToLower(name) == key {
+			return btn, nil
+		}
+	}
+	if btn, ok := buttonAliases[key]; ok {
+		return btn, nil
+	}
+
+	// 数值形式（旧配置或未知按键）；未知按键也保留原值，与 Name 的输出互为逆操作
+	if n, err := strconv.ParseUint(strings.TrimSpace(s), 0, 32); err == nil {
+		return Button(n), nil
+	}
+
+	return 0, fmt.Errorf("unknown button %q", s)
+}
+
+// MarshalText 以规范名称序列化
+func (b Button) MarshalText() ([]byte, error) {
+	return []byte(b.Name()), nil
+}
+
+// UnmarshalText 从名称或数值反序列化
+func (b *Button) UnmarshalText(text []byte) error {
+	btn, err := ParseButton(string(text))
+	if err != nil {
+		return err
+	}
+	*b = btn
+	return nil
+}
+
+// UnmarshalJSON 同时支持名称字符串和旧版数值格式
+func (b *Button) UnmarshalJSON(data []byte) error {
+	var n uint32
+	if err := json.Unmarshal(data, &n); err == nil {
+		*b = Button(n)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("button must be a name or number: %s", data)
+	}
+	return b.UnmarshalText([]byte(s))
+}
+
+// normalizeName 规范化名称用于比较
+func normalizeName(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(s)
+}
@@ -0,0 +1,72 @@
+package gamepad
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestButtonNameRoundTrip(t *testing.T) {
+	buttons := []Button{0, 0x3039, 0x80000000, 0xFFFFFFFF} // 未知按键
+	for btn := range buttonNames {
+		buttons = append(buttons, btn)
+	}
+
+	for _, btn := range buttons {
+		name := btn.Name()
+		parsed, err := ParseButton(name)
+		if err != nil {
+			t.Errorf("ParseButton(%q) for %#x: %v", name, uint32(btn), err)
+			continue
+		}
+		if parsed != btn || parsed.Name() != name {
+			t.Errorf("ParseButton(%q) = %#x (%s), want %#x", name, uint32(parsed), parsed.Name(), uint32(btn))
+		}
+	}
+}
+
+func TestParseButton(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Button
+		wantErr bool
+	}{
+		{"A", ButtonA, false},
+		{" menu ", ButtonStart, false},
+		{"Start", ButtonStart, false},
+		{"left_stick-up", ButtonLeftStickUp, false},
+		{"L1", ButtonLB, false},
+		{"0x1000", ButtonA, false}, // 旧配置的数值
+		{"4096", ButtonA, false},   // 十进制数值
+		{"0x3039", 0x3039, false},  // 未知按键保留原值
+		{"0x100000000", 0, true},   // 超出范围
+		{"", 0, true},
+		{"Turbo", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseButton(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseButton(%q) = %#x, %v; want %#x, error %v", tt.input, uint32(got), err, uint32(tt.want), tt.wantErr)
+		}
+	}
+}
+
+func TestButtonJSONRoundTrip(t *testing.T) {
+	// 旧配置中的数值（包括未知按键）保存为名称后仍能读回
+	for _, legacy := range []string{`4096`, `12345`} {
+		var btn Button
+		if err := json.Unmarshal([]byte(legacy), &btn); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", legacy, err)
+		}
+		data, err := json.Marshal(btn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var again Button
+		if err := json.Unmarshal(data, &again); err != nil {
+			t.Fatalf("Unmarshal(%s) after saving %s: %v", data, legacy, err)
+		}
+		if again != btn {
+			t.Errorf("%s -> %s -> %#x, want %#x", legacy, data, uint32(again), uint32(btn))
+		}
+	}
+}
@@ -4,6 +4,7 @@ package gamepad
 
 import (
 	"errors"
+	"log/slog"
 	"sync"
 	"syscall"
 	"unsafe"
@@ -34,11 +35,13 @@ func LoadXInput() error {
 				procGetState, err = dll.FindProc("XInputGetState")
 				if err == nil {
 					xinputLoaded = true
+					slog.Info("xinput loaded", "dll", name)
 					return
 				}
 			}
 		}
 		xinputLoadError = errors.New("failed to load xinput dll")
+		slog.Error("xinput not available", "tried", dllNames)
 	})
 
 	return xinputLoadError
@@ -0,0 +1,168 @@
+package ipc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// 客户端超时
+const (
+	dialTimeout = time.Second     // 连接超时
+	callTimeout = 5 * time.Second // 单个请求超时
+)
+
+// ErrNotRunning 没有运行中的实例在监听控制接口
+var ErrNotRunning = errors.New("没有运行中的实例")
+
+// Client 控制接口客户端（可在多个协程中使用，请求按顺序发送）
+type Client struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	enc    *json.Encoder
+	dec    *json.Decoder
+	nextID int
+}
+
+// Dial 连接 addr 上的实例，没有实例在监听时返回的错误包含 ErrNotRunning
+func Dial(addr string) (*Client, error) {
+	conn, err := dial(addr, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotRunning, err)
+	}
+	enc := json.NewEncoder(conn)
+	enc.SetEscapeHTML(false)
+	return &Client{conn: conn, enc: enc, dec: json.NewDecoder(conn)}, nil
+}
+
+// DialDefault 连接使用当前配置文件的实例
+func DialDefault() (*Client, error) {
+	addr, err := DefaultAddress()
+	if err != nil {
+		return nil, err
+	}
+	return Dial(addr)
+}
+
+// Close 断开连接
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Call 调用方法，params 为 nil 时不发送参数；实例返回的错误为 *Error
+func (c *Client) Call(method string, params any, result any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	req := request{JSONRPC: "2.0", ID: json.RawMessage(strconv.Itoa(c.nextID)), Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = data
+	}
+
+	c.conn.SetDeadline(time.Now().Add(callTimeout))
+	defer c.conn.SetDeadline(time.Time{})
+
+	if err := c.enc.Encode(&req); err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	var resp response
+	if err := c.dec.Decode(&resp); err != nil {
+		return fmt.Errorf("读取响应失败: %w", err)
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result != nil {
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("无效的响应: %w", err)
+		}
+	}
+	return nil
+}
+
+// Status 查询实例状态
+func (c *Client) Status() (*Status, error) {
+	return c.callStatus(MethodStatus, nil)
+}
+
+// Start 启动映射
+func (c *Client) Start() (*Status, error) {
+	return c.callStatus(MethodStart, nil)
+}
+
+// Stop 停止映射（释放所有按住的键）
+func (c *Client) Stop() (*Status, error) {
+	return c.callStatus(MethodStop, nil)
+}
+
+// Pause 暂停映射
+func (c *Client) Pause() (*Status, error) {
+	return c.callStatus(MethodPause, nil)
+}
+
+// Resume 继续已暂停的映射
+func (c *Client) Resume() (*Status, error) {
+	return c.callStatus(MethodResume, nil)
+}
+
+// Rules 返回当前方案的规则
+func (c *Client) Rules() (*RuleList, error) {
+	var list RuleList
+	if err := c.Call(MethodRulesList, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// AddRule 向当前方案添加规则（rule.ID 由实例生成），返回添加的规则
+func (c *Client) AddRule(rule *mapper.MappingRule) (*mapper.MappingRule, error) {
+	var added mapper.MappingRule
+	if err := c.Call(MethodRulesAdd, rule, &added); err != nil {
+		return nil, err
+	}
+	return &added, nil
+}
+
+// RemoveRule 删除规则
+func (c *Client) RemoveRule(id string) error {
+	return c.Call(MethodRulesRemove, &RuleParams{ID: id}, nil)
+}
+
+// SetRuleEnabled 启用或停用规则
+func (c *Client) SetRuleEnabled(id string, enabled bool) error {
+	return c.Call(MethodRulesSetEnabled, &RuleParams{ID: id, Enabled: enabled}, nil)
+}
+
+// Profiles 返回所有方案
+func (c *Client) Profiles() (*ProfileList, error) {
+	var list ProfileList
+	if err := c.Call(MethodProfilesList, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// UseProfile 切换当前方案
+func (c *Client) UseProfile(name string) error {
+	return c.Call(MethodProfilesUse, &ProfileParams{Name: name}, nil)
+}
+
+// callStatus 调用返回 Status 的方法
+func (c *Client) callStatus(method string, params any) (*Status, error) {
+	var status Status
+	if err := c.Call(method, params, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
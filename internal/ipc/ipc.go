@@ -0,0 +1,114 @@
+// Package ipc 本地控制接口：运行中的实例通过 Unix 域套接字（Windows 上为命名管道）
+// 接受 JSON-RPC 2.0 请求，供 Stream Deck 插件、脚本和 gkm 命令行控制映射。
+//
+// 每个连接上的请求按顺序处理：客户端发送一个请求（一个 JSON 对象），等待响应后再发送下一个。
+package ipc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gamepad-key-mapper/internal/config"
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// 方法名称
+const (
+	MethodStatus          = "status"            // 查询状态，结果为 Status
+	MethodStart           = "start"             // 启动映射，结果为 Status
+	MethodStop            = "stop"              // 停止映射，结果为 Status
+	MethodPause           = "pause"             // 暂停映射，结果为 Status
+	MethodResume          = "resume"            // 继续映射，结果为 Status
+	MethodRulesList       = "rules.list"        // 当前方案的规则，结果为 RuleList
+	MethodRulesAdd        = "rules.add"         // 添加规则，参数为 mapper.MappingRule（忽略 id），结果为添加的规则
+	MethodRulesRemove     = "rules.remove"      // 删除规则，参数为 RuleParams
+	MethodRulesSetEnabled = "rules.set_enabled" // 启用或停用规则，参数为 RuleParams
+	MethodProfilesList    = "profiles.list"     // 所有方案，结果为 ProfileList
+	MethodProfilesUse     = "profiles.use"      // 切换方案，参数为 ProfileParams，结果为 Status
+)
+
+// 映射状态名称
+const (
+	StateStopped = "stopped"
+	StateRunning = "running"
+	StatePaused  = "paused"
+)
+
+// Status 实例状态
+type Status struct {
+	State       string `json:"state"`         // stopped、running 或 paused
+	Profile     string `json:"profile"`       // 当前方案
+	Rules       int    `json:"rules"`         // 当前方案的规则数量
+	ExecAllowed bool   `json:"exec_allowed"`  // 是否允许执行命令规则
+	PID         int    `json:"pid,omitempty"` // 实例的进程ID（没有运行中的实例时为 0）
+}
+
+// RuleList 当前方案的规则
+type RuleList struct {
+	Profile string                `json:"profile"`
+	Rules   []*mapper.MappingRule `json:"rules"`
+}
+
+// ProfileList 所有方案
+type ProfileList struct {
+	Active   string   `json:"active"`
+	Profiles []string `json:"profiles"`
+}
+
+// RuleParams 按ID操作规则的参数
+type RuleParams struct {
+	ID      string `json:"id"`
+	Enabled bool   `json:"enabled,omitempty"` // 仅 rules.set_enabled
+}
+
+// ProfileParams 切换方案的参数
+type ProfileParams struct {
+	Name string `json:"name"`
+}
+
+// JSON-RPC 错误码
+const (
+	CodeParseError     = -32700 // 请求不是有效的 JSON
+	CodeInvalidRequest = -32600 // 请求格式错误
+	CodeMethodNotFound = -32601 // 未知方法
+	CodeInvalidParams  = -32602 // 参数错误
+	CodeFailed         = -32000 // 操作失败（如规则不存在）
+)
+
+// Error 控制接口返回的错误
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error 返回错误描述
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// request JSON-RPC 请求
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response JSON-RPC 响应
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// DefaultAddress 返回当前配置文件对应的控制接口地址
+//
+// 地址由配置文件路径决定，使用不同配置文件的实例互不干扰。
+func DefaultAddress() (string, error) {
+	path, err := config.GetConfigPath()
+	if err != nil {
+		return "", fmt.Errorf("无法确定控制接口地址: %w", err)
+	}
+	return addressFor(path), nil
+}
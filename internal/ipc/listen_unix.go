@@ -0,0 +1,52 @@
+//go:build !windows
+
+package ipc
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// socketName 控制接口套接字文件名（位于配置文件所在目录）
+const socketName = "gamepad-key-mapper.sock"
+
+// addressFor 返回配置文件对应的套接字路径
+func addressFor(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), socketName)
+}
+
+// listen 在 Unix 域套接字上监听（只允许当前用户连接）
+//
+// 上次异常退出留下的套接字文件会被删除；仍有实例在监听时返回错误。
+func listen(addr string) (net.Listener, error) {
+	if _, err := os.Stat(addr); err == nil {
+		if conn, err := net.DialTimeout("unix", addr, dialTimeout); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("已有实例在 %s 监听", addr)
+		}
+		if err := os.Remove(addr); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	l, err := net.Listen("unix", addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(addr, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// dial 连接 Unix 域套接字
+func dial(addr string, timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout("unix", addr, timeout)
+}
@@ -0,0 +1,476 @@
+//go:build windows
+
+package ipc
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	kernel32                = syscall.NewLazyDLL("kernel32.dll")
+	procCreateNamedPipeW    = kernel32.NewProc("CreateNamedPipeW")
+	procConnectNamedPipe    = kernel32.NewProc("ConnectNamedPipe")
+	procDisconnectNamedPipe = kernel32.NewProc("DisconnectNamedPipe")
+	procWaitNamedPipeW      = kernel32.NewProc("WaitNamedPipeW")
+	procCreateEventW        = kernel32.NewProc("CreateEventW")
+	procSetEvent            = kernel32.NewProc("SetEvent")
+	procWaitForMultiple     = kernel32.NewProc("WaitForMultipleObjects")
+	procGetOverlappedResult = kernel32.NewProc("GetOverlappedResult")
+)
+
+// 命名管道参数
+const (
+	PIPE_ACCESS_DUPLEX            = 0x00000003
+	FILE_FLAG_FIRST_PIPE_INSTANCE = 0x00080000
+	PIPE_TYPE_BYTE                = 0x00000000
+	PIPE_READMODE_BYTE            = 0x00000000
+	PIPE_WAIT                     = 0x00000000
+	PIPE_REJECT_REMOTE_CLIENTS    = 0x00000008
+	PIPE_UNLIMITED_INSTANCES      = 255
+	SECURITY_SQOS_PRESENT         = 0x00100000
+	SECURITY_IDENTIFICATION       = 0x00010000
+)
+
+// 命名管道错误码
+const (
+	ERROR_PIPE_BUSY          syscall.Errno = 231
+	ERROR_NO_DATA            syscall.Errno = 232
+	ERROR_PIPE_NOT_CONNECTED syscall.Errno = 233
+	ERROR_PIPE_CONNECTED     syscall.Errno = 535
+)
+
+// pipeBufferSize 管道缓冲区大小
+const pipeBufferSize = 64 * 1024
+
+// addressFor 返回配置文件对应的命名管道名称
+func addressFor(configPath string) string {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(configPath)))
+	return fmt.Sprintf(`\\.\pipe\gamepad-key-mapper-%08x`, h.Sum32())
+}
+
+// createPipe 创建一个命名管道实例（first 为 true 时同名管道已存在则失败）
+func createPipe(name string, first bool) (syscall.Handle, error) {
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return syscall.InvalidHandle, err
+	}
+
+	openMode := uintptr(PIPE_ACCESS_DUPLEX | syscall.FILE_FLAG_OVERLAPPED)
+	if first {
+		openMode |= FILE_FLAG_FIRST_PIPE_INSTANCE
+	}
+	h, _, callErr := procCreateNamedPipeW.Call(
+		uintptr(unsafe.Pointer(namePtr)),
+		openMode,
+		PIPE_TYPE_BYTE|PIPE_READMODE_BYTE|PIPE_WAIT|PIPE_REJECT_REMOTE_CLIENTS,
+		PIPE_UNLIMITED_INSTANCES,
+		pipeBufferSize,
+		pipeBufferSize,
+		0,
+		0, // 默认安全描述符：只有创建者（及管理员、系统）可以写入
+	)
+	if syscall.Handle(h) == syscall.InvalidHandle {
+		return syscall.InvalidHandle, callErr
+	}
+	return syscall.Handle(h), nil
+}
+
+// newEvent 创建手动重置的事件（重叠 I/O 要求手动重置）
+func newEvent() (syscall.Handle, error) {
+	h, _, callErr := procCreateEventW.Call(0, 1, 0, 0)
+	if h == 0 {
+		return 0, callErr
+	}
+	return syscall.Handle(h), nil
+}
+
+// newEvents 创建多个事件，失败时关闭已创建的事件
+func newEvents(events ...*syscall.Handle) error {
+	for i, ev := range events {
+		h, err := newEvent()
+		if err != nil {
+			for _, created := range events[:i] {
+				syscall.CloseHandle(*created)
+			}
+			return err
+		}
+		*ev = h
+	}
+	return nil
+}
+
+// setEvent 触发事件
+func setEvent(h syscall.Handle) {
+	procSetEvent.Call(uintptr(h))
+}
+
+// waitIO 等待重叠操作完成，返回传输的字节数
+//
+// cancel 事件触发（连接或监听器关闭）时取消操作并返回 net.ErrClosed，
+// 超过 deadline（零值表示不超时）时取消操作并返回 os.ErrDeadlineExceeded。
+func waitIO(h syscall.Handle, ov *syscall.Overlapped, cancel syscall.Handle, deadline time.Time) (uint32, error) {
+	timeout := uint32(syscall.INFINITE)
+	if !deadline.IsZero() {
+		timeout = 0
+		if remaining := time.Until(deadline); remaining > 0 {
+			timeout = uint32((remaining + time.Millisecond - 1) / time.Millisecond)
+		}
+	}
+
+	handles := [2]syscall.Handle{ov.HEvent, cancel}
+	r, _, callErr := procWaitForMultiple.Call(2, uintptr(unsafe.Pointer(&handles[0])), 0, uintptr(timeout))
+
+	var cause error
+	switch uint32(r) {
+	case syscall.WAIT_OBJECT_0:
+	case syscall.WAIT_OBJECT_0 + 1:
+		cause = net.ErrClosed
+	case syscall.WAIT_TIMEOUT:
+		cause = os.ErrDeadlineExceeded
+	default:
+		cause = callErr
+	}
+	if cause != nil {
+		syscall.CancelIoEx(h, ov)
+	}
+
+	// 取消后仍需等待操作结束，内核才不再使用 ov 和缓冲区
+	var n uint32
+	ok, _, callErr := procGetOverlappedResult.Call(uintptr(h), uintptr(unsafe.Pointer(ov)), uintptr(unsafe.Pointer(&n)), 1)
+	if ok == 0 {
+		if cause != nil && errors.Is(callErr, syscall.ERROR_OPERATION_ABORTED) {
+			return n, cause
+		}
+		return n, callErr
+	}
+	return n, nil // 取消前操作已完成
+}
+
+// pipeListener 命名管道监听器
+//
+// 始终保留一个等待连接的管道实例，客户端不会因为两次 Accept 之间管道不存在而连接失败。
+// 管道以重叠 I/O 方式打开，Close 可以取消等待中的 Accept。
+type pipeListener struct {
+	name string
+
+	acceptMu sync.Mutex         // Accept 依次进行
+	next     syscall.Handle     // 等待连接的管道实例
+	ov       syscall.Overlapped // ConnectNamedPipe 使用（放在堆上，等待期间地址不变）
+
+	mu      sync.Mutex
+	closed  bool
+	closing syscall.Handle // Close 时触发，取消等待中的 Accept
+	ops     sync.WaitGroup // 进行中的 Accept
+}
+
+// listen 创建命名管道；已有实例使用同名管道时返回错误
+func listen(addr string) (net.Listener, error) {
+	h, err := createPipe(addr, true)
+	if err != nil {
+		if errors.Is(err, syscall.ERROR_ACCESS_DENIED) {
+			return nil, fmt.Errorf("已有实例在 %s 监听", addr)
+		}
+		return nil, err
+	}
+	l := &pipeListener{name: addr, next: h}
+	if err := newEvents(&l.ov.HEvent, &l.closing); err != nil {
+		syscall.CloseHandle(h)
+		return nil, err
+	}
+	return l, nil
+}
+
+// Accept 等待客户端连接
+func (l *pipeListener) Accept() (net.Conn, error) {
+	l.acceptMu.Lock()
+	defer l.acceptMu.Unlock()
+
+	for {
+		conn, retry, err := l.acceptOnce()
+		if !retry {
+			return conn, err
+		}
+	}
+}
+
+// acceptOnce 等待一次连接；客户端连接后立即断开时返回 retry=true
+func (l *pipeListener) acceptOnce() (conn net.Conn, retry bool, err error) {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil, false, net.ErrClosed
+	}
+	l.ops.Add(1)
+	l.mu.Unlock()
+	defer l.ops.Done()
+
+	h := l.next
+	l.ov = syscall.Overlapped{HEvent: l.ov.HEvent}
+	ret, _, callErr := procConnectNamedPipe.Call(uintptr(h), uintptr(unsafe.Pointer(&l.ov)))
+	if ret == 0 {
+		switch {
+		case errors.Is(callErr, ERROR_PIPE_CONNECTED):
+			// 客户端在 ConnectNamedPipe 之前已连接
+		case errors.Is(callErr, syscall.ERROR_IO_PENDING):
+			if _, err := waitIO(h, &l.ov, l.closing, time.Time{}); err != nil {
+				if errors.Is(err, ERROR_NO_DATA) {
+					procDisconnectNamedPipe.Call(uintptr(h))
+					return nil, true, nil
+				}
+				return nil, false, err
+			}
+		case errors.Is(callErr, ERROR_NO_DATA):
+			// 客户端连接后立即断开，重置管道实例后继续等待
+			procDisconnectNamedPipe.Call(uintptr(h))
+			return nil, true, nil
+		default:
+			return nil, false, callErr
+		}
+	}
+
+	next, err := createPipe(l.name, false)
+	if err != nil {
+		return nil, false, err
+	}
+	pc, err := newPipeConn(h, l.name, true)
+	if err != nil {
+		syscall.CloseHandle(next)
+		return nil, false, err
+	}
+	l.next = next
+	return pc, false, nil
+}
+
+// Close 停止监听（不影响已建立的连接）
+func (l *pipeListener) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.mu.Unlock()
+
+	setEvent(l.closing)
+	l.ops.Wait()
+
+	err := syscall.CloseHandle(l.next)
+	syscall.CloseHandle(l.ov.HEvent)
+	syscall.CloseHandle(l.closing)
+	return err
+}
+
+// Addr 返回管道名称
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr(l.name)
+}
+
+// dial 连接命名管道，所有实例都忙时等待至超时
+func dial(addr string, timeout time.Duration) (net.Conn, error) {
+	namePtr, err := syscall.UTF16PtrFromString(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		// 只允许服务端识别客户端身份，不允许模拟客户端
+		h, err := syscall.CreateFile(namePtr,
+			syscall.GENERIC_READ|syscall.GENERIC_WRITE,
+			0, nil, syscall.OPEN_EXISTING,
+			SECURITY_SQOS_PRESENT|SECURITY_IDENTIFICATION|syscall.FILE_FLAG_OVERLAPPED, 0)
+		if err == nil {
+			conn, err := newPipeConn(h, addr, false)
+			if err != nil {
+				syscall.CloseHandle(h)
+				return nil, err
+			}
+			return conn, nil
+		}
+		if !errors.Is(err, ERROR_PIPE_BUSY) {
+			return nil, err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, err
+		}
+		procWaitNamedPipeW.Call(uintptr(unsafe.Pointer(namePtr)), uintptr(remaining.Milliseconds()))
+	}
+}
+
+// pipeConn 命名管道连接
+//
+// 管道以重叠 I/O 方式读写：Close 会取消其它协程中阻塞的读写，读写超过截止时间时取消并返回超时错误。
+// 截止时间只对之后开始的读写生效。
+type pipeConn struct {
+	h      syscall.Handle
+	name   string
+	server bool // 服务端的连接，关闭时先断开客户端
+
+	// 读写各自的重叠结构（放在堆上，等待期间地址不变）
+	rov, wov syscall.Overlapped
+
+	mu            sync.Mutex
+	closed        bool
+	closing       syscall.Handle // Close 时触发，取消进行中的读写
+	ops           sync.WaitGroup // 进行中的读写
+	readDeadline  time.Time
+	writeDeadline time.Time
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// newPipeConn 包装管道句柄（句柄需以重叠方式打开）
+func newPipeConn(h syscall.Handle, name string, server bool) (*pipeConn, error) {
+	c := &pipeConn{h: h, name: name, server: server}
+	if err := newEvents(&c.rov.HEvent, &c.wov.HEvent, &c.closing); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// begin 开始一次读写，返回截止时间；连接已关闭或已超时时返回错误
+func (c *pipeConn) begin(write bool) (time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return time.Time{}, net.ErrClosed
+	}
+	deadline := c.readDeadline
+	if write {
+		deadline = c.writeDeadline
+	}
+	if !deadline.IsZero() && !time.Now().Before(deadline) {
+		return time.Time{}, os.ErrDeadlineExceeded
+	}
+	c.ops.Add(1)
+	return deadline, nil
+}
+
+// Read 读取数据，对方关闭管道时返回 io.EOF
+func (c *pipeConn) Read(b []byte) (int, error) {
+	deadline, err := c.begin(false)
+	if err != nil {
+		return 0, err
+	}
+	defer c.ops.Done()
+
+	var n uint32
+	c.rov = syscall.Overlapped{HEvent: c.rov.HEvent}
+	err = syscall.ReadFile(c.h, b, &n, &c.rov)
+	if errors.Is(err, syscall.ERROR_IO_PENDING) {
+		n, err = waitIO(c.h, &c.rov, c.closing, deadline)
+	}
+	if errors.Is(err, syscall.ERROR_BROKEN_PIPE) || errors.Is(err, ERROR_PIPE_NOT_CONNECTED) {
+		return int(n), io.EOF
+	}
+	return int(n), err
+}
+
+// Write 写入数据
+func (c *pipeConn) Write(b []byte) (int, error) {
+	deadline, err := c.begin(true)
+	if err != nil {
+		return 0, err
+	}
+	defer c.ops.Done()
+
+	written := 0
+	for written < len(b) {
+		var n uint32
+		c.wov = syscall.Overlapped{HEvent: c.wov.HEvent}
+		err := syscall.WriteFile(c.h, b[written:], &n, &c.wov)
+		if errors.Is(err, syscall.ERROR_IO_PENDING) {
+			n, err = waitIO(c.h, &c.wov, c.closing, deadline)
+		}
+		written += int(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// Close 关闭连接，其它协程中阻塞的读写返回 net.ErrClosed
+func (c *pipeConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		c.closed = true
+		c.mu.Unlock()
+
+		// 等待进行中的读写取消后再关闭句柄
+		setEvent(c.closing)
+		c.ops.Wait()
+
+		if c.server {
+			procDisconnectNamedPipe.Call(uintptr(c.h))
+		}
+		c.closeErr = syscall.CloseHandle(c.h)
+		syscall.CloseHandle(c.rov.HEvent)
+		syscall.CloseHandle(c.wov.HEvent)
+		syscall.CloseHandle(c.closing)
+	})
+	return c.closeErr
+}
+
+// LocalAddr 返回管道名称
+func (c *pipeConn) LocalAddr() net.Addr {
+	return pipeAddr(c.name)
+}
+
+// RemoteAddr 返回管道名称
+func (c *pipeConn) RemoteAddr() net.Addr {
+	return pipeAddr(c.name)
+}
+
+// SetDeadline 设置读写截止时间
+func (c *pipeConn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readDeadline = t
+	c.writeDeadline = t
+	return nil
+}
+
+// SetReadDeadline 设置读取截止时间
+func (c *pipeConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readDeadline = t
+	return nil
+}
+
+// SetWriteDeadline 设置写入截止时间
+func (c *pipeConn) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writeDeadline = t
+	return nil
+}
+
+// pipeAddr 命名管道地址
+type pipeAddr string
+
+// Network 返回地址类型
+func (a pipeAddr) Network() string {
+	return "pipe"
+}
+
+// String 返回管道名称
+func (a pipeAddr) String() string {
+	return string(a)
+}
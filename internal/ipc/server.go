@@ -0,0 +1,284 @@
+package ipc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+
+	"gamepad-key-mapper/internal/app"
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// Server 控制接口服务端
+type Server struct {
+	app *app.App
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// NewServer 创建控制 a 的服务端
+func NewServer(a *app.App) *Server {
+	return &Server{
+		app:   a,
+		conns: make(map[net.Conn]struct{}),
+	}
+}
+
+// Listen 在 addr 上监听并在后台处理请求
+//
+// 已有其它实例在同一地址监听时返回错误。
+func (s *Server) Listen(addr string) error {
+	l, err := listen(addr)
+	if err != nil {
+		return fmt.Errorf("启动控制接口失败: %w", err)
+	}
+
+	s.mu.Lock()
+	if s.closed || s.listener != nil {
+		s.mu.Unlock()
+		l.Close()
+		return errors.New("控制接口已关闭或已在监听")
+	}
+	s.listener = l
+	s.mu.Unlock()
+
+	slog.Info("control api listening", "addr", addr)
+	s.wg.Add(1)
+	go s.serve(l)
+	return nil
+}
+
+// ListenDefault 在当前配置文件对应的地址上监听
+func (s *Server) ListenDefault() error {
+	addr, err := DefaultAddress()
+	if err != nil {
+		return err
+	}
+	return s.Listen(addr)
+}
+
+// Close 停止监听并断开所有连接
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	l := s.listener
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	var err error
+	if l != nil {
+		err = l.Close()
+	}
+	s.wg.Wait()
+	return err
+}
+
+// serve 接受连接
+func (s *Server) serve(l net.Listener) {
+	defer s.wg.Done()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				slog.Error("control api accept failed", "err", err)
+			}
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.handleConn(conn)
+	}
+}
+
+// handleConn 按顺序处理一个连接上的请求，直到对方关闭连接
+func (s *Server) handleConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+		s.wg.Done()
+	}()
+
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+	enc.SetEscapeHTML(false)
+	for {
+		var req request
+		if err := dec.Decode(&req); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			// 数据格式错误后无法找到下一个请求的开始位置，回复后断开
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				enc.Encode(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &Error{Code: CodeParseError, Message: err.Error()}})
+			} else {
+				slog.Debug("control api connection closed", "err", err)
+			}
+			return
+		}
+
+		resp := s.handle(&req)
+		if req.ID == nil {
+			continue // 通知不需要回复
+		}
+		if err := enc.Encode(resp); err != nil {
+			slog.Debug("control api write failed", "err", err)
+			return
+		}
+	}
+}
+
+// handle 执行一个请求
+func (s *Server) handle(req *request) *response {
+	resp := &response{JSONRPC: "2.0", ID: req.ID}
+
+	var result any
+	var err error
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		err = &Error{Code: CodeInvalidRequest, Message: "无效的请求"}
+	} else {
+		slog.Debug("control request", "method", req.Method)
+		result, err = s.call(req.Method, req.Params)
+	}
+
+	if err == nil {
+		resp.Result, err = json.Marshal(result)
+	}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeFailed, Message: err.Error()}
+		}
+		resp.Result = nil
+		resp.Error = rpcErr
+	}
+	return resp
+}
+
+// call 调用方法
+func (s *Server) call(method string, params json.RawMessage) (any, error) {
+	switch method {
+	case MethodStatus:
+		return s.status(), nil
+
+	case MethodStart:
+		if err := s.app.Start(); err != nil {
+			return nil, err
+		}
+		return s.status(), nil
+
+	case MethodStop:
+		s.app.Stop()
+		return s.status(), nil
+
+	case MethodPause:
+		s.app.Pause()
+		return s.status(), nil
+
+	case MethodResume:
+		s.app.Resume()
+		return s.status(), nil
+
+	case MethodRulesList:
+		return &RuleList{Profile: s.app.ActiveProfile(), Rules: s.app.GetRules()}, nil
+
+	case MethodRulesAdd:
+		var rule mapper.MappingRule
+		if err := decodeParams(params, &rule); err != nil {
+			return nil, err
+		}
+		return s.app.AddMappingRule(&rule)
+
+	case MethodRulesRemove:
+		var p RuleParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if !s.app.RemoveRule(p.ID) {
+			return nil, fmt.Errorf("规则不存在: %s", p.ID)
+		}
+		return true, nil
+
+	case MethodRulesSetEnabled:
+		var p RuleParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if err := s.app.SetRuleEnabled(p.ID, p.Enabled); err != nil {
+			return nil, err
+		}
+		return true, nil
+
+	case MethodProfilesList:
+		return &ProfileList{Active: s.app.ActiveProfile(), Profiles: s.app.ProfileNames()}, nil
+
+	case MethodProfilesUse:
+		var p ProfileParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if err := s.app.ActivateProfile(p.Name); err != nil {
+			return nil, err
+		}
+		return s.status(), nil
+
+	default:
+		return nil, &Error{Code: CodeMethodNotFound, Message: "未知的方法: " + method}
+	}
+}
+
+// status 返回当前状态
+func (s *Server) status() *Status {
+	return &Status{
+		State:       stateName(s.app.GetState()),
+		Profile:     s.app.ActiveProfile(),
+		Rules:       len(s.app.GetRules()),
+		ExecAllowed: s.app.ExecAllowed(),
+		PID:         os.Getpid(),
+	}
+}
+
+// stateName 返回状态的英文名称
+func stateName(state app.State) string {
+	switch state {
+	case app.StateRunning:
+		return StateRunning
+	case app.StatePaused:
+		return StatePaused
+	default:
+		return StateStopped
+	}
+}
+
+// decodeParams 解析请求参数
+func decodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 {
+		return &Error{Code: CodeInvalidParams, Message: "缺少参数"}
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &Error{Code: CodeInvalidParams, Message: "参数错误: " + err.Error()}
+	}
+	return nil
+}
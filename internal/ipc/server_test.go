@@ -0,0 +1,159 @@
+package ipc
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gamepad-key-mapper/internal/app"
+	"gamepad-key-mapper/internal/config"
+)
+
+// testAddress 返回临时配置文件对应的控制接口地址
+func testAddress(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := config.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { config.SetConfigPath("") })
+	return addressFor(path)
+}
+
+// within 在 d 内等待 fn 返回，超时则测试失败
+func within(t *testing.T, d time.Duration, what string, fn func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	select {
+	case <-done:
+	case <-time.After(d):
+		t.Fatalf("%s did not return within %v", what, d)
+	}
+}
+
+func TestServerCloseWithIdleClient(t *testing.T) {
+	addr := testAddress(t)
+	a, err := app.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(a)
+	if err := s.Listen(addr); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := Dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	status, err := client.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != StateStopped {
+		t.Errorf("state = %q, want %q", status.State, StateStopped)
+	}
+
+	// 客户端保持空闲连接时（如 Stream Deck 插件），关闭服务端不应等待客户端断开
+	within(t, 2*time.Second, "Server.Close", func() {
+		if err := s.Close(); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	})
+	if _, err := client.Status(); err == nil {
+		t.Error("call succeeded after server closed")
+	}
+}
+
+func TestListenerCloseUnblocksAccept(t *testing.T) {
+	l, err := listen(testAddress(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accepted := make(chan error, 1)
+	go func() {
+		_, err := l.Accept()
+		accepted <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	within(t, 2*time.Second, "Listener.Close", func() { l.Close() })
+	select {
+	case err := <-accepted:
+		if !errors.Is(err, net.ErrClosed) {
+			t.Errorf("Accept err = %v, want net.ErrClosed", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Accept did not return after Close")
+	}
+}
+
+func TestConnReadDeadlineAndClose(t *testing.T) {
+	addr := testAddress(t)
+	l, err := listen(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	serverConn := make(chan net.Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			serverConn <- conn
+		}
+	}()
+
+	conn, err := dial(addr, dialTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	peer := <-serverConn
+	defer peer.Close()
+
+	// 对方不回复时，读取在截止时间后返回超时错误
+	conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	buf := make([]byte, 16)
+	_, err = conn.Read(buf)
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() || !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("Read err = %v, want deadline exceeded", err)
+	}
+
+	// 超时后连接仍可使用
+	conn.SetReadDeadline(time.Time{})
+	if _, err := peer.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	n, err := conn.Read(buf)
+	if err != nil || string(buf[:n]) != "ping" {
+		t.Fatalf("Read = %q, %v; want ping", buf[:n], err)
+	}
+
+	// 关闭连接时，另一个协程中阻塞的读取返回
+	read := make(chan error, 1)
+	go func() {
+		_, err := peer.Read(buf)
+		read <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	within(t, 2*time.Second, "Conn.Close", func() { peer.Close() })
+	select {
+	case err := <-read:
+		if err == nil {
+			t.Error("blocked Read returned no error after Close")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("blocked Read did not return after Close")
+	}
+}
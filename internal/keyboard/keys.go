@@ -89,6 +89,19 @@ const (
 	KeyNumpad7 KeyCode = 0x67
 	KeyNumpad8 KeyCode = 0x68
 	KeyNumpad9 KeyCode = 0x69
+
+	// 修饰键（作为普通按键使用，如单独按住 Shift 或区分左右）
+	KeyShift  KeyCode = 0x10
+	KeyCtrl   KeyCode = 0x11
+	KeyAlt    KeyCode = 0x12
+	KeyLShift KeyCode = 0xA0
+	KeyRShift KeyCode = 0xA1
+	KeyLCtrl  KeyCode = 0xA2
+	KeyRCtrl  KeyCode = 0xA3
+	KeyLAlt   KeyCode = 0xA4
+	KeyRAlt   KeyCode = 0xA5
+	KeyLWin   KeyCode = 0x5B
+	KeyRWin   KeyCode = 0x5C
 )
 
 // String 返回按键名称
@@ -220,6 +233,48 @@ func (k KeyCode) String() string {
 		return "Left"
 	case KeyRight:
 		return "Right"
+	case KeyNumpad0:
+		return "Numpad0"
+	case KeyNumpad1:
+		return "Numpad1"
+	case KeyNumpad2:
+		return "Numpad2"
+	case KeyNumpad3:
+		return "Numpad3"
+	case KeyNumpad4:
+		return "Numpad4"
+	case KeyNumpad5:
+		return "Numpad5"
+	case KeyNumpad6:
+		return "Numpad6"
+	case KeyNumpad7:
+		return "Numpad7"
+	case KeyNumpad8:
+		return "Numpad8"
+	case KeyNumpad9:
+		return "Numpad9"
+	case KeyShift:
+		return "Shift"
+	case KeyCtrl:
+		return "Ctrl"
+	case KeyAlt:
+		return "Alt"
+	case KeyLShift:
+		return "LShift"
+	case KeyRShift:
+		return "RShift"
+	case KeyLCtrl:
+		return "LCtrl"
+	case KeyRCtrl:
+		return "RCtrl"
+	case KeyLAlt:
+		return "LAlt"
+	case KeyRAlt:
+		return "RAlt"
+	case KeyLWin:
+		return "LWin"
+	case KeyRWin:
+		return "RWin"
 	default:
 		return "Unknown"
 	}
@@ -0,0 +1,291 @@
+package keyboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// namedKeys 所有有规范名称的按键
+var namedKeys = append(AllKeys(),
+	KeyBackspace, KeyDelete, KeyInsert, KeyHome, KeyEnd, KeyPageUp, KeyPageDown,
+	KeyNumpad0, KeyNumpad1, KeyNumpad2, KeyNumpad3, KeyNumpad4,
+	KeyNumpad5, KeyNumpad6, KeyNumpad7, KeyNumpad8, KeyNumpad9,
+	KeyShift, KeyCtrl, KeyAlt,
+	KeyLShift, KeyRShift, KeyLCtrl, KeyRCtrl, KeyLAlt, KeyRAlt, KeyLWin, KeyRWin,
+)
+
+// keyAliases 按键别名（键为规范化后的小写形式）
+var keyAliases = map[string]KeyCode{
+	"esc":        KeyEscape,
+	"return":     KeyEnter,
+	"spacebar":   KeySpace,
+	"bksp":       KeyBackspace,
+	"del":        KeyDelete,
+	"ins":        KeyInsert,
+	"pgup":       KeyPageUp,
+	"pgdn":       KeyPageDown,
+	"pagedn":     KeyPageDown,
+	"arrowup":    KeyUp,
+	"arrowdown":  KeyDown,
+	"arrowleft":  KeyLeft,
+	"arrowright": KeyRight,
+	"control":    KeyCtrl,
+	"leftshift":  KeyLShift,
+	"rightshift": KeyRShift,
+	"leftctrl":   KeyLCtrl,
+	"rightctrl":  KeyRCtrl,
+	"lcontrol":   KeyLCtrl,
+	"rcontrol":   KeyRCtrl,
+	"leftalt":    KeyLAlt,
+	"rightalt":   KeyRAlt,
+	"altgr":      KeyRAlt,
+	"win":        KeyLWin,
+	"leftwin":    KeyLWin,
+	"rightwin":   KeyRWin,
+}
+
+// NamedKeys 返回所有有规范名称的按键
+func NamedKeys() []KeyCode {
+	return append([]KeyCode(nil), namedKeys...)
+}
+
+// Name 返回按键的规范名称（未知按键返回十六进制虚拟键码）
+func (k KeyCode) Name() string {
+	if k.IsNamed() {
+		return k.String()
+	}
+	return fmt.Sprintf("0x%02X", uint32(k))
+}
+
+// IsNamed 检查按键是否有规范名称
+func (k KeyCode) IsNamed() bool {
+	for _, key := range namedKeys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValid 检查按键码是否在 Windows 虚拟键码范围内（0x01-0xFE）
+func (k KeyCode) IsValid() bool {
+	return k >= 0x01 && k <= 0xFE
+}
+
+// ParseKey 解析按键名称（忽略大小写、空格、下划线和连字符，支持别名和虚拟键码数值）
+func ParseKey(s string) (KeyCode, error) {
+	key := normalizeName(s)
+	if key == "" {
+		return 0, fmt.Errorf("empty key name")
+	}
+
+	for _, k := range namedKeys {
+		if strings.ToLower(k.String()) == key {
+			return k, nil
+		}
+	}
+	if k, ok := keyAliases[key]; ok {
+		return k, nil
+	}
+
+	// 小键盘别名：Num0 / KP0
+	for _, prefix := range []string{"num", "kp"} {
+		if rest, ok := strings.CutPrefix(key, prefix); ok && len(rest) == 1 && rest[0] >= '0' && rest[0] <= '9' {
+			return KeyNumpad0 + KeyCode(rest[0]-'0'), nil
+		}
+	}
+
+	// 虚拟键码数值（仅接受十六进制写法，避免与数字键 "0"-"9" 混淆）
+	//
+	// 超出虚拟键码范围的值也保留原值（与 Name 的输出互为逆操作），由规则检查报告为无效按键码。
+	if strings.HasPrefix(key, "0x") {
+		n, err := strconv.ParseUint(key[2:], 16, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid key code %q", s)
+		}
+		return KeyCode(int32(n)), nil
+	}
+
+	return 0, fmt.Errorf("unknown key %q", s)
+}
+
+// MarshalText 以规范名称序列化
+func (k KeyCode) MarshalText() ([]byte, error) {
+	return []byte(k.Name()), nil
+}
+
+// UnmarshalText 从名称或十六进制键码反序列化
+func (k *KeyCode) UnmarshalText(text []byte) error {
+	key, err := ParseKey(string(text))
+	if err != nil {
+		return err
+	}
+	*k = key
+	return nil
+}
+
+// UnmarshalJSON 同时支持名称字符串和旧版数值格式
+func (k *KeyCode) UnmarshalJSON(data []byte) error {
+	var n int32
+	if err := json.Unmarshal(data, &n); err == nil {
+		*k = KeyCode(n)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("key must be a name or number: %s", data)
+	}
+	return k.UnmarshalText([]byte(s))
+}
+
+// String 返回修饰键组合的文本形式（如 "Ctrl+Shift"，无修饰键时为空）
+func (m Modifiers) String() string {
+	var parts []string
+	if m.Ctrl {
+		parts = append(parts, "Ctrl")
+	}
+	if m.Alt {
+		parts = append(parts, "Alt")
+	}
+	if m.Shift {
+		parts = append(parts, "Shift")
+	}
+	if m.Win {
+		parts = append(parts, "Win")
+	}
+	return strings.Join(parts, "+")
+}
+
+// IsEmpty 检查是否没有任何修饰键
+func (m Modifiers) IsEmpty() bool {
+	return !m.Ctrl && !m.Alt && !m.Shift && !m.Win
+}
+
+// Keys 返回修饰键对应的按键（Win 使用左 Win 键）
+func (m Modifiers) Keys() []KeyCode {
+	var keys []KeyCode
+	if m.Ctrl {
+		keys = append(keys, KeyCtrl)
+	}
+	if m.Alt {
+		keys = append(keys, KeyAlt)
+	}
+	if m.Shift {
+		keys = append(keys, KeyShift)
+	}
+	if m.Win {
+		keys = append(keys, KeyLWin)
+	}
+	return keys
+}
+
+// parseModifier 解析单个修饰键名称，返回是否识别
+func (m *Modifiers) parseModifier(name string) bool {
+	switch normalizeName(name) {
+	case "ctrl", "control":
+		m.Ctrl = true
+	case "alt":
+		m.Alt = true
+	case "shift":
+		m.Shift = true
+	case "win", "windows", "super", "meta", "cmd":
+		m.Win = true
+	default:
+		return false
+	}
+	return true
+}
+
+// ParseModifiers 解析修饰键组合（如 "Ctrl+Shift"）
+func ParseModifiers(s string) (Modifiers, error) {
+	var m Modifiers
+	if strings.TrimSpace(s) == "" {
+		return m, nil
+	}
+	for _, part := range strings.Split(s, "+") {
+		if !m.parseModifier(part) {
+			return Modifiers{}, fmt.Errorf("unknown modifier %q", strings.TrimSpace(part))
+		}
+	}
+	return m, nil
+}
+
+// MarshalJSON 修饰键以 "Ctrl+Shift" 形式保存
+func (m Modifiers) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON 同时支持文本形式和旧版对象形式（{"ctrl":true,...}）
+func (m *Modifiers) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		mods, err := ParseModifiers(s)
+		if err != nil {
+			return err
+		}
+		*m = mods
+		return nil
+	}
+
+	// 旧版对象形式（使用别名类型避免递归）
+	type legacy Modifiers
+	var l legacy
+	if err := json.Unmarshal(data, &l); err != nil {
+		return fmt.Errorf("modifiers must be a string or object: %s", data)
+	}
+	*m = Modifiers(l)
+	return nil
+}
+
+// ParseShortcut 解析快捷键文本（如 "Ctrl+Shift+F5"、"Alt+Tab"、"W+D"、"RCtrl+C"）
+//
+// 只有修饰键时（如 "Shift"）把修饰键本身作为按键返回。左右区分的修饰键（LShift、RCtrl 等）
+// 总是作为按键返回。
+func ParseShortcut(s string) ([]KeyCode, Modifiers, error) {
+	var mods Modifiers
+	var keys []KeyCode
+
+	if strings.TrimSpace(s) == "" {
+		return nil, mods, fmt.Errorf("empty shortcut")
+	}
+
+	for _, part := range strings.Split(s, "+") {
+		if strings.TrimSpace(part) == "" {
+			return nil, Modifiers{}, fmt.Errorf("invalid shortcut %q", s)
+		}
+		if mods.parseModifier(part) {
+			continue
+		}
+		key, err := ParseKey(part)
+		if err != nil {
+			return nil, Modifiers{}, err
+		}
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return mods.Keys(), Modifiers{}, nil
+	}
+	return keys, mods, nil
+}
+
+// FormatShortcut 将按键和修饰键格式化为快捷键文本（ParseShortcut 的逆操作）
+func FormatShortcut(keys []KeyCode, mods Modifiers) string {
+	var parts []string
+	if s := mods.String(); s != "" {
+		parts = append(parts, s)
+	}
+	for _, key := range keys {
+		parts = append(parts, key.Name())
+	}
+	return strings.Join(parts, "+")
+}
+
+// normalizeName 规范化名称用于比较
+func normalizeName(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(s)
+}
@@ -0,0 +1,134 @@
+package keyboard
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestKeyNameRoundTrip(t *testing.T) {
+	keys := append(NamedKeys(), 0, 0x07, 0xFF, 0x100, -1) // 未命名和超出范围的键码
+	for _, key := range keys {
+		name := key.Name()
+		parsed, err := ParseKey(name)
+		if err != nil {
+			t.Errorf("ParseKey(%q) for %d: %v", name, int(key), err)
+			continue
+		}
+		if parsed != key || parsed.Name() != name {
+			t.Errorf("ParseKey(%q) = %d (%s), want %d", name, int(parsed), parsed.Name(), int(key))
+		}
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    KeyCode
+		wantErr bool
+	}{
+		{"A", KeyA, false},
+		{"f1", KeyF1, false},
+		{"Esc", KeyEscape, false},
+		{"page-up", KeyPageUp, false},
+		{"Num5", KeyNumpad5, false},
+		{"kp0", KeyNumpad0, false},
+		{"0x41", KeyA, false},
+		{"0X41", KeyA, false},
+		{"5", KeyCode('5'), false}, // 数字键，不是键码
+		{"0x1FFFFFFFF", 0, true},
+		{"0xZZ", 0, true},
+		{"", 0, true},
+		{"Hyper", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseKey(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseKey(%q) = %d, %v; want %d, error %v", tt.input, int(got), err, int(tt.want), tt.wantErr)
+		}
+	}
+}
+
+func TestKeyJSONRoundTrip(t *testing.T) {
+	// 旧配置中的数值（包括未命名键码）保存为名称后仍能读回
+	for _, legacy := range []string{`65`, `7`, `300`, `-1`} {
+		var key KeyCode
+		if err := json.Unmarshal([]byte(legacy), &key); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", legacy, err)
+		}
+		data, err := json.Marshal(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var again KeyCode
+		if err := json.Unmarshal(data, &again); err != nil {
+			t.Fatalf("Unmarshal(%s) after saving %s: %v", data, legacy, err)
+		}
+		if again != key {
+			t.Errorf("%s -> %s -> %d, want %d", legacy, data, int(again), int(key))
+		}
+	}
+}
+
+func TestParseShortcut(t *testing.T) {
+	tests := []struct {
+		input   string
+		keys    []KeyCode
+		mods    Modifiers
+		wantErr bool
+	}{
+		{input: "F5", keys: []KeyCode{KeyF5}},
+		{input: "Ctrl+Shift+Esc", keys: []KeyCode{KeyEscape}, mods: Modifiers{Ctrl: true, Shift: true}},
+		{input: " alt + tab ", keys: []KeyCode{KeyTab}, mods: Modifiers{Alt: true}},
+		{input: "Win+D", keys: []KeyCode{KeyD}, mods: Modifiers{Win: true}},
+		{input: "W+D", keys: []KeyCode{KeyW, KeyD}},
+		{input: "RCtrl+C", keys: []KeyCode{KeyRCtrl, KeyC}}, // 左右区分的修饰键作为按键
+		{input: "Shift", keys: []KeyCode{KeyShift}},         // 只有修饰键时修饰键本身是按键
+		{input: "Ctrl+Alt", keys: []KeyCode{KeyCtrl, KeyAlt}},
+		{input: "Ctrl+0x90", keys: []KeyCode{KeyCode(0x90)}, mods: Modifiers{Ctrl: true}},
+		{input: "", wantErr: true},
+		{input: "Ctrl+", wantErr: true},
+		{input: "+A", wantErr: true},
+		{input: "Ctrl+Hyper", wantErr: true},
+	}
+	for _, tt := range tests {
+		keys, mods, err := ParseShortcut(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseShortcut(%q) error = %v, want error %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(keys, tt.keys) || mods != tt.mods {
+			t.Errorf("ParseShortcut(%q) = %v, %+v; want %v, %+v", tt.input, keys, mods, tt.keys, tt.mods)
+		}
+	}
+}
+
+func TestFormatShortcutRoundTrip(t *testing.T) {
+	tests := []struct {
+		keys []KeyCode
+		mods Modifiers
+		want string
+	}{
+		{[]KeyCode{KeyF5}, Modifiers{}, "F5"},
+		{[]KeyCode{KeyEscape}, Modifiers{Ctrl: true, Shift: true}, "Ctrl+Shift+Escape"},
+		{[]KeyCode{KeyA}, Modifiers{Ctrl: true, Alt: true, Shift: true, Win: true}, "Ctrl+Alt+Shift+Win+A"},
+		{[]KeyCode{KeyW, KeyD}, Modifiers{}, "W+D"},
+		{[]KeyCode{KeyRCtrl, KeyC}, Modifiers{}, "RCtrl+C"},
+		{[]KeyCode{KeyCode(0x07)}, Modifiers{Alt: true}, "Alt+0x07"},
+	}
+	for _, tt := range tests {
+		got := FormatShortcut(tt.keys, tt.mods)
+		if got != tt.want {
+			t.Errorf("FormatShortcut(%v, %+v) = %q, want %q", tt.keys, tt.mods, got, tt.want)
+			continue
+		}
+		keys, mods, err := ParseShortcut(got)
+		if err != nil {
+			t.Errorf("ParseShortcut(%q): %v", got, err)
+			continue
+		}
+		if !reflect.DeepEqual(keys, tt.keys) || mods != tt.mods {
+			t.Errorf("ParseShortcut(%q) = %v, %+v; want %v, %+v", got, keys, mods, tt.keys, tt.mods)
+		}
+	}
+}
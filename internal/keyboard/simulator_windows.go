@@ -3,6 +3,7 @@
 package keyboard
 
 import (
+	"log/slog"
 	"sync"
 	"syscall"
 	"unsafe"
@@ -261,6 +262,10 @@ func (s *Simulator) makeKeyInput(vk uint16, keyUp bool) INPUT {
 	if vk >= 0x70 && vk <= 0x7B { // F1-F12
 		input.Ki.Flags |= KEYEVENTF_EXTENDEDKEY
 	}
+	switch KeyCode(vk) {
+	case KeyRCtrl, KeyRAlt, KeyLWin, KeyRWin: // 右侧修饰键和 Win 键
+		input.Ki.Flags |= KEYEVENTF_EXTENDEDKEY
+	}
 
 	return input
 }
@@ -279,6 +284,7 @@ func (s *Simulator) sendInputs(inputs []INPUT) error {
 	)
 
 	if ret == 0 {
+		slog.Debug("SendInput failed", "inputs", len(inputs), "err", err)
 		return err
 	}
 
@@ -0,0 +1,76 @@
+// Package logging 设置全局的结构化日志（log/slog），输出到标准错误和日志文件
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	fileName    = "gamepad-key-mapper.log"
+	maxFileSize = 5 << 20 // 单个日志文件的最大字节数，超过后轮转
+	maxBackups  = 3       // 保留的旧日志文件数
+)
+
+// level 当前日志级别（可在运行时修改）
+var level = new(slog.LevelVar)
+
+// Setup 设置 slog 默认日志和标准库 log 的输出：日志文件写在 dir 中，同时输出到 console
+// （dir 为空时不写文件，console 为 nil 时不输出到控制台）
+//
+// 日志文件无法打开时仍会输出到 console，并返回错误。返回的函数用于关闭日志文件。
+func Setup(dir string, console io.Writer) (func() error, error) {
+	var file *RotatingFile
+	var openErr error
+	if dir != "" {
+		file, openErr = OpenRotatingFile(filepath.Join(dir, fileName), maxFileSize, maxBackups)
+	}
+
+	// 日志文件放在前面：窗口程序没有标准错误时写入失败不影响日志文件
+	var writers []io.Writer
+	if file != nil {
+		writers = append(writers, file)
+	}
+	if console != nil {
+		writers = append(writers, console)
+	}
+	out := io.Discard
+	if len(writers) > 0 {
+		out = io.MultiWriter(writers...)
+	}
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: level})))
+
+	if openErr != nil {
+		return func() error { return nil }, fmt.Errorf("无法打开日志文件: %w", openErr)
+	}
+	if file == nil {
+		return func() error { return nil }, nil
+	}
+	return file.Close, nil
+}
+
+// SetLevel 设置日志级别
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// Level 返回当前日志级别
+func Level() slog.Level {
+	return level.Level()
+}
+
+// ParseLevel 解析日志级别名称（debug/info/warn/error，不区分大小写，空字符串为 info）
+func ParseLevel(name string) (slog.Level, error) {
+	var l slog.Level
+	if strings.TrimSpace(name) == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+		return slog.LevelInfo, fmt.Errorf("无效的日志级别 %q（可用 debug、info、warn、error）", name)
+	}
+	return l, nil
+}
@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFile 按大小轮转的日志文件
+//
+// 写入后超过 maxSize 时，当前文件改名为 path.1（已有的 path.1 改名为 path.2，依此类推），
+// 最多保留 maxBackups 个旧文件。
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// OpenRotatingFile 打开（追加写入）日志文件
+func OpenRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open 打开日志文件并记录当前大小
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file = f
+	r.size = info.Size()
+	return nil
+}
+
+// Write 实现 io.Writer
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate 关闭当前文件，依次改名旧文件后重新打开（调用方需持有锁）
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		// 旧句柄已不可用，重新打开后继续写入当前文件，本次写入返回关闭错误
+		r.file = nil
+		if openErr := r.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	r.file = nil
+
+	for i := r.maxBackups - 1; i >= 1; i-- {
+		os.Rename(r.backupPath(i), r.backupPath(i+1)) // 不存在的旧文件直接跳过
+	}
+	var err error
+	if r.maxBackups > 0 {
+		err = os.Rename(r.path, r.backupPath(1))
+	} else {
+		err = os.Remove(r.path)
+	}
+	if openErr := r.open(); openErr != nil {
+		return openErr
+	}
+	if err != nil {
+		r.size = 0 // 无法轮转（如文件被其它程序占用）时继续写入当前文件，写满一轮后再尝试
+	}
+	return nil
+}
+
+// backupPath 返回第 n 个旧文件的路径
+func (r *RotatingFile) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", r.path, n)
+}
+
+// Close 关闭日志文件
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
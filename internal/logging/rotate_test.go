@@ -0,0 +1,161 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// readLogs 返回日志文件和各个旧文件的内容（不存在的文件为空字符串）
+func readLogs(t *testing.T, path string, backups int) []string {
+	t.Helper()
+	paths := []string{path}
+	for i := 1; i <= backups+1; i++ {
+		paths = append(paths, fmt.Sprintf("%s.%d", path, i))
+	}
+	contents := make([]string, len(paths))
+	for i, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			t.Fatal(err)
+		}
+		contents[i] = string(data)
+	}
+	return contents
+}
+
+func TestRotatingFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxBackups int
+		writes     []string
+		want       []string // 当前文件、.1、.2 …（多检查一个不应存在的旧文件）
+	}{
+		{
+			name:       "below max size",
+			maxBackups: 2,
+			writes:     []string{"aaaa", "bbbb"},
+			want:       []string{"aaaabbbb", "", "", ""},
+		},
+		{
+			name:       "rotates when exceeding max size",
+			maxBackups: 2,
+			writes:     []string{"aaaa", "bbbb", "cc"},
+			want:       []string{"cc", "aaaabbbb", "", ""},
+		},
+		{
+			name:       "backups shift",
+			maxBackups: 2,
+			writes:     []string{"aaaaaaaa", "bbbbbbbb", "cccc"},
+			want:       []string{"cccc", "bbbbbbbb", "aaaaaaaa", ""},
+		},
+		{
+			name:       "oldest backup dropped",
+			maxBackups: 2,
+			writes:     []string{"aaaaaaaa", "bbbbbbbb", "cccccccc", "dd"},
+			want:       []string{"dd", "cccccccc", "bbbbbbbb", ""},
+		},
+		{
+			name:       "oversized write goes to empty file",
+			maxBackups: 2,
+			writes:     []string{"0123456789ab", "cd"},
+			want:       []string{"cd", "0123456789ab", "", ""},
+		},
+		{
+			name:       "no backups",
+			maxBackups: 0,
+			writes:     []string{"aaaaaaaa", "bb"},
+			want:       []string{"bb", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.log")
+			r, err := OpenRotatingFile(path, 8, tt.maxBackups)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range tt.writes {
+				if n, err := r.Write([]byte(w)); err != nil || n != len(w) {
+					t.Fatalf("Write(%q) = %d, %v", w, n, err)
+				}
+			}
+			if err := r.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := readLogs(t, path, tt.maxBackups); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("files = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotatingFileAppendsToExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("old12"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenRotatingFile(path, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	// 打开时已有 5 字节，再写 4 字节超过上限，先轮转
+	if _, err := r.Write([]byte("new1")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readLogs(t, path, 1); !reflect.DeepEqual(got, []string{"new1", "old12", ""}) {
+		t.Errorf("files = %q", got)
+	}
+}
+
+func TestRotatingFileWriteAfterClose(t *testing.T) {
+	r, err := OpenRotatingFile(filepath.Join(t.TempDir(), "test.log"), 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Write after Close err = %v, want os.ErrClosed", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("second Close err = %v, want nil", err)
+	}
+}
+
+func TestRotatingFileRecoversFromCloseError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	r, err := OpenRotatingFile(path, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if _, err := r.Write([]byte("aaaaaaaa")); err != nil {
+		t.Fatal(err)
+	}
+	r.file.Close() // 让轮转时关闭旧文件失败
+
+	if _, err := r.Write([]byte("b")); err == nil {
+		t.Fatal("Write with failed rotation err = nil, want close error")
+	}
+	if r.file == nil {
+		t.Fatal("file not reopened after close error")
+	}
+	// 重新打开后仍可写入，写满后正常轮转
+	if _, err := r.Write([]byte("c")); err != nil {
+		t.Fatalf("Write after recovery err = %v", err)
+	}
+	if got := readLogs(t, path, 1); !reflect.DeepEqual(got, []string{"c", "aaaaaaaa", ""}) {
+		t.Errorf("files = %q", got)
+	}
+}
@@ -0,0 +1,234 @@
+package mapper
+
+import (
+	"math"
+	"sort"
+
+	"gamepad-key-mapper/internal/gamepad"
+)
+
+// AxisTarget 模拟量输出目标
+//
+// 目前只支持鼠标输出。输出到虚拟手柄（交换摇杆、扳机映射到摇杆等）需要 ViGEm 之类的外部驱动，暂不支持。
+type AxisTarget int
+
+const (
+	AxisTargetMouseX AxisTarget = iota // 鼠标水平移动
+	AxisTargetMouseY                   // 鼠标垂直移动（正值向上，与摇杆一致）
+	AxisTargetWheel                    // 鼠标滚轮（正值向上）
+	AxisTargetWheelH                   // 鼠标水平滚轮（正值向右）
+)
+
+// 鼠标输出的默认缩放（满偏转时每次轮询的输出量）
+const (
+	defaultMouseScale = 20 // 像素
+	defaultWheelScale = 30 // 滚轮单位（120 为一格）
+)
+
+// String 返回输出目标名称
+func (t AxisTarget) String() string {
+	switch t {
+	case AxisTargetMouseX:
+		return "鼠标 X"
+	case AxisTargetMouseY:
+		return "鼠标 Y"
+	case AxisTargetWheel:
+		return "滚轮"
+	case AxisTargetWheelH:
+		return "水平滚轮"
+	default:
+		return "Unknown"
+	}
+}
+
+// IsValid 检查是否为支持的输出目标
+func (t AxisTarget) IsValid() bool {
+	return t >= AxisTargetMouseX && t <= AxisTargetWheelH
+}
+
+// CurveType 响应曲线类型
+type CurveType int
+
+const (
+	CurveLinear      CurveType = iota // 线性
+	CurveExponential                  // 指数曲线（小幅度更精细）
+	CurveSCurve                       // S曲线（中间段更灵敏）
+	CurveCustom                       // 自定义折线
+)
+
+// CurvePoint 自定义曲线上的点（X、Y 均为 0~1）
+type CurvePoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// ResponseCurve 响应曲线
+type ResponseCurve struct {
+	Type     CurveType    `json:"type"`
+	Exponent float64      `json:"exponent,omitempty"` // 指数/S曲线强度（0 表示默认值 2）
+	Points   []CurvePoint `json:"points,omitempty"`   // 自定义曲线点
+}
+
+// Apply 对 0~1 的幅度应用响应曲线
+func (c ResponseCurve) Apply(x float64) float64 {
+	x = clamp(x, 0, 1)
+
+	exp := c.Exponent
+	if exp <= 0 {
+		exp = 2
+	}
+
+	switch c.Type {
+	case CurveExponential:
+		return math.Pow(x, exp)
+	case CurveSCurve:
+		a := math.Pow(x, exp)
+		b := math.Pow(1-x, exp)
+		if a+b == 0 {
+			return x
+		}
+		return a / (a + b)
+	case CurveCustom:
+		return interpolatePoints(c.Points, x)
+	default:
+		return x
+	}
+}
+
+// interpolatePoints 在自定义曲线点之间线性插值（隐含端点 (0,0) 和 (1,1)）
+func interpolatePoints(points []CurvePoint, x float64) float64 {
+	pts := make([]CurvePoint, 0, len(points)+2)
+	pts = append(pts, CurvePoint{0, 0})
+	for _, p := range points {
+		if p.X > 0 && p.X < 1 {
+			pts = append(pts, CurvePoint{p.X, clamp(p.Y, 0, 1)})
+		}
+	}
+	pts = append(pts, CurvePoint{1, 1})
+
+	// 用户填写的端点值覆盖隐含端点
+	for _, p := range points {
+		if p.X <= 0 {
+			pts[0].Y = clamp(p.Y, 0, 1)
+		} else if p.X >= 1 {
+			pts[len(pts)-1].Y = clamp(p.Y, 0, 1)
+		}
+	}
+
+	sort.SliceStable(pts, func(i, j int) bool { return pts[i].X < pts[j].X })
+
+	for i := 1; i < len(pts); i++ {
+		if x <= pts[i].X {
+			p0, p1 := pts[i-1], pts[i]
+			if p1.X == p0.X {
+				return p1.Y
+			}
+			t := (x - p0.X) / (p1.X - p0.X)
+			return p0.Y + t*(p1.Y-p0.Y)
+		}
+	}
+	return pts[len(pts)-1].Y
+}
+
+// AxisRule 定义一条从手柄模拟轴到模拟量输出的映射规则
+type AxisRule struct {
+	ID         string        `json:"id"`          // 唯一标识
+	Name       string        `json:"name"`        // 规则名称（可选）
+	Source     gamepad.Axis  `json:"source"`      // 源轴
+	Target     AxisTarget    `json:"target"`      // 输出目标
+	Invert     bool          `json:"invert"`      // 反转方向
+	Deadzone   float64       `json:"deadzone"`    // 内死区（0~1）
+	OuterRange float64       `json:"outer_range"` // 外圈范围（0~1，达到即视为满偏转，0 表示 1）
+	Curve      ResponseCurve `json:"curve"`       // 响应曲线
+	Scale      float64       `json:"scale"`       // 输出缩放（每次轮询的最大输出量，0 表示默认值）
+	Enabled    bool          `json:"enabled"`     // 是否启用
+}
+
+// NewAxisRule 创建一个模拟轴映射规则
+func NewAxisRule(id string, source gamepad.Axis, target AxisTarget) *AxisRule {
+	return &AxisRule{
+		ID:      id,
+		Source:  source,
+		Target:  target,
+		Enabled: true,
+	}
+}
+
+// String 返回规则的可读描述
+func (r *AxisRule) String() string {
+	s := r.Source.String() + " → " + r.Target.String()
+	if r.Invert {
+		s += " (反转)"
+	}
+	return s
+}
+
+// Transform 对源轴的归一化值依次应用死区、外圈范围、响应曲线和反转
+func (r *AxisRule) Transform(raw float64) float64 {
+	sign := 1.0
+	if raw < 0 {
+		sign = -1
+	}
+	mag := math.Abs(raw)
+
+	deadzone := clamp(r.Deadzone, 0, 1)
+	if mag <= deadzone {
+		return 0
+	}
+
+	outer := r.OuterRange
+	if outer <= 0 || outer > 1 {
+		outer = 1
+	}
+	if outer <= deadzone {
+		mag = 1
+	} else {
+		mag = clamp((mag-deadzone)/(outer-deadzone), 0, 1)
+	}
+
+	v := sign * r.Curve.Apply(mag)
+	if r.Invert {
+		v = -v
+	}
+	return v
+}
+
+// scale 返回实际使用的输出缩放
+func (r *AxisRule) scale() float64 {
+	if r.Scale != 0 {
+		return r.Scale
+	}
+	switch r.Target {
+	case AxisTargetMouseX, AxisTargetMouseY:
+		return defaultMouseScale
+	case AxisTargetWheel, AxisTargetWheelH:
+		return defaultWheelScale
+	default:
+		return 1
+	}
+}
+
+// axisAccumulator 累积鼠标输出的小数部分，避免低速移动被截断为0
+type axisAccumulator struct {
+	mouseX, mouseY float64
+	wheel, wheelH  float64
+}
+
+// take 累加并取出整数部分
+func take(acc *float64, delta float64) int {
+	*acc += delta
+	whole := math.Trunc(*acc)
+	*acc -= whole
+	return int(whole)
+}
+
+// clamp 将值限制在 [lo, hi] 范围内
+func clamp(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,196 @@
+package mapper
+
+import (
+	"math"
+	"testing"
+
+	"gamepad-key-mapper/internal/gamepad"
+)
+
+const epsilon = 1e-9
+
+func TestResponseCurveApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		curve ResponseCurve
+		in    float64
+		want  float64
+	}{
+		{"linear", ResponseCurve{Type: CurveLinear}, 0.3, 0.3},
+		{"linear clamps above", ResponseCurve{Type: CurveLinear}, 1.5, 1},
+		{"linear clamps below", ResponseCurve{Type: CurveLinear}, -0.2, 0},
+		{"exponential default", ResponseCurve{Type: CurveExponential}, 0.5, 0.25},
+		{"exponential cubic", ResponseCurve{Type: CurveExponential, Exponent: 3}, 0.5, 0.125},
+		{"exponential full", ResponseCurve{Type: CurveExponential, Exponent: 3}, 1, 1},
+		{"s-curve midpoint", ResponseCurve{Type: CurveSCurve}, 0.5, 0.5},
+		{"s-curve low", ResponseCurve{Type: CurveSCurve}, 0.25, 0.1},
+		{"s-curve high", ResponseCurve{Type: CurveSCurve}, 0.75, 0.9},
+		{"s-curve zero", ResponseCurve{Type: CurveSCurve}, 0, 0},
+		{"custom below point", ResponseCurve{Type: CurveCustom, Points: []CurvePoint{{0.5, 0.2}}}, 0.25, 0.1},
+		{"custom above point", ResponseCurve{Type: CurveCustom, Points: []CurvePoint{{0.5, 0.2}}}, 0.75, 0.6},
+		{"custom unsorted points", ResponseCurve{Type: CurveCustom, Points: []CurvePoint{{0.8, 0.9}, {0.2, 0.1}}}, 0.5, 0.5},
+		{"custom endpoint override", ResponseCurve{Type: CurveCustom, Points: []CurvePoint{{1, 0.8}}}, 1, 0.8},
+		{"custom no points", ResponseCurve{Type: CurveCustom}, 0.4, 0.4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.curve.Apply(tt.in); math.Abs(got-tt.want) > epsilon {
+				t.Errorf("Apply(%v) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAxisRuleTransform(t *testing.T) {
+	tests := []struct {
+		name string
+		rule AxisRule
+		in   float64
+		want float64
+	}{
+		{"passthrough", AxisRule{}, 0.5, 0.5},
+		{"negative passthrough", AxisRule{}, -0.5, -0.5},
+		{"invert", AxisRule{Invert: true}, 0.5, -0.5},
+		{"invert negative", AxisRule{Invert: true}, -1, 1},
+		{"inside deadzone", AxisRule{Deadzone: 0.2}, 0.1, 0},
+		{"deadzone rescales", AxisRule{Deadzone: 0.2}, 0.6, 0.5},
+		{"deadzone keeps sign", AxisRule{Deadzone: 0.2}, -0.6, -0.5},
+		{"outer range saturates", AxisRule{OuterRange: 0.5}, 0.75, 1},
+		{"outer range rescales", AxisRule{OuterRange: 0.5}, 0.25, 0.5},
+		{"outer range inside deadzone", AxisRule{Deadzone: 0.5, OuterRange: 0.4}, 0.6, 1},
+		{"curve after deadzone", AxisRule{Deadzone: 0.2, Curve: ResponseCurve{Type: CurveExponential}}, 0.6, 0.25},
+		{"curve then invert", AxisRule{Invert: true, Curve: ResponseCurve{Type: CurveExponential}}, -0.5, 0.25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Transform(tt.in); math.Abs(got-tt.want) > epsilon {
+				t.Errorf("Transform(%v) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleAxesMouseScale(t *testing.T) {
+	tests := []struct {
+		name       string
+		rule       *AxisRule
+		state      gamepad.XInputGamepad
+		frames     int
+		wantDX     int
+		wantDY     int
+		wantWheel  int
+		wantWheelH int
+	}{
+		{
+			name:   "default mouse scale",
+			rule:   &AxisRule{Source: gamepad.AxisLeftX, Target: AxisTargetMouseX, Enabled: true},
+			state:  gamepad.XInputGamepad{ThumbLX: 32767},
+			frames: 1,
+			wantDX: defaultMouseScale,
+		},
+		{
+			name:   "custom scale",
+			rule:   &AxisRule{Source: gamepad.AxisLeftX, Target: AxisTargetMouseX, Scale: 7, Enabled: true},
+			state:  gamepad.XInputGamepad{ThumbLX: -32767},
+			frames: 1,
+			wantDX: -7,
+		},
+		{
+			name:   "stick up moves cursor up",
+			rule:   &AxisRule{Source: gamepad.AxisRightY, Target: AxisTargetMouseY, Scale: 5, Enabled: true},
+			state:  gamepad.XInputGamepad{ThumbRY: 32767},
+			frames: 1,
+			wantDY: -5,
+		},
+		{
+			name:   "fractions accumulate",
+			rule:   &AxisRule{Source: gamepad.AxisLeftX, Target: AxisTargetMouseX, Scale: 0.4, Enabled: true},
+			state:  gamepad.XInputGamepad{ThumbLX: 32767},
+			frames: 5,
+			wantDX: 2,
+		},
+		{
+			name:      "trigger to wheel",
+			rule:      &AxisRule{Source: gamepad.AxisRightTrigger, Target: AxisTargetWheel, Enabled: true},
+			state:     gamepad.XInputGamepad{RightTrigger: 255},
+			frames:    2,
+			wantWheel: 2 * defaultWheelScale,
+		},
+		{
+			name:       "inverted horizontal wheel",
+			rule:       &AxisRule{Source: gamepad.AxisLeftX, Target: AxisTargetWheelH, Invert: true, Scale: 10, Enabled: true},
+			state:      gamepad.XInputGamepad{ThumbLX: 32767},
+			frames:     1,
+			wantWheelH: -10,
+		},
+		{
+			name:   "disabled rule",
+			rule:   &AxisRule{Source: gamepad.AxisLeftX, Target: AxisTargetMouseX},
+			state:  gamepad.XInputGamepad{ThumbLX: 32767},
+			frames: 1,
+		},
+		{
+			name:   "unsupported target",
+			rule:   &AxisRule{Source: gamepad.AxisLeftX, Target: AxisTarget(4), Enabled: true},
+			state:  gamepad.XInputGamepad{ThumbLX: 32767},
+			frames: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _, ms := newTestMapper()
+			m.AddAxisRule(tt.rule)
+			for i := 0; i < tt.frames; i++ {
+				m.HandleAxes(tt.state)
+			}
+			if ms.dx != tt.wantDX || ms.dy != tt.wantDY {
+				t.Errorf("mouse moved (%d,%d), want (%d,%d)", ms.dx, ms.dy, tt.wantDX, tt.wantDY)
+			}
+			if ms.wheel != tt.wantWheel || ms.wheelH != tt.wantWheelH {
+				t.Errorf("wheel (%d,%d), want (%d,%d)", ms.wheel, ms.wheelH, tt.wantWheel, tt.wantWheelH)
+			}
+		})
+	}
+}
+
+func TestHandleAxesIdle(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     []*AxisRule
+		wantCalls int
+	}{
+		{name: "no rules"},
+		{name: "only disabled rules", rules: []*AxisRule{{Source: gamepad.AxisLeftX, Target: AxisTargetMouseX}}},
+		// 有启用的规则时每帧都输出一次移动和两次滚动（即使为0）
+		{name: "enabled rule", rules: []*AxisRule{{Source: gamepad.AxisLeftX, Target: AxisTargetMouseX, Enabled: true}}, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _, ms := newTestMapper()
+			m.SetAxisRules(tt.rules)
+
+			m.HandleAxes(gamepad.XInputGamepad{})
+			if ms.calls != tt.wantCalls {
+				t.Errorf("mouse output calls = %d, want %d", ms.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestValidateAxisRules(t *testing.T) {
+	rules := []*AxisRule{
+		{ID: "mouse", Source: gamepad.AxisLeftX, Target: AxisTargetMouseX, Enabled: true},
+		{ID: "legacy pad", Source: gamepad.AxisLeftX, Target: AxisTarget(6), Enabled: true},
+		{ID: "disabled", Source: gamepad.AxisLeftY, Target: AxisTarget(7)},
+		nil,
+	}
+
+	diags := ValidateAxisRules(rules)
+	if len(diags) != 1 || diags[0].RuleID != "legacy pad" || diags[0].Code != CodeInvalidTarget || diags[0].Severity != SeverityError {
+		t.Fatalf("diagnostics = %v, want one invalid_target error for rule legacy pad", diags)
+	}
+}
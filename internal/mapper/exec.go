@@ -0,0 +1,187 @@
+package mapper
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// ExecMode 命令执行方式
+type ExecMode int
+
+const (
+	ExecProgram ExecMode = iota // 直接启动程序（Command 为程序路径，Args 为参数），不等待结束
+	ExecShell                   // 通过系统 shell 执行命令行（Windows: cmd /C，其他: sh -c），等待结束并记录输出
+	ExecOpen                    // 使用系统默认程序打开 URL 或文件，不等待结束
+)
+
+// defaultExecTimeout 默认命令超时时间（仅 ExecShell）
+const defaultExecTimeout = 30 * time.Second
+
+// execWaitDelay shell 退出或超时后等待输出管道关闭的时间
+//
+// 命令在后台启动的子进程（如 sh -c "foo &"）会继承输出管道，不设置时
+// 读取输出会一直等到子进程结束，超时也不起作用。
+const execWaitDelay = time.Second
+
+// ErrExecNotAllowed 配置未允许执行命令
+var ErrExecNotAllowed = errors.New("exec actions are disabled by config (allow_exec)")
+
+// ExecAction 命令执行目标（当 TargetType == TargetExec）
+type ExecAction struct {
+	Mode      ExecMode `json:"mode"`                  // 执行方式
+	Command   string   `json:"command"`               // 程序路径 / 命令行 / URL或文件路径
+	Args      []string `json:"args,omitempty"`        // 参数（仅 ExecProgram）
+	Dir       string   `json:"dir,omitempty"`         // 工作目录（空为当前目录）
+	Env       []string `json:"env,omitempty"`         // 附加环境变量（KEY=VALUE）
+	OnPress   bool     `json:"on_press"`              // 按下时执行
+	OnRelease bool     `json:"on_release"`            // 释放时执行
+	Timeout   int      `json:"timeout_sec,omitempty"` // 超时秒数（仅 ExecShell，0 为默认 30 秒）
+}
+
+// String 返回命令的可读描述
+func (a *ExecAction) String() string {
+	switch a.Mode {
+	case ExecOpen:
+		return "打开 " + a.Command
+	case ExecShell:
+		return a.Command
+	default:
+		if len(a.Args) == 0 {
+			return a.Command
+		}
+		return a.Command + " " + strings.Join(a.Args, " ")
+	}
+}
+
+// FiresOn 检查在按下/释放时是否应执行
+func (a *ExecAction) FiresOn(pressed bool) bool {
+	if pressed {
+		return a.OnPress
+	}
+	return a.OnRelease
+}
+
+// timeout 返回实际使用的超时时间
+func (a *ExecAction) timeout() time.Duration {
+	if a.Timeout > 0 {
+		return time.Duration(a.Timeout) * time.Second
+	}
+	return defaultExecTimeout
+}
+
+// command 根据执行方式构造命令（ctx 只用于 ExecShell，其他方式启动的进程不受其控制）
+func (a *ExecAction) command(ctx context.Context) (*exec.Cmd, error) {
+	if strings.TrimSpace(a.Command) == "" {
+		return nil, errors.New("empty command")
+	}
+
+	var cmd *exec.Cmd
+	switch a.Mode {
+	case ExecProgram:
+		cmd = exec.Command(a.Command, a.Args...)
+	case ExecShell:
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/C", a.Command)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", "-c", a.Command)
+		}
+		cmd.WaitDelay = execWaitDelay
+	case ExecOpen:
+		switch runtime.GOOS {
+		case "windows":
+			cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", a.Command)
+		case "darwin":
+			cmd = exec.Command("open", a.Command)
+		default:
+			cmd = exec.Command("xdg-open", a.Command)
+		}
+	default:
+		return nil, errors.New("unknown exec mode")
+	}
+
+	cmd.Dir = a.Dir
+	if len(a.Env) > 0 {
+		cmd.Env = append(os.Environ(), a.Env...)
+	}
+	return cmd, nil
+}
+
+// Run 执行命令
+//
+// ExecShell 等待命令结束并返回输出，超时后终止进程；启动程序和打开 URL/文件只负责启动，
+// 进程与映射器分离（例如启动 OBS 后不会因超时被终止），返回的输出为空。
+func (a *ExecAction) Run() ([]byte, error) {
+	if a.Mode != ExecShell {
+		return nil, a.start()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout())
+	defer cancel()
+
+	cmd, err := a.command(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return output, ctx.Err()
+	}
+	if errors.Is(err, exec.ErrWaitDelay) {
+		// shell 本身已成功退出，只是后台子进程仍持有输出管道，不再收集它之后的输出
+		return output, nil
+	}
+	return output, err
+}
+
+// start 启动进程后立即返回，不等待、不设超时
+func (a *ExecAction) start() error {
+	cmd, err := a.command(context.Background())
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		return cmd.Process.Release()
+	}
+	// Unix 上需要回收已退出的子进程，否则会留下僵尸进程；Wait 不会终止进程
+	go cmd.Wait()
+	return nil
+}
+
+// runExec 在后台执行规则的命令并记录输出
+func (m *Mapper) runExec(rule *MappingRule, pressed bool, depth int) {
+	if rule.Exec == nil {
+		return
+	}
+
+	m.execMu.Lock()
+	allowed := m.execAllowed
+	m.execMu.Unlock()
+
+	if !allowed {
+		slog.Warn("exec rule skipped", "rule", rule.ID, "err", ErrExecNotAllowed)
+		m.traceSkip(rule.SourceKey, pressed, SkipExecDisabled, rule.ID, depth)
+		return
+	}
+	m.trace(TraceEvent{Kind: TraceExec, RuleID: rule.ID, Depth: depth})
+
+	action := *rule.Exec
+	go func() {
+		output, err := action.Run()
+		if len(output) > 0 {
+			slog.Info("exec rule output", "rule", rule.ID, "output", string(output))
+		}
+		if err != nil {
+			slog.Error("exec rule failed", "rule", rule.ID, "action", action.String(), "err", err)
+		}
+	}()
+}
@@ -0,0 +1,73 @@
+package mapper
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecShellRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		timeout int
+		output  string
+		wantErr error // 期望的错误（nil 表示成功）
+		failed  bool  // 期望命令以非零状态退出
+		maxTime time.Duration
+	}{
+		{name: "output", command: "echo hello", output: "hello\n", maxTime: 5 * time.Second},
+		{name: "exit status", command: "echo oops; exit 3", output: "oops\n", failed: true, maxTime: 5 * time.Second},
+		{name: "timeout", command: "sleep 10", timeout: 1, wantErr: context.DeadlineExceeded, maxTime: 1*time.Second + execWaitDelay + time.Second},
+		// 后台子进程继承了输出管道，shell 退出后不再等待它
+		{name: "background child", command: "echo started; sleep 10 &", output: "started\n", maxTime: execWaitDelay + 2*time.Second},
+		{name: "background child after timeout", command: "sleep 10 & sleep 10", timeout: 1, wantErr: context.DeadlineExceeded, maxTime: 1*time.Second + execWaitDelay + time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &ExecAction{Mode: ExecShell, Command: tt.command, Timeout: tt.timeout}
+			start := time.Now()
+			output, err := a.Run()
+			elapsed := time.Since(start)
+
+			var exitErr *exec.ExitError
+			switch {
+			case tt.failed:
+				if !errors.As(err, &exitErr) {
+					t.Errorf("Run() err = %v, want exit error", err)
+				}
+			case !errors.Is(err, tt.wantErr):
+				t.Errorf("Run() err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.output != "" && string(output) != tt.output {
+				t.Errorf("Run() output = %q, want %q", output, tt.output)
+			}
+			if elapsed > tt.maxTime {
+				t.Errorf("Run() took %v, want at most %v", elapsed, tt.maxTime)
+			}
+		})
+	}
+}
+
+func TestExecCommandErrors(t *testing.T) {
+	tests := []struct {
+		action ExecAction
+		want   string
+	}{
+		{ExecAction{Mode: ExecShell, Command: "  "}, "empty command"},
+		{ExecAction{Mode: ExecMode(9), Command: "x"}, "unknown exec mode"},
+	}
+	for _, tt := range tests {
+		if _, err := tt.action.Run(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Run(%+v) err = %v, want %q", tt.action, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package mapper
+
+import (
+	"sync"
+
+	"gamepad-key-mapper/internal/keyboard"
+)
+
+// fakeKeys 记录键盘输出的假设备
+type fakeKeys struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (f *fakeKeys) PressKeys(keys []keyboard.KeyCode, mods keyboard.Modifiers) error {
+	f.record("down " + keyboard.FormatShortcut(keys, mods))
+	return nil
+}
+
+func (f *fakeKeys) ReleaseKeys(keys []keyboard.KeyCode, mods keyboard.Modifiers) error {
+	f.record("up " + keyboard.FormatShortcut(keys, mods))
+	return nil
+}
+
+func (f *fakeKeys) ReleaseAllKeys() error {
+	f.record("release all")
+	return nil
+}
+
+func (f *fakeKeys) record(event string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, event)
+}
+
+func (f *fakeKeys) Events() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.events...)
+}
+
+// fakeMouse 累计鼠标输出的假设备
+type fakeMouse struct {
+	dx, dy, wheel, wheelH int
+	calls                 int // 输出调用次数
+}
+
+func (f *fakeMouse) Move(dx, dy int) error {
+	f.calls++
+	f.dx += dx
+	f.dy += dy
+	return nil
+}
+
+func (f *fakeMouse) Scroll(delta int) error {
+	f.calls++
+	f.wheel += delta
+	return nil
+}
+
+func (f *fakeMouse) ScrollHorizontal(delta int) error {
+	f.calls++
+	f.wheelH += delta
+	return nil
+}
+
+// newTestMapper 创建使用假输出设备的映射引擎
+func newTestMapper() (*Mapper, *fakeKeys, *fakeMouse) {
+	keys := &fakeKeys{}
+	ms := &fakeMouse{}
+	return newMapper(keys, ms), keys, ms
+}
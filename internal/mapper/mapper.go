@@ -1,21 +1,62 @@
 package mapper
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
 	"sync"
 
 	"gamepad-key-mapper/internal/gamepad"
 	"gamepad-key-mapper/internal/keyboard"
+	"gamepad-key-mapper/internal/mouse"
 )
 
+// keyOutput 键盘输出设备（由 keyboard.Simulator 实现）
+type keyOutput interface {
+	PressKeys(keys []keyboard.KeyCode, mods keyboard.Modifiers) error
+	ReleaseKeys(keys []keyboard.KeyCode, mods keyboard.Modifiers) error
+	ReleaseAllKeys() error
+}
+
+// mouseOutput 鼠标输出设备（由 mouse.Simulator 实现）
+type mouseOutput interface {
+	Move(dx, dy int) error
+	Scroll(delta int) error
+	ScrollHorizontal(delta int) error
+}
+
 // Mapper 映射引擎
 type Mapper struct {
 	rules     []*MappingRule
-	simulator *keyboard.Simulator
+	simulator keyOutput
 	mu        sync.RWMutex
+
+	// 模拟轴映射
+	axisRules []*AxisRule
+	mouse     mouseOutput
+	axisAcc   axisAccumulator // 鼠标输出累积值
+
+	// 命令执行开关（配置级别，默认关闭）
+	execAllowed bool
+	execMu      sync.Mutex
 	
 	// 用于防止循环映射的处理中标记
 	processing   map[gamepad.Button]bool
 	processingMu sync.Mutex // 保护 processing map
+
+	// 当前按住的源按键（修改规则时用于释放旧规则的输出）
+	held   map[gamepad.Button]bool
+	heldMu sync.Mutex // 保护 held map
+
+	// 跟踪事件观察者（用于事件日志）
+	tracer   Tracer
+	tracerMu sync.Mutex
+
+	// 模拟输出失败的通知（每种设备连续失败只通知一次）
+	onError      func(error)
+	outputFailed map[string]bool
+	errMu        sync.Mutex
 }
 
 // New 创建新的映射引擎
@@ -25,11 +66,25 @@ func New() (*Mapper, error) {
 		return nil, err
 	}
 
+	ms, err := mouse.NewSimulator()
+	if err != nil {
+		return nil, err
+	}
+
+	return newMapper(sim, ms), nil
+}
+
+// newMapper 使用指定的输出设备创建映射引擎
+func newMapper(keys keyOutput, ms mouseOutput) *Mapper {
 	return &Mapper{
-		rules:      make([]*MappingRule, 0),
-		simulator:  sim,
-		processing: make(map[gamepad.Button]bool),
-	}, nil
+		rules:        make([]*MappingRule, 0),
+		simulator:    keys,
+		axisRules:    make([]*AxisRule, 0),
+		mouse:        ms,
+		processing:   make(map[gamepad.Button]bool),
+		held:         make(map[gamepad.Button]bool),
+		outputFailed: make(map[string]bool),
+	}
 }
 
 // AddRule 添加映射规则
@@ -53,6 +108,117 @@ func (m *Mapper) RemoveRule(id string) bool {
 	return false
 }
 
+// UpdateRule 按ID替换规则（保持规则在列表中的位置），返回是否找到
+//
+// 如果旧规则的源按键正被按住，先释放旧规则的输出，之后的释放事件不再作用于新规则，
+// 避免按住的键被"卡住"或新规则收到没有按下的释放。
+func (m *Mapper) UpdateRule(rule *MappingRule) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, old := range m.rules {
+		if old.ID != rule.ID {
+			continue
+		}
+		if sameBehavior(old, rule) {
+			m.rules[i] = rule // 只修改了名称或说明，按住的键保持不变
+			return true
+		}
+
+		m.heldMu.Lock()
+		oldHeld := m.held[old.SourceKey]
+		delete(m.held, old.SourceKey)
+		delete(m.held, rule.SourceKey)
+		m.heldMu.Unlock()
+
+		if oldHeld && old.Enabled {
+			m.releaseRuleLocked(old)
+		}
+		m.rules[i] = rule
+		return true
+	}
+	return false
+}
+
+// SetRuleEnabled 启用或停用规则，返回是否找到
+//
+// 停用正被按住的规则时先释放它的输出。规则以副本替换，
+// GetRules 返回给界面和配置保存的旧指针不会在锁外被修改。
+func (m *Mapper) SetRuleEnabled(id string, enabled bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, rule := range m.rules {
+		if rule.ID != id {
+			continue
+		}
+		if rule.Enabled && !enabled {
+			m.heldMu.Lock()
+			held := m.held[rule.SourceKey]
+			delete(m.held, rule.SourceKey)
+			m.heldMu.Unlock()
+
+			if held {
+				m.releaseRuleLocked(rule)
+			}
+		}
+		updated := *rule
+		updated.Enabled = enabled
+		m.rules[i] = &updated
+		return true
+	}
+	return false
+}
+
+// MoveRule 将规则在列表中移动 delta 个位置（负数向前），返回是否移动
+//
+// 同一源按键有多条启用的规则时，只有排在前面的生效。
+func (m *Mapper) MoveRule(id string, delta int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, rule := range m.rules {
+		if rule.ID != id {
+			continue
+		}
+		j := i + delta
+		if j < 0 {
+			j = 0
+		}
+		if j >= len(m.rules) {
+			j = len(m.rules) - 1
+		}
+		if j == i {
+			return false
+		}
+
+		rules := append(m.rules[:i:i], m.rules[i+1:]...)
+		rules = append(rules[:j], append([]*MappingRule{rule}, rules[j:]...)...)
+		m.rules = rules
+		return true
+	}
+	return false
+}
+
+// sameBehavior 检查两条规则除名称和说明外是否完全相同
+func sameBehavior(a, b *MappingRule) bool {
+	x, y := *a, *b
+	x.Name, x.Description = "", ""
+	y.Name, y.Description = "", ""
+	return reflect.DeepEqual(x, y)
+}
+
+// releaseRuleLocked 释放规则按下时产生的输出（调用方需持有锁）
+func (m *Mapper) releaseRuleLocked(rule *MappingRule) {
+	switch rule.TargetType {
+	case TargetKeyboard:
+		m.sendKeys(rule, false, 0)
+	case TargetGamepad:
+		m.handleGamepadMapping(rule, false, 0, 1)
+	}
+	// 命令规则没有持续的输出，不执行释放时的命令
+}
+
 // GetRules 获取所有规则
 func (m *Mapper) GetRules() []*MappingRule {
 	m.mu.RLock()
@@ -78,13 +244,15 @@ func (m *Mapper) ClearRules() {
 	m.rules = make([]*MappingRule, 0)
 }
 
-// HasConflict 检查是否存在源按键冲突
+// HasConflict 检查是否已有其它启用的规则使用该源按键
+//
+// 同一源按键可以有多条规则（如不同场景的备选规则），但同时只能启用一条。
 func (m *Mapper) HasConflict(sourceKey gamepad.Button, excludeID string) bool {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	for _, rule := range m.rules {
-		if rule.SourceKey == sourceKey && rule.ID != excludeID {
+		if rule.Enabled && rule.SourceKey == sourceKey && rule.ID != excludeID {
 			return true
 		}
 	}
@@ -93,37 +261,86 @@ func (m *Mapper) HasConflict(sourceKey gamepad.Button, excludeID string) bool {
 
 // HandleEvent 处理手柄按键事件
 func (m *Mapper) HandleEvent(event gamepad.ButtonEvent) {
+	m.trace(TraceEvent{Kind: TraceInput, Button: event.Button, Pressed: event.Pressed})
+
 	// 检查是否正在处理（防止循环）
 	m.processingMu.Lock()
 	if m.processing[event.Button] {
 		m.processingMu.Unlock()
+		m.traceSkip(event.Button, event.Pressed, SkipCycle, "", 0)
 		return
 	}
 	m.processingMu.Unlock()
 
+	// 记录按住的源按键；没有记录的释放（按下后规则被修改）直接忽略
+	m.heldMu.Lock()
+	if event.Pressed {
+		m.held[event.Button] = true
+	} else if m.held[event.Button] {
+		delete(m.held, event.Button)
+	} else {
+		m.heldMu.Unlock()
+		m.traceSkip(event.Button, event.Pressed, SkipUnpaired, "", 0)
+		return
+	}
+	m.heldMu.Unlock()
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	for _, rule := range m.rules {
 		if rule.Enabled && rule.SourceKey == event.Button {
+			m.trace(TraceEvent{Kind: TraceMatch, Button: event.Button, Pressed: event.Pressed, RuleID: rule.ID})
 			if rule.TargetType == TargetKeyboard {
 				// 键盘映射
-				if event.Pressed {
-					m.simulator.PressKeys(rule.TargetKeys, rule.Modifiers)
-				} else {
-					m.simulator.ReleaseKeys(rule.TargetKeys, rule.Modifiers)
-				}
+				m.sendKeys(rule, event.Pressed, 0)
 			} else if rule.TargetType == TargetGamepad {
 				// 手柄到手柄映射：触发目标按键的映射
-				m.handleGamepadMapping(rule, event.Pressed, event.PlayerID)
+				m.handleGamepadMapping(rule, event.Pressed, event.PlayerID, 1)
+			} else if rule.TargetType == TargetExec {
+				// 命令执行
+				if rule.Exec != nil && rule.Exec.FiresOn(event.Pressed) {
+					m.runExec(rule, event.Pressed, 0)
+				}
 			}
-			break // 每个源按键只匹配一个规则
+			return // 每个源按键只匹配一个规则
 		}
 	}
+	m.traceUnmatchedLocked(event.Button, event.Pressed, 0)
+}
+
+// sendKeys 按下或释放规则的目标键盘按键
+func (m *Mapper) sendKeys(rule *MappingRule, pressed bool, depth int) {
+	kind := TraceKeyUp
+	var err error
+	if pressed {
+		err = m.simulator.PressKeys(rule.TargetKeys, rule.Modifiers)
+		kind = TraceKeyDown
+	} else {
+		err = m.simulator.ReleaseKeys(rule.TargetKeys, rule.Modifiers)
+	}
+	m.reportOutput("keyboard", err)
+	m.trace(TraceEvent{Kind: kind, RuleID: rule.ID, Keys: rule.TargetKeys, Modifiers: rule.Modifiers, Depth: depth})
 }
 
-// handleGamepadMapping 处理手柄到手柄的映射
-func (m *Mapper) handleGamepadMapping(rule *MappingRule, pressed bool, playerID int) {
+// traceSkip 记录没有触发规则的按键
+func (m *Mapper) traceSkip(button gamepad.Button, pressed bool, reason SkipReason, ruleID string, depth int) {
+	m.trace(TraceEvent{Kind: TraceSkip, Button: button, Pressed: pressed, Reason: reason, RuleID: ruleID, Depth: depth})
+}
+
+// traceUnmatchedLocked 记录没有启用的规则匹配的按键（区分没有规则和规则已停用，调用方需持有锁）
+func (m *Mapper) traceUnmatchedLocked(button gamepad.Button, pressed bool, depth int) {
+	for _, rule := range m.rules {
+		if rule.SourceKey == button {
+			m.traceSkip(button, pressed, SkipDisabled, rule.ID, depth)
+			return
+		}
+	}
+	m.traceSkip(button, pressed, SkipNoRule, "", depth)
+}
+
+// handleGamepadMapping 处理手柄到手柄的映射（depth 为目标按键所在的映射层级，用于跟踪）
+func (m *Mapper) handleGamepadMapping(rule *MappingRule, pressed bool, playerID int, depth int) {
 	// 标记源按键正在处理，防止循环
 	m.processingMu.Lock()
 	m.processing[rule.SourceKey] = true
@@ -138,15 +355,14 @@ func (m *Mapper) handleGamepadMapping(rule *MappingRule, pressed bool, playerID
 	// 为每个目标手柄按键触发映射
 	for _, targetBtn := range rule.TargetButtons {
 		// 查找目标按键的映射规则
+		matched := false
 		for _, targetRule := range m.rules {
 			if targetRule.Enabled && targetRule.SourceKey == targetBtn && targetRule.ID != rule.ID {
+				matched = true
+				m.trace(TraceEvent{Kind: TraceMatch, Button: targetBtn, Pressed: pressed, RuleID: targetRule.ID, Depth: depth})
 				if targetRule.TargetType == TargetKeyboard {
 					// 目标按键映射到键盘
-					if pressed {
-						m.simulator.PressKeys(targetRule.TargetKeys, targetRule.Modifiers)
-					} else {
-						m.simulator.ReleaseKeys(targetRule.TargetKeys, targetRule.Modifiers)
-					}
+					m.sendKeys(targetRule, pressed, depth)
 				} else if targetRule.TargetType == TargetGamepad {
 					// 目标按键也是手柄映射，递归处理（有循环保护）
 					m.processingMu.Lock()
@@ -157,21 +373,110 @@ func (m *Mapper) handleGamepadMapping(rule *MappingRule, pressed bool, playerID
 					m.processingMu.Unlock()
 					
 					if !isProcessing {
-						m.handleGamepadMapping(targetRule, pressed, playerID)
+						m.handleGamepadMapping(targetRule, pressed, playerID, depth+1)
 						m.processingMu.Lock()
 						delete(m.processing, targetBtn)
 						m.processingMu.Unlock()
+					} else {
+						m.traceSkip(targetBtn, pressed, SkipCycle, targetRule.ID, depth+1)
+					}
+				} else if targetRule.TargetType == TargetExec {
+					// 目标按键映射到命令
+					if targetRule.Exec != nil && targetRule.Exec.FiresOn(pressed) {
+						m.runExec(targetRule, pressed, depth)
 					}
 				}
 				break
 			}
 		}
+		if !matched {
+			m.traceUnmatchedLocked(targetBtn, pressed, depth)
+		}
 	}
 }
 
+// SetExecAllowed 设置是否允许执行命令规则
+func (m *Mapper) SetExecAllowed(allowed bool) {
+	m.execMu.Lock()
+	defer m.execMu.Unlock()
+	m.execAllowed = allowed
+}
+
+// ExecAllowed 检查是否允许执行命令规则
+func (m *Mapper) ExecAllowed() bool {
+	m.execMu.Lock()
+	defer m.execMu.Unlock()
+	return m.execAllowed
+}
+
 // ReleaseAll 释放所有按键（用于停止映射时）
 func (m *Mapper) ReleaseAll() {
-	m.simulator.ReleaseAllKeys()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.releaseAllLocked()
+}
+
+// ReplaceRules 释放所有按住的键并原子地替换全部规则（用于切换方案）
+func (m *Mapper) ReplaceRules(rules []*MappingRule, axisRules []*AxisRule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.releaseAllLocked()
+
+	m.rules = make([]*MappingRule, len(rules))
+	copy(m.rules, rules)
+	m.axisRules = make([]*AxisRule, len(axisRules))
+	copy(m.axisRules, axisRules)
+	m.axisAcc = axisAccumulator{}
+}
+
+// releaseAllLocked 释放所有按键（调用方需持有写锁）
+func (m *Mapper) releaseAllLocked() {
+	m.reportOutput("keyboard", m.simulator.ReleaseAllKeys())
+	m.trace(TraceEvent{Kind: TraceReleaseAll})
+
+	m.heldMu.Lock()
+	m.held = make(map[gamepad.Button]bool)
+	m.heldMu.Unlock()
+}
+
+// SetOnError 设置模拟输出失败的回调
+//
+// 同一种输出设备连续失败只回调一次，成功后再次失败时重新回调。回调可能在持有引擎锁时调用，不应阻塞。
+func (m *Mapper) SetOnError(callback func(error)) {
+	m.errMu.Lock()
+	defer m.errMu.Unlock()
+	m.onError = callback
+}
+
+// reportOutput 记录一次模拟输出的结果，失败时写日志并通知
+func (m *Mapper) reportOutput(device string, err error) {
+	m.errMu.Lock()
+	if err == nil {
+		delete(m.outputFailed, device)
+		m.errMu.Unlock()
+		return
+	}
+	repeated := m.outputFailed[device]
+	m.outputFailed[device] = true
+	onError := m.onError
+	m.errMu.Unlock()
+
+	if repeated {
+		slog.Debug("simulated output still failing", "device", device, "err", err)
+		return
+	}
+	slog.Error("simulated output failed", "device", device, "err", err)
+	if onError != nil {
+		onError(fmt.Errorf("模拟%s输入失败: %w", deviceNames[device], err))
+	}
+}
+
+// deviceNames 输出设备的显示名称
+var deviceNames = map[string]string{
+	"keyboard":    "键盘",
+	"mouse":       "鼠标",
+	"virtual pad": "虚拟手柄",
 }
 
 // FindRuleBySource 根据源按键查找规则
@@ -199,3 +504,97 @@ func (m *Mapper) GetRuleByID(id string) *MappingRule {
 	}
 	return nil
 }
+
+// AddAxisRule 添加模拟轴映射规则
+func (m *Mapper) AddAxisRule(rule *AxisRule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.axisRules = append(m.axisRules, rule)
+}
+
+// RemoveAxisRule 删除模拟轴映射规则
+func (m *Mapper) RemoveAxisRule(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, rule := range m.axisRules {
+		if rule.ID == id {
+			m.axisRules = append(m.axisRules[:i], m.axisRules[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// GetAxisRules 获取所有模拟轴规则
+func (m *Mapper) GetAxisRules() []*AxisRule {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// 返回副本
+	rules := make([]*AxisRule, len(m.axisRules))
+	copy(rules, m.axisRules)
+	return rules
+}
+
+// SetAxisRules 设置所有模拟轴规则
+func (m *Mapper) SetAxisRules(rules []*AxisRule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rules == nil {
+		rules = make([]*AxisRule, 0)
+	}
+	m.axisRules = rules
+}
+
+// HandleAxes 处理一次轮询的模拟量状态（应在事件循环中逐帧调用）
+func (m *Mapper) HandleAxes(state gamepad.XInputGamepad) {
+	// 大多数方案没有模拟轴规则，先在读锁下检查，避免每次轮询都与按键处理争用写锁
+	m.mu.RLock()
+	idle := !hasEnabledAxisRule(m.axisRules)
+	m.mu.RUnlock()
+	if idle {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var mouseX, mouseY, wheel, wheelH float64
+	for _, rule := range m.axisRules {
+		if !rule.Enabled {
+			continue
+		}
+
+		v := rule.Transform(state.Axis(rule.Source))
+		switch {
+		case rule.Target == AxisTargetMouseX:
+			mouseX += v * rule.scale()
+		case rule.Target == AxisTargetMouseY:
+			mouseY += v * rule.scale()
+		case rule.Target == AxisTargetWheel:
+			wheel += v * rule.scale()
+		case rule.Target == AxisTargetWheelH:
+			wheelH += v * rule.scale()
+		}
+	}
+
+	// 鼠标输出（累积小数部分）
+	dx := take(&m.axisAcc.mouseX, mouseX)
+	dy := take(&m.axisAcc.mouseY, mouseY)
+	m.reportOutput("mouse", errors.Join(
+		m.mouse.Move(dx, -dy), // 屏幕坐标Y轴向下
+		m.mouse.Scroll(take(&m.axisAcc.wheel, wheel)),
+		m.mouse.ScrollHorizontal(take(&m.axisAcc.wheelH, wheelH)),
+	))
+}
+
+// hasEnabledAxisRule 检查是否有启用的模拟轴规则
+func hasEnabledAxisRule(rules []*AxisRule) bool {
+	for _, rule := range rules {
+		if rule.Enabled {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package mapper
+
+import (
+	"reflect"
+	"testing"
+
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/keyboard"
+)
+
+func TestHasConflictIgnoresDisabledRules(t *testing.T) {
+	m, _, _ := newTestMapper()
+	enabled := NewRule("enabled", gamepad.ButtonA, keyboard.KeyCode(0x41), keyboard.Modifiers{})
+	disabled := NewRule("disabled", gamepad.ButtonB, keyboard.KeyCode(0x42), keyboard.Modifiers{})
+	disabled.Enabled = false
+	m.SetRules([]*MappingRule{enabled, disabled})
+
+	tests := []struct {
+		source  gamepad.Button
+		exclude string
+		want    bool
+	}{
+		{gamepad.ButtonA, "", true},
+		{gamepad.ButtonA, "enabled", false}, // 规则自身不冲突
+		{gamepad.ButtonB, "", false},        // 停用的规则不冲突
+		{gamepad.ButtonX, "", false},
+	}
+	for _, tt := range tests {
+		if got := m.HasConflict(tt.source, tt.exclude); got != tt.want {
+			t.Errorf("HasConflict(%s, %q) = %v, want %v", tt.source.Name(), tt.exclude, got, tt.want)
+		}
+	}
+}
+
+func TestHandleEventUsesFirstEnabledRule(t *testing.T) {
+	m, keys, _ := newTestMapper()
+	first := NewRule("first", gamepad.ButtonA, keyboard.KeyCode(0x41), keyboard.Modifiers{})
+	second := NewRule("second", gamepad.ButtonA, keyboard.KeyCode(0x42), keyboard.Modifiers{})
+	m.SetRules([]*MappingRule{first, second})
+
+	tap := func() {
+		m.HandleEvent(gamepad.ButtonEvent{Button: gamepad.ButtonA, Pressed: true})
+		m.HandleEvent(gamepad.ButtonEvent{Button: gamepad.ButtonA, Pressed: false})
+	}
+
+	// 两条规则都启用时（如手动编辑的配置），排在前面的生效
+	tap()
+	// 调整顺序后另一条规则生效
+	m.MoveRule("second", -1)
+	tap()
+	// 停用排在前面的规则后，下一条启用的规则生效
+	m.SetRuleEnabled("second", false)
+	tap()
+
+	want := []string{"down A", "up A", "down B", "up B", "down A", "up A"}
+	if got := keys.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func TestSetRuleEnabledReplacesRule(t *testing.T) {
+	m, _, _ := newTestMapper()
+	rule := NewRule("a", gamepad.ButtonA, keyboard.KeyCode(0x41), keyboard.Modifiers{})
+	m.SetRules([]*MappingRule{rule})
+
+	before := m.GetRules()[0]
+	if !m.SetRuleEnabled("a", false) {
+		t.Fatal("SetRuleEnabled returned false")
+	}
+	// 之前取得的规则指针可能正被界面或配置保存在锁外读取，不能被原地修改
+	if !before.Enabled {
+		t.Error("SetRuleEnabled modified the rule returned by GetRules in place")
+	}
+	if got := m.GetRuleByID("a"); got == nil || got.Enabled {
+		t.Errorf("GetRuleByID after disable = %+v, want disabled rule", got)
+	}
+	if m.SetRuleEnabled("missing", true) {
+		t.Error("SetRuleEnabled(missing) returned true")
+	}
+}
@@ -1,6 +1,8 @@
 package mapper
 
 import (
+	"encoding/json"
+	"fmt"
 	"strings"
 
 	"gamepad-key-mapper/internal/gamepad"
@@ -13,14 +15,85 @@ type TargetType int
 const (
 	TargetKeyboard TargetType = iota // 目标是键盘按键
 	TargetGamepad                    // 目标是手柄按键（内部转发）
+	TargetExec                       // 目标是执行命令/启动程序/打开URL
 )
 
+// targetTypeNames 目标类型的规范名称（用于配置文件）
+var targetTypeNames = map[TargetType]string{
+	TargetKeyboard: "keyboard",
+	TargetGamepad:  "gamepad",
+	TargetExec:     "exec",
+}
+
+// targetTypeAliases 目标类型别名
+var targetTypeAliases = map[string]TargetType{
+	"key":        TargetKeyboard,
+	"keys":       TargetKeyboard,
+	"kbd":        TargetKeyboard,
+	"pad":        TargetGamepad,
+	"controller": TargetGamepad,
+	"command":    TargetExec,
+	"run":        TargetExec,
+}
+
+// ParseTargetType 解析目标类型名称（忽略大小写，支持别名）
+func ParseTargetType(s string) (TargetType, error) {
+	key := strings.ToLower(strings.TrimSpace(s))
+	for t, name := range targetTypeNames {
+		if name == key {
+			return t, nil
+		}
+	}
+	if t, ok := targetTypeAliases[key]; ok {
+		return t, nil
+	}
+	return 0, fmt.Errorf("unknown target type %q", s)
+}
+
+// MarshalText 以规范名称序列化
+func (t TargetType) MarshalText() ([]byte, error) {
+	name, ok := targetTypeNames[t]
+	if !ok {
+		return nil, fmt.Errorf("unknown target type %d", int(t))
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText 从名称反序列化
+func (t *TargetType) UnmarshalText(text []byte) error {
+	parsed, err := ParseTargetType(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// UnmarshalJSON 同时支持名称字符串和旧版数值格式
+func (t *TargetType) UnmarshalJSON(data []byte) error {
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		if _, ok := targetTypeNames[TargetType(n)]; !ok {
+			return fmt.Errorf("unknown target type %d", n)
+		}
+		*t = TargetType(n)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("target type must be a name or number: %s", data)
+	}
+	return t.UnmarshalText([]byte(s))
+}
+
 // MappingRule 定义一条从手柄按键到目标的映射规则
 type MappingRule struct {
-	ID         string             `json:"id"`          // 唯一标识
-	Name       string             `json:"name"`        // 规则名称（可选）
-	SourceKey  gamepad.Button     `json:"source_key"`  // 源按键（手柄）
-	TargetType TargetType         `json:"target_type"` // 目标类型
+	ID          string         `json:"id"`                    // 唯一标识
+	Name        string         `json:"name"`                  // 规则名称（可选）
+	Description string         `json:"description,omitempty"` // 规则说明（可选）
+	SourceKey   gamepad.Button `json:"source_key"`            // 源按键（手柄）
+	TargetType  TargetType     `json:"target_type"`           // 目标类型
 	
 	// 键盘目标（当 TargetType == TargetKeyboard）
 	TargetKeys []keyboard.KeyCode `json:"target_keys"` // 目标按键（键盘，支持多键）
@@ -28,7 +101,10 @@ type MappingRule struct {
 	
 	// 手柄目标（当 TargetType == TargetGamepad）
 	TargetButtons []gamepad.Button `json:"target_buttons"` // 目标按键（手柄，支持多键）
-	
+
+	// 命令目标（当 TargetType == TargetExec）
+	Exec *ExecAction `json:"exec,omitempty"`
+
 	Enabled bool `json:"enabled"` // 是否启用
 }
 
@@ -67,6 +143,17 @@ func NewRuleGamepad(id string, source gamepad.Button, targets []gamepad.Button)
 	}
 }
 
+// NewRuleExec 创建一个命令执行规则
+func NewRuleExec(id string, source gamepad.Button, action *ExecAction) *MappingRule {
+	return &MappingRule{
+		ID:         id,
+		SourceKey:  source,
+		TargetType: TargetExec,
+		Exec:       action,
+		Enabled:    true,
+	}
+}
+
 // String 返回规则的可读描述
 func (r *MappingRule) String() string {
 	sourceStr := r.SourceKey.String()
@@ -79,6 +166,14 @@ func (r *MappingRule) String() string {
 		}
 		return sourceStr + " → 🎮 " + strings.Join(btnNames, "+")
 	}
+
+	if r.TargetType == TargetExec {
+		// 命令执行
+		if r.Exec == nil {
+			return sourceStr + " → ▶ (空)"
+		}
+		return sourceStr + " → ▶ " + r.Exec.String()
+	}
 	
 	// 键盘映射
 	modStr := ""
@@ -114,6 +209,11 @@ func (r *MappingRule) IsGamepadMapping() bool {
 	return r.TargetType == TargetGamepad
 }
 
+// IsExecMapping 检查是否为命令执行映射
+func (r *MappingRule) IsExecMapping() bool {
+	return r.TargetType == TargetExec
+}
+
 // GetFirstTargetKey 获取第一个目标键（兼容旧代码）
 func (r *MappingRule) GetFirstTargetKey() keyboard.KeyCode {
 	if len(r.TargetKeys) > 0 {
@@ -124,8 +224,12 @@ func (r *MappingRule) GetFirstTargetKey() keyboard.KeyCode {
 
 // HasMultipleTargets 检查是否有多个目标
 func (r *MappingRule) HasMultipleTargets() bool {
-	if r.TargetType == TargetKeyboard {
+	switch r.TargetType {
+	case TargetKeyboard:
 		return len(r.TargetKeys) > 1
+	case TargetGamepad:
+		return len(r.TargetButtons) > 1
+	default:
+		return false
 	}
-	return len(r.TargetButtons) > 1
 }
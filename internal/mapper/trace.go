@@ -0,0 +1,158 @@
+package mapper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/keyboard"
+)
+
+// TraceKind 跟踪事件类型
+type TraceKind int
+
+const (
+	TraceInput      TraceKind = iota // 收到手柄按键事件
+	TraceMatch                       // 按键匹配到规则
+	TraceSkip                        // 按键没有触发规则（原因见 Reason）
+	TraceKeyDown                     // 模拟按下键盘按键
+	TraceKeyUp                       // 模拟释放键盘按键
+	TraceExec                        // 执行命令
+	TraceReleaseAll                  // 释放所有按键
+)
+
+// String 返回跟踪事件类型名称
+func (k TraceKind) String() string {
+	switch k {
+	case TraceInput:
+		return "输入"
+	case TraceMatch:
+		return "匹配"
+	case TraceSkip:
+		return "未触发"
+	case TraceKeyDown:
+		return "按下"
+	case TraceKeyUp:
+		return "释放"
+	case TraceExec:
+		return "命令"
+	case TraceReleaseAll:
+		return "全部释放"
+	default:
+		return "未知"
+	}
+}
+
+// SkipReason 按键没有触发规则的原因
+type SkipReason int
+
+const (
+	SkipNone         SkipReason = iota
+	SkipNoRule                  // 没有该按键的规则
+	SkipDisabled                // 规则已停用
+	SkipCycle                   // 循环映射保护
+	SkipUnpaired                // 释放时没有对应的按下（按下后规则被修改）
+	SkipExecDisabled            // 配置未允许执行命令
+)
+
+// String 返回原因说明
+func (r SkipReason) String() string {
+	switch r {
+	case SkipNone:
+		return ""
+	case SkipNoRule:
+		return "没有规则"
+	case SkipDisabled:
+		return "规则已停用"
+	case SkipCycle:
+		return "循环映射保护"
+	case SkipUnpaired:
+		return "没有对应的按下"
+	case SkipExecDisabled:
+		return "未允许执行命令"
+	default:
+		return "未知原因"
+	}
+}
+
+// TraceEvent 映射引擎的跟踪事件
+type TraceEvent struct {
+	Time      time.Time
+	Kind      TraceKind
+	Button    gamepad.Button     // 输入、匹配、未触发事件的手柄按键
+	Pressed   bool               // 按下或释放
+	RuleID    string             // 相关的规则
+	Keys      []keyboard.KeyCode // 模拟的键盘按键
+	Modifiers keyboard.Modifiers // 模拟的修饰键
+	Reason    SkipReason         // 未触发的原因
+	Depth     int                // 手柄到手柄映射的层级（0 表示直接来自手柄）
+}
+
+// String 返回一行可读的描述
+func (e TraceEvent) String() string {
+	var detail string
+	switch e.Kind {
+	case TraceInput:
+		detail = fmt.Sprintf("%s %s", e.Button, pressedText(e.Pressed))
+	case TraceMatch:
+		detail = fmt.Sprintf("%s %s → 规则 %s", e.Button, pressedText(e.Pressed), e.RuleID)
+	case TraceSkip:
+		detail = fmt.Sprintf("%s %s：%s", e.Button, pressedText(e.Pressed), e.Reason)
+		if e.RuleID != "" {
+			detail += "（规则 " + e.RuleID + "）"
+		}
+	case TraceKeyDown, TraceKeyUp:
+		detail = keyboard.FormatShortcut(e.Keys, e.Modifiers)
+	case TraceExec:
+		detail = "规则 " + e.RuleID
+	}
+
+	text := e.Time.Format("15:04:05.000") + " " + strings.Repeat("  ", e.Depth) + e.Kind.String()
+	if detail != "" {
+		text += " " + detail
+	}
+	return text
+}
+
+// pressedText 返回按下/释放的文字
+func pressedText(pressed bool) string {
+	if pressed {
+		return "按下"
+	}
+	return "释放"
+}
+
+// Tracer 跟踪事件观察者
+//
+// Trace 在映射引擎处理事件的协程中同步调用（可能持有引擎的锁），不应阻塞，也不应调用 Mapper 的方法。
+type Tracer interface {
+	Trace(event TraceEvent)
+}
+
+// TracerFunc 将函数用作 Tracer
+type TracerFunc func(event TraceEvent)
+
+// Trace 实现 Tracer
+func (f TracerFunc) Trace(event TraceEvent) {
+	f(event)
+}
+
+// SetTracer 设置跟踪事件观察者（nil 表示不跟踪）
+func (m *Mapper) SetTracer(tracer Tracer) {
+	m.tracerMu.Lock()
+	defer m.tracerMu.Unlock()
+	m.tracer = tracer
+}
+
+// trace 发送跟踪事件
+func (m *Mapper) trace(event TraceEvent) {
+	m.tracerMu.Lock()
+	tracer := m.tracer
+	m.tracerMu.Unlock()
+
+	if tracer != nil {
+		event.Time = time.Now()
+		tracer.Trace(event)
+	}
+}
@@ -0,0 +1,199 @@
+package mapper
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/keyboard"
+)
+
+// traceRecorder 记录跟踪事件（清除时间以便比较）
+type traceRecorder struct {
+	mu     sync.Mutex
+	events []TraceEvent
+}
+
+func (r *traceRecorder) tracer() Tracer {
+	return TracerFunc(func(event TraceEvent) {
+		if event.Time.IsZero() {
+			panic("trace event without time")
+		}
+		event.Time = time.Time{}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events = append(r.events, event)
+	})
+}
+
+func (r *traceRecorder) Events() []TraceEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]TraceEvent(nil), r.events...)
+}
+
+func TestHandleEventTrace(t *testing.T) {
+	keyA := []keyboard.KeyCode{0x41}
+	disabled := NewRule("off", gamepad.ButtonA, 0x41, keyboard.Modifiers{})
+	disabled.Enabled = false
+
+	press := func(b gamepad.Button) gamepad.ButtonEvent { return gamepad.ButtonEvent{Button: b, Pressed: true} }
+	release := func(b gamepad.Button) gamepad.ButtonEvent { return gamepad.ButtonEvent{Button: b} }
+	input := func(b gamepad.Button, pressed bool) TraceEvent {
+		return TraceEvent{Kind: TraceInput, Button: b, Pressed: pressed}
+	}
+
+	tests := []struct {
+		name   string
+		rules  []*MappingRule
+		events []gamepad.ButtonEvent
+		want   []TraceEvent
+	}{
+		{
+			name:   "match",
+			rules:  []*MappingRule{NewRule("r1", gamepad.ButtonA, 0x41, keyboard.Modifiers{})},
+			events: []gamepad.ButtonEvent{press(gamepad.ButtonA), release(gamepad.ButtonA)},
+			want: []TraceEvent{
+				input(gamepad.ButtonA, true),
+				{Kind: TraceMatch, Button: gamepad.ButtonA, Pressed: true, RuleID: "r1"},
+				{Kind: TraceKeyDown, RuleID: "r1", Keys: keyA},
+				input(gamepad.ButtonA, false),
+				{Kind: TraceMatch, Button: gamepad.ButtonA, Pressed: false, RuleID: "r1"},
+				{Kind: TraceKeyUp, RuleID: "r1", Keys: keyA},
+			},
+		},
+		{
+			name:   "no rule",
+			events: []gamepad.ButtonEvent{press(gamepad.ButtonX), release(gamepad.ButtonX)},
+			want: []TraceEvent{
+				input(gamepad.ButtonX, true),
+				{Kind: TraceSkip, Button: gamepad.ButtonX, Pressed: true, Reason: SkipNoRule},
+				input(gamepad.ButtonX, false),
+				{Kind: TraceSkip, Button: gamepad.ButtonX, Pressed: false, Reason: SkipNoRule},
+			},
+		},
+		{
+			name:   "disabled",
+			rules:  []*MappingRule{disabled},
+			events: []gamepad.ButtonEvent{press(gamepad.ButtonA)},
+			want: []TraceEvent{
+				input(gamepad.ButtonA, true),
+				{Kind: TraceSkip, Button: gamepad.ButtonA, Pressed: true, Reason: SkipDisabled, RuleID: "off"},
+			},
+		},
+		{
+			name:   "unpaired release",
+			rules:  []*MappingRule{NewRule("r1", gamepad.ButtonA, 0x41, keyboard.Modifiers{})},
+			events: []gamepad.ButtonEvent{release(gamepad.ButtonA)},
+			want: []TraceEvent{
+				input(gamepad.ButtonA, false),
+				{Kind: TraceSkip, Button: gamepad.ButtonA, Pressed: false, Reason: SkipUnpaired},
+			},
+		},
+		{
+			name: "chain",
+			rules: []*MappingRule{
+				NewRuleGamepad("ab", gamepad.ButtonA, []gamepad.Button{gamepad.ButtonB, gamepad.ButtonY}),
+				NewRule("b", gamepad.ButtonB, 0x41, keyboard.Modifiers{}),
+			},
+			events: []gamepad.ButtonEvent{press(gamepad.ButtonA)},
+			want: []TraceEvent{
+				input(gamepad.ButtonA, true),
+				{Kind: TraceMatch, Button: gamepad.ButtonA, Pressed: true, RuleID: "ab"},
+				{Kind: TraceMatch, Button: gamepad.ButtonB, Pressed: true, RuleID: "b", Depth: 1},
+				{Kind: TraceKeyDown, RuleID: "b", Keys: keyA, Depth: 1},
+				{Kind: TraceSkip, Button: gamepad.ButtonY, Pressed: true, Reason: SkipNoRule, Depth: 1},
+			},
+		},
+		{
+			name: "cycle guard",
+			rules: []*MappingRule{
+				NewRuleGamepad("ab", gamepad.ButtonA, []gamepad.Button{gamepad.ButtonB}),
+				NewRuleGamepad("ba", gamepad.ButtonB, []gamepad.Button{gamepad.ButtonA}),
+			},
+			events: []gamepad.ButtonEvent{press(gamepad.ButtonA)},
+			want: []TraceEvent{
+				input(gamepad.ButtonA, true),
+				{Kind: TraceMatch, Button: gamepad.ButtonA, Pressed: true, RuleID: "ab"},
+				{Kind: TraceMatch, Button: gamepad.ButtonB, Pressed: true, RuleID: "ba", Depth: 1},
+				{Kind: TraceMatch, Button: gamepad.ButtonA, Pressed: true, RuleID: "ab", Depth: 2},
+				{Kind: TraceSkip, Button: gamepad.ButtonA, Pressed: true, Reason: SkipCycle, RuleID: "ab", Depth: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _, _ := newTestMapper()
+			m.SetRules(tt.rules)
+			rec := &traceRecorder{}
+			m.SetTracer(rec.tracer())
+
+			for _, event := range tt.events {
+				m.HandleEvent(event)
+			}
+			if got := rec.Events(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("trace:\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleEventTopLevelCycleGuard(t *testing.T) {
+	m, keys, _ := newTestMapper()
+	m.SetRules([]*MappingRule{NewRule("r1", gamepad.ButtonA, 0x41, keyboard.Modifiers{})})
+	rec := &traceRecorder{}
+	m.SetTracer(rec.tracer())
+
+	// 源按键正作为手柄映射的目标处理时，直接收到的同一按键被忽略
+	m.processing[gamepad.ButtonA] = true
+	m.HandleEvent(gamepad.ButtonEvent{Button: gamepad.ButtonA, Pressed: true})
+
+	want := []TraceEvent{
+		{Kind: TraceInput, Button: gamepad.ButtonA, Pressed: true},
+		{Kind: TraceSkip, Button: gamepad.ButtonA, Pressed: true, Reason: SkipCycle},
+	}
+	if got := rec.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("trace:\n got %+v\nwant %+v", got, want)
+	}
+	if len(keys.Events()) != 0 {
+		t.Errorf("keyboard output = %q, want none", keys.Events())
+	}
+}
+
+func TestSetTracerNil(t *testing.T) {
+	m, keys, _ := newTestMapper()
+	m.SetRules([]*MappingRule{NewRule("r1", gamepad.ButtonA, 0x41, keyboard.Modifiers{})})
+	rec := &traceRecorder{}
+	m.SetTracer(rec.tracer())
+	m.SetTracer(nil)
+
+	m.HandleEvent(gamepad.ButtonEvent{Button: gamepad.ButtonA, Pressed: true})
+	if len(rec.Events()) != 0 {
+		t.Errorf("removed tracer received %d events", len(rec.Events()))
+	}
+	if len(keys.Events()) != 1 {
+		t.Errorf("keyboard output = %q, want one key down", keys.Events())
+	}
+}
+
+func TestTraceEventString(t *testing.T) {
+	at := time.Date(2024, 1, 1, 12, 30, 45, 123e6, time.UTC)
+	tests := []struct {
+		event TraceEvent
+		want  string
+	}{
+		{TraceEvent{Kind: TraceInput, Button: gamepad.ButtonA, Pressed: true}, "12:30:45.123 输入 A 按下"},
+		{TraceEvent{Kind: TraceSkip, Button: gamepad.ButtonA, Reason: SkipDisabled, RuleID: "r1"}, "12:30:45.123 未触发 A 释放：规则已停用（规则 r1）"},
+		{TraceEvent{Kind: TraceKeyDown, Keys: []keyboard.KeyCode{0x43}, Modifiers: keyboard.Modifiers{Ctrl: true}, Depth: 1}, "12:30:45.123   按下 Ctrl+C"},
+		{TraceEvent{Kind: TraceReleaseAll}, "12:30:45.123 全部释放"},
+	}
+	for _, tt := range tests {
+		tt.event.Time = at
+		if got := tt.event.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
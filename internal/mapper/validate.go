@@ -0,0 +1,267 @@
+package mapper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gamepad-key-mapper/internal/gamepad"
+)
+
+// Severity 诊断级别
+type Severity int
+
+const (
+	SeverityWarning Severity = iota // 规则可以运行，但可能不符合预期
+	SeverityError                   // 规则无法正常工作
+)
+
+// String 返回诊断级别名称
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "警告"
+	case SeverityError:
+		return "错误"
+	default:
+		return "未知"
+	}
+}
+
+// MarshalText 诊断级别以英文名称序列化
+func (s Severity) MarshalText() ([]byte, error) {
+	switch s {
+	case SeverityWarning:
+		return []byte("warning"), nil
+	case SeverityError:
+		return []byte("error"), nil
+	default:
+		return nil, fmt.Errorf("unknown severity %d", int(s))
+	}
+}
+
+// DiagnosticCode 诊断类型（稳定的英文标识，便于程序处理）
+type DiagnosticCode string
+
+const (
+	CodeCycle          DiagnosticCode = "cycle"           // 手柄到手柄映射形成循环
+	CodeDanglingTarget DiagnosticCode = "dangling_target" // 目标手柄按键没有映射规则
+	CodeEmptyTarget    DiagnosticCode = "empty_target"    // 没有目标
+	CodeInvalidKey     DiagnosticCode = "invalid_key"     // 按键码超出虚拟键码范围
+	CodeUnknownKey     DiagnosticCode = "unknown_key"     // 按键码没有对应的按键名称
+	CodeNeverFires     DiagnosticCode = "never_fires"     // 命令在按下和释放时都不执行
+	CodeInvalidTarget  DiagnosticCode = "invalid_target"  // 模拟量规则的输出目标不受支持
+)
+
+// Diagnostic 规则检查结果
+type Diagnostic struct {
+	RuleID   string         `json:"rule_id"`
+	Severity Severity       `json:"severity"`
+	Code     DiagnosticCode `json:"code"`
+	Message  string         `json:"message"`
+}
+
+// String 返回诊断的可读描述
+func (d Diagnostic) String() string {
+	return "[" + d.Severity.String() + "] " + d.RuleID + ": " + d.Message
+}
+
+// HasErrors 检查诊断结果中是否有错误级别的问题
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// DiagnosticsFor 筛选指定规则的诊断结果
+func DiagnosticsFor(diags []Diagnostic, ruleID string) []Diagnostic {
+	var result []Diagnostic
+	for _, d := range diags {
+		if d.RuleID == ruleID {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// ValidateRules 静态检查一组规则
+//
+// 检查内容：没有目标的规则、无效或未知的按键码、指向没有映射规则的手柄按键，
+// 以及手柄到手柄映射形成的循环（运行时循环保护会让这些规则静默失效）。
+// 循环和转发只考虑已启用的规则，与 HandleEvent 的匹配方式一致。
+func ValidateRules(rules []*MappingRule) []Diagnostic {
+	var diags []Diagnostic
+	add := func(rule *MappingRule, severity Severity, code DiagnosticCode, format string, args ...any) {
+		diags = append(diags, Diagnostic{
+			RuleID:   rule.ID,
+			Severity: severity,
+			Code:     code,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	// 每个源按键实际生效的规则（第一条启用的规则）
+	active := make(map[gamepad.Button]*MappingRule)
+	for _, rule := range rules {
+		if rule != nil && rule.Enabled {
+			if _, ok := active[rule.SourceKey]; !ok {
+				active[rule.SourceKey] = rule
+			}
+		}
+	}
+
+	for _, rule := range rules {
+		if rule == nil {
+			continue
+		}
+
+		switch rule.TargetType {
+		case TargetKeyboard:
+			if len(rule.TargetKeys) == 0 {
+				add(rule, SeverityError, CodeEmptyTarget, "没有目标按键")
+			}
+			for _, key := range rule.TargetKeys {
+				if !key.IsValid() {
+					add(rule, SeverityError, CodeInvalidKey, "无效的按键码 %d", int(key))
+				} else if !key.IsNamed() {
+					add(rule, SeverityWarning, CodeUnknownKey, "未知按键 %s", key.Name())
+				}
+			}
+
+		case TargetGamepad:
+			if len(rule.TargetButtons) == 0 {
+				add(rule, SeverityError, CodeEmptyTarget, "没有目标手柄按键")
+			}
+			if !rule.Enabled {
+				continue
+			}
+			for _, target := range rule.TargetButtons {
+				if target == rule.SourceKey {
+					continue // 由循环检查报告
+				}
+				if active[target] == nil {
+					add(rule, SeverityWarning, CodeDanglingTarget,
+						"目标按键 %s 没有映射规则，不会产生任何输出", target.String())
+				}
+			}
+
+		case TargetExec:
+			if rule.Exec == nil || strings.TrimSpace(rule.Exec.Command) == "" {
+				add(rule, SeverityError, CodeEmptyTarget, "命令为空")
+			} else if !rule.Exec.OnPress && !rule.Exec.OnRelease {
+				add(rule, SeverityWarning, CodeNeverFires, "按下和释放时都不会执行")
+			}
+		}
+	}
+
+	for _, cycle := range findCycles(active) {
+		path := make([]string, 0, len(cycle)+1)
+		for _, rule := range cycle {
+			path = append(path, rule.SourceKey.String())
+		}
+		path = append(path, cycle[0].SourceKey.String())
+		for i, rule := range cycle {
+			next := cycle[(i+1)%len(cycle)].SourceKey
+			if hasOtherTarget(rule, next) {
+				// 运行时只跳过形成循环的分支，其他目标照常输出
+				add(rule, SeverityError, CodeCycle, "循环映射 %s，目标按键 %s 不会产生输出",
+					strings.Join(path, " → "), next.String())
+			} else {
+				add(rule, SeverityError, CodeCycle, "循环映射 %s，按下时不会产生任何输出", strings.Join(path, " → "))
+			}
+		}
+	}
+
+	return diags
+}
+
+// ValidateAxisRules 静态检查一组模拟轴规则
+//
+// 输出目标不受支持的已启用规则（如旧版本保存的虚拟手柄输出）不会产生任何输出，报告为错误。
+func ValidateAxisRules(rules []*AxisRule) []Diagnostic {
+	var diags []Diagnostic
+	for _, rule := range rules {
+		if rule == nil || !rule.Enabled {
+			continue
+		}
+		if !rule.Target.IsValid() {
+			diags = append(diags, Diagnostic{
+				RuleID:   rule.ID,
+				Severity: SeverityError,
+				Code:     CodeInvalidTarget,
+				Message:  fmt.Sprintf("不支持的输出目标 %d，不会产生任何输出", int(rule.Target)),
+			})
+		}
+	}
+	return diags
+}
+
+// hasOtherTarget 检查规则除 target 外是否还有其他目标按键
+func hasOtherTarget(rule *MappingRule, target gamepad.Button) bool {
+	for _, b := range rule.TargetButtons {
+		if b != target {
+			return true
+		}
+	}
+	return false
+}
+
+// findCycles 在手柄到手柄映射构成的图中查找循环（每个循环只报告一次）
+func findCycles(active map[gamepad.Button]*MappingRule) [][]*MappingRule {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+
+	// 按源按键排序遍历，保证结果稳定
+	var sources []gamepad.Button
+	for source, rule := range active {
+		if rule.TargetType == TargetGamepad {
+			sources = append(sources, source)
+		}
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i] < sources[j] })
+
+	state := make(map[gamepad.Button]int)
+	var stack []*MappingRule
+	var cycles [][]*MappingRule
+
+	var visit func(rule *MappingRule)
+	visit = func(rule *MappingRule) {
+		state[rule.SourceKey] = visiting
+		stack = append(stack, rule)
+
+		for _, target := range rule.TargetButtons {
+			next := active[target]
+			if next == nil || next.TargetType != TargetGamepad {
+				continue
+			}
+			switch state[target] {
+			case unvisited:
+				visit(next)
+			case visiting:
+				// 栈中从 target 开始到当前规则为一个循环
+				for i, r := range stack {
+					if r.SourceKey == target {
+						cycles = append(cycles, append([]*MappingRule(nil), stack[i:]...))
+						break
+					}
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[rule.SourceKey] = done
+	}
+
+	for _, source := range sources {
+		if state[source] == unvisited {
+			visit(active[source])
+		}
+	}
+	return cycles
+}
@@ -0,0 +1,150 @@
+package mapper
+
+import (
+	"reflect"
+	"testing"
+
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/keyboard"
+)
+
+func TestValidateRules(t *testing.T) {
+	a, b, x, y := gamepad.ButtonA, gamepad.ButtonB, gamepad.ButtonX, gamepad.ButtonY
+	key := NewRule
+	pad := NewRuleGamepad
+	disabled := func(rule *MappingRule) *MappingRule {
+		rule.Enabled = false
+		return rule
+	}
+
+	tests := []struct {
+		name  string
+		rules []*MappingRule
+		want  []Diagnostic
+	}{
+		{
+			name: "valid chain",
+			rules: []*MappingRule{
+				pad("ab", a, []gamepad.Button{b}),
+				key("b", b, keyboard.KeyCode(0x41), keyboard.Modifiers{}),
+			},
+		},
+		{
+			name: "empty keyboard target",
+			rules: []*MappingRule{
+				NewRuleMultiKeys("k", a, nil, keyboard.Modifiers{}),
+			},
+			want: []Diagnostic{
+				{RuleID: "k", Severity: SeverityError, Code: CodeEmptyTarget, Message: "没有目标按键"},
+			},
+		},
+		{
+			name: "empty gamepad target",
+			rules: []*MappingRule{
+				pad("p", a, nil),
+			},
+			want: []Diagnostic{
+				{RuleID: "p", Severity: SeverityError, Code: CodeEmptyTarget, Message: "没有目标手柄按键"},
+			},
+		},
+		{
+			name: "empty command",
+			rules: []*MappingRule{
+				NewRuleExec("e", a, &ExecAction{Mode: ExecShell, Command: " ", OnPress: true}),
+				NewRuleExec("nil", b, nil),
+			},
+			want: []Diagnostic{
+				{RuleID: "e", Severity: SeverityError, Code: CodeEmptyTarget, Message: "命令为空"},
+				{RuleID: "nil", Severity: SeverityError, Code: CodeEmptyTarget, Message: "命令为空"},
+			},
+		},
+		{
+			name: "dangling target",
+			rules: []*MappingRule{
+				pad("ab", a, []gamepad.Button{b}),
+			},
+			want: []Diagnostic{
+				{RuleID: "ab", Severity: SeverityWarning, Code: CodeDanglingTarget, Message: "目标按键 B 没有映射规则，不会产生任何输出"},
+			},
+		},
+		{
+			name: "target mapped only by disabled rule",
+			rules: []*MappingRule{
+				pad("ab", a, []gamepad.Button{b}),
+				disabled(key("b", b, keyboard.KeyCode(0x41), keyboard.Modifiers{})),
+			},
+			want: []Diagnostic{
+				{RuleID: "ab", Severity: SeverityWarning, Code: CodeDanglingTarget, Message: "目标按键 B 没有映射规则，不会产生任何输出"},
+			},
+		},
+		{
+			name: "disabled rule is not checked for dangling targets",
+			rules: []*MappingRule{
+				disabled(pad("ab", a, []gamepad.Button{b})),
+			},
+		},
+		{
+			name: "self cycle",
+			rules: []*MappingRule{
+				pad("aa", a, []gamepad.Button{a}),
+			},
+			want: []Diagnostic{
+				{RuleID: "aa", Severity: SeverityError, Code: CodeCycle, Message: "循环映射 A → A，按下时不会产生任何输出"},
+			},
+		},
+		{
+			name: "two rule cycle",
+			rules: []*MappingRule{
+				pad("ab", a, []gamepad.Button{b}),
+				pad("ba", b, []gamepad.Button{a}),
+			},
+			want: []Diagnostic{
+				{RuleID: "ab", Severity: SeverityError, Code: CodeCycle, Message: "循环映射 A → B → A，按下时不会产生任何输出"},
+				{RuleID: "ba", Severity: SeverityError, Code: CodeCycle, Message: "循环映射 A → B → A，按下时不会产生任何输出"},
+			},
+		},
+		{
+			name: "cycle with other target",
+			rules: []*MappingRule{
+				pad("ab", a, []gamepad.Button{b, x}),
+				pad("ba", b, []gamepad.Button{a}),
+				key("x", x, keyboard.KeyCode(0x41), keyboard.Modifiers{}),
+			},
+			want: []Diagnostic{
+				{RuleID: "ab", Severity: SeverityError, Code: CodeCycle, Message: "循环映射 A → B → A，目标按键 B 不会产生输出"},
+				{RuleID: "ba", Severity: SeverityError, Code: CodeCycle, Message: "循环映射 A → B → A，按下时不会产生任何输出"},
+			},
+		},
+		{
+			name: "cycle reported once",
+			rules: []*MappingRule{
+				pad("ab", a, []gamepad.Button{b}),
+				pad("by", b, []gamepad.Button{y}),
+				pad("ya", y, []gamepad.Button{a}),
+				pad("xa", x, []gamepad.Button{a}),
+			},
+			want: []Diagnostic{
+				{RuleID: "ab", Severity: SeverityError, Code: CodeCycle, Message: "循环映射 A → B → Y → A，按下时不会产生任何输出"},
+				{RuleID: "by", Severity: SeverityError, Code: CodeCycle, Message: "循环映射 A → B → Y → A，按下时不会产生任何输出"},
+				{RuleID: "ya", Severity: SeverityError, Code: CodeCycle, Message: "循环映射 A → B → Y → A，按下时不会产生任何输出"},
+			},
+		},
+		{
+			name: "disabled rule breaks cycle",
+			rules: []*MappingRule{
+				pad("ab", a, []gamepad.Button{b}),
+				disabled(pad("ba", b, []gamepad.Button{a})),
+				key("b", b, keyboard.KeyCode(0x41), keyboard.Modifiers{}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateRules(tt.rules)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateRules() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
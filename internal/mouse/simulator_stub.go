@@ -0,0 +1,26 @@
+//go:build !windows
+
+package mouse
+
+// Simulator 鼠标模拟器（非Windows平台存根）
+type Simulator struct{}
+
+// NewSimulator 创建鼠标模拟器
+func NewSimulator() (*Simulator, error) {
+	return &Simulator{}, nil
+}
+
+// Move 相对移动鼠标（存根）
+func (s *Simulator) Move(dx, dy int) error {
+	return nil
+}
+
+// Scroll 垂直滚动滚轮（存根）
+func (s *Simulator) Scroll(delta int) error {
+	return nil
+}
+
+// ScrollHorizontal 水平滚动滚轮（存根）
+func (s *Simulator) ScrollHorizontal(delta int) error {
+	return nil
+}
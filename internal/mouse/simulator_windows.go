@@ -0,0 +1,123 @@
+//go:build windows
+
+package mouse
+
+import (
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	user32        *syscall.DLL
+	procSendInput *syscall.Proc
+	mouseInitOnce sync.Once
+	mouseInitErr  error
+)
+
+// INPUT 结构体类型
+const (
+	INPUT_MOUSE = 0
+)
+
+// MOUSEEVENTF 标志
+const (
+	MOUSEEVENTF_MOVE   = 0x0001
+	MOUSEEVENTF_WHEEL  = 0x0800
+	MOUSEEVENTF_HWHEEL = 0x1000
+)
+
+// INPUT 结构体
+type INPUT struct {
+	Type uint32
+	Mi   MOUSEINPUT
+}
+
+// MOUSEINPUT 结构体
+type MOUSEINPUT struct {
+	Dx        int32
+	Dy        int32
+	MouseData uint32
+	Flags     uint32
+	Time      uint32
+	ExtraInfo uintptr
+}
+
+// Simulator 鼠标模拟器
+type Simulator struct {
+	mu sync.Mutex
+}
+
+// NewSimulator 创建鼠标模拟器
+func NewSimulator() (*Simulator, error) {
+	mouseInitOnce.Do(func() {
+		user32, mouseInitErr = syscall.LoadDLL("user32.dll")
+		if mouseInitErr != nil {
+			return
+		}
+		procSendInput, mouseInitErr = user32.FindProc("SendInput")
+	})
+
+	if mouseInitErr != nil {
+		return nil, mouseInitErr
+	}
+
+	return &Simulator{}, nil
+}
+
+// Move 相对移动鼠标
+func (s *Simulator) Move(dx, dy int) error {
+	if dx == 0 && dy == 0 {
+		return nil
+	}
+	return s.send(MOUSEINPUT{
+		Dx:    int32(dx),
+		Dy:    int32(dy),
+		Flags: MOUSEEVENTF_MOVE,
+	})
+}
+
+// Scroll 垂直滚动滚轮（正值向上，120 为一格）
+func (s *Simulator) Scroll(delta int) error {
+	if delta == 0 {
+		return nil
+	}
+	return s.send(MOUSEINPUT{
+		MouseData: uint32(int32(delta)),
+		Flags:     MOUSEEVENTF_WHEEL,
+	})
+}
+
+// ScrollHorizontal 水平滚动滚轮（正值向右，120 为一格）
+func (s *Simulator) ScrollHorizontal(delta int) error {
+	if delta == 0 {
+		return nil
+	}
+	return s.send(MOUSEINPUT{
+		MouseData: uint32(int32(delta)),
+		Flags:     MOUSEEVENTF_HWHEEL,
+	})
+}
+
+// send 发送鼠标输入事件
+func (s *Simulator) send(mi MOUSEINPUT) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	input := INPUT{
+		Type: INPUT_MOUSE,
+		Mi:   mi,
+	}
+
+	ret, _, err := procSendInput.Call(
+		1,
+		uintptr(unsafe.Pointer(&input)),
+		unsafe.Sizeof(input),
+	)
+
+	if ret == 0 {
+		return err
+	}
+
+	return nil
+}
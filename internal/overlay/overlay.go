@@ -0,0 +1,428 @@
+// Package overlay 直播叠加层服务：通过 WebSocket 和 Server-Sent Events 以 JSON 推送手柄按键、
+// 摇杆/扳机状态和规则触发，并提供可直接作为 OBS 浏览器源使用的页面。
+package overlay
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gamepad-key-mapper/internal/app"
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/mapper"
+)
+
+//go:embed overlay.html
+var overlayPage []byte
+
+const (
+	inputInterval     = time.Second / 60 // 手柄状态的最高推送频率
+	keepAliveInterval = 15 * time.Second // 连接保活间隔
+	clientBuffer      = 64               // 每个客户端待发送的消息数，发送不及时的消息会被丢弃
+	traceBuffer       = 256              // 等待处理的规则触发事件数
+)
+
+// 消息类型
+const (
+	TypeInput = "input" // 手柄状态变化
+	TypeRule  = "rule"  // 规则触发
+)
+
+// Message 推送给客户端的消息
+type Message struct {
+	Type  string          `json:"type"`
+	Time  time.Time       `json:"time"`
+	Input *InputState     `json:"input,omitempty"` // TypeInput
+	Rule  *RuleActivation `json:"rule,omitempty"`  // TypeRule
+}
+
+// InputState 手柄状态（摇杆为 -1~1，Y 轴向上为正；扳机为 0~1）
+type InputState struct {
+	Connected    bool             `json:"connected"`
+	Buttons      []gamepad.Button `json:"buttons"` // 按下的按键（扳机超过阈值时包含 LT/RT）
+	LeftX        float64          `json:"left_x"`
+	LeftY        float64          `json:"left_y"`
+	RightX       float64          `json:"right_x"`
+	RightY       float64          `json:"right_y"`
+	LeftTrigger  float64          `json:"left_trigger"`
+	RightTrigger float64          `json:"right_trigger"`
+}
+
+// RuleActivation 规则触发（源按键按下或释放时匹配到规则）
+type RuleActivation struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name,omitempty"`
+	Button      gamepad.Button `json:"button"`
+	Pressed     bool           `json:"pressed"`
+	Description string         `json:"description"` // 规则的可读描述，如「A → ⌨️ Ctrl+S」
+}
+
+// newInputState 从状态快照生成手柄状态
+func newInputState(snapshot gamepad.Snapshot) *InputState {
+	gp := snapshot.Gamepad
+	state := gamepad.XInputState{Gamepad: gp}
+	return &InputState{
+		Connected:    snapshot.Connected,
+		Buttons:      append([]gamepad.Button{}, state.GetPressedButtons()...),
+		LeftX:        gp.Axis(gamepad.AxisLeftX),
+		LeftY:        gp.Axis(gamepad.AxisLeftY),
+		RightX:       gp.Axis(gamepad.AxisRightX),
+		RightY:       gp.Axis(gamepad.AxisRightY),
+		LeftTrigger:  gp.Axis(gamepad.AxisLeftTrigger),
+		RightTrigger: gp.Axis(gamepad.AxisRightTrigger),
+	}
+}
+
+// Server 直播叠加层服务
+//
+// 有客户端连接时才订阅手柄状态和规则触发，没有客户端时不占用手柄监听。
+type Server struct {
+	app     *app.App
+	handler http.Handler
+	done    chan struct{} // 服务关闭时关闭
+
+	mu         sync.Mutex
+	clients    map[chan encoded]struct{}
+	stopFeed   func()  // 停止订阅（没有客户端时为 nil）
+	lastInput  encoded // 最近一次的手柄状态，新客户端连接时先发送
+	boundIP    net.IP  // 监听的地址（Listen 之前为 nil）
+	httpServer *http.Server
+	closeOnce  sync.Once
+}
+
+// NewServer 创建叠加层服务
+func NewServer(a *app.App) *Server {
+	s := &Server{
+		app:     a,
+		done:    make(chan struct{}),
+		clients: make(map[chan encoded]struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handlePage)
+	mux.HandleFunc("/ws", s.handleWebSocket)
+	mux.HandleFunc("/events", s.handleEvents)
+	s.handler = s.checkHost(mux)
+	return s
+}
+
+// Handler 返回服务的 HTTP 处理器（/ 叠加层页面，/ws WebSocket，/events Server-Sent Events）
+//
+// 只接受主机名为本机或监听地址的请求。
+func (s *Server) Handler() http.Handler {
+	return s.handler
+}
+
+// Listen 在 addr 上监听并在后台提供服务
+func (s *Server) Listen(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("启动叠加层服务失败: %w", err)
+	}
+
+	s.mu.Lock()
+	if tcpAddr, ok := l.Addr().(*net.TCPAddr); ok {
+		s.boundIP = tcpAddr.IP
+	}
+	s.httpServer = &http.Server{Handler: s.handler, ReadHeaderTimeout: 10 * time.Second}
+	srv := s.httpServer
+	s.mu.Unlock()
+
+	slog.Info("overlay server listening", "addr", l.Addr().String())
+	go func() {
+		if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("overlay server stopped", "err", err)
+		}
+	}()
+	return nil
+}
+
+// Close 停止服务并断开所有客户端
+func (s *Server) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+
+		s.mu.Lock()
+		srv := s.httpServer
+		s.mu.Unlock()
+		if srv != nil {
+			err = srv.Close()
+		}
+	})
+	return err
+}
+
+// handlePage 返回叠加层页面
+func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(overlayPage)
+}
+
+// handleWebSocket 通过 WebSocket 推送消息（每条消息为一个 JSON 文本帧）
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		http.Error(w, "不允许跨站连接", http.StatusForbidden)
+		return
+	}
+	conn, err := upgradeWebSocket(w, r)
+	if err != nil {
+		slog.Debug("overlay websocket handshake failed", "err", err)
+		return
+	}
+	defer conn.Close()
+
+	closed := make(chan struct{})
+	go func() {
+		conn.readLoop()
+		close(closed)
+	}()
+
+	messages := s.subscribe()
+	defer s.unsubscribe(messages)
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case msg := <-messages:
+			if err := conn.writeText(msg.data); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if err := conn.writeFrame(opPing, nil); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		case <-s.done:
+			conn.writeFrame(opClose, nil)
+			return
+		}
+	}
+}
+
+// handleEvents 通过 Server-Sent Events 推送消息（事件名为消息类型，数据为 JSON）
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "不支持流式响应", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	messages := s.subscribe()
+	defer s.unsubscribe(messages)
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case msg := <-messages:
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.kind, msg.data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// checkHost 拒绝主机名不是本机或监听地址的请求
+//
+// 防止 DNS 重绑定：其它网站把自己的域名解析到 127.0.0.1 后，浏览器会认为本服务与该网站同源，
+// 此时请求的 Host 仍是该网站的域名。
+func (s *Server) checkHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowedHost(r.Host) {
+			http.Error(w, "不允许的主机名", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost 检查请求的 Host 是否为 localhost、回环地址或监听的地址
+//
+// 监听所有地址（如 0.0.0.0）时接受任意 IP 地址，域名只接受 localhost。
+func (s *Server) allowedHost(host string) bool {
+	name := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		name = h
+	}
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "["), "]")
+	if strings.EqualFold(name, "localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(name)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		return true
+	}
+	s.mu.Lock()
+	bound := s.boundIP
+	s.mu.Unlock()
+	return bound != nil && (bound.IsUnspecified() || bound.Equal(ip))
+}
+
+// sameOrigin 检查 WebSocket 请求是否来自本服务的页面（或非浏览器客户端）
+//
+// 浏览器允许任意网页连接本机的 WebSocket，检查 Origin 避免其它网站读取手柄输入
+// （请求的 Host 已由 checkHost 检查）。
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return u.Host == r.Host
+}
+
+// encoded 编码后的消息
+type encoded struct {
+	kind string // 消息类型
+	data []byte // JSON
+}
+
+// subscribe 添加客户端，第一个客户端连接时开始订阅
+func (s *Server) subscribe() chan encoded {
+	ch := make(chan encoded, clientBuffer)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients[ch] = struct{}{}
+	if s.lastInput.data != nil {
+		ch <- s.lastInput
+	}
+	if s.stopFeed == nil {
+		s.stopFeed = s.startFeed()
+	}
+	return ch
+}
+
+// unsubscribe 移除客户端，最后一个客户端断开时停止订阅
+func (s *Server) unsubscribe(ch chan encoded) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.clients, ch)
+	if len(s.clients) == 0 && s.stopFeed != nil {
+		s.stopFeed()
+		s.stopFeed = nil
+		s.lastInput = encoded{}
+	}
+}
+
+// startFeed 订阅手柄状态和规则触发，返回停止订阅的函数（调用方需持有锁）
+func (s *Server) startFeed() func() {
+	// 跟踪事件在映射引擎的协程中同步产生，只做非阻塞转发
+	traces := make(chan mapper.TraceEvent, traceBuffer)
+	removeTracer := s.app.AddTracer(mapper.TracerFunc(func(event mapper.TraceEvent) {
+		if event.Kind != mapper.TraceMatch {
+			return
+		}
+		select {
+		case traces <- event:
+		default:
+		}
+	}))
+
+	// 没有手柄监听（如非 Windows 系统）时仍推送规则触发
+	states, cancelInput, err := s.app.WatchInput(inputInterval)
+	if err != nil {
+		slog.Warn("overlay input feed unavailable", "err", err)
+		cancelInput = func() {}
+	}
+
+	stop := make(chan struct{})
+	go s.feed(states, traces, stop)
+	return func() {
+		removeTracer()
+		cancelInput()
+		close(stop)
+	}
+}
+
+// feed 将手柄状态变化和规则触发转换为消息并广播
+func (s *Server) feed(states <-chan gamepad.Snapshot, traces <-chan mapper.TraceEvent, stop <-chan struct{}) {
+	var last gamepad.Snapshot
+	first := true
+	for {
+		select {
+		case snapshot, ok := <-states:
+			if !ok {
+				states = nil // 订阅已取消，等待 stop
+				continue
+			}
+			if !first && snapshot == last {
+				continue // 只推送变化
+			}
+			first, last = false, snapshot
+			s.broadcast(&Message{Type: TypeInput, Time: time.Now(), Input: newInputState(snapshot)})
+
+		case event := <-traces:
+			activation := &RuleActivation{ID: event.RuleID, Button: event.Button, Pressed: event.Pressed}
+			if rule := s.app.GetRule(event.RuleID); rule != nil {
+				activation.Name = rule.Name
+				activation.Description = rule.String()
+			}
+			s.broadcast(&Message{Type: TypeRule, Time: event.Time, Rule: activation})
+
+		case <-stop:
+			return
+		}
+	}
+}
+
+// broadcast 将消息发送给所有客户端（客户端发送不及时时丢弃该消息）
+func (s *Server) broadcast(msg *Message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("overlay message encode failed", "err", err)
+		return
+	}
+
+	e := encoded{kind: msg.Type, data: data}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.clients) == 0 {
+		return // 订阅已停止
+	}
+	if msg.Type == TypeInput {
+		s.lastInput = e
+	}
+	for ch := range s.clients {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
@@ -0,0 +1,446 @@
+package overlay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gamepad-key-mapper/internal/app"
+	"gamepad-key-mapper/internal/config"
+	"gamepad-key-mapper/internal/gamepad"
+	"gamepad-key-mapper/internal/keyboard"
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// newTestServer 创建叠加层服务并用 httptest 在本机随机端口提供服务
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	if err := config.SetConfigPath(filepath.Join(t.TempDir(), "config.json")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { config.SetConfigPath("") })
+
+	a, err := app.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(a)
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(func() {
+		s.Close()
+		ts.Close()
+	})
+	return s, ts
+}
+
+// waitClients 等待订阅的客户端数量达到 n
+func waitClients(t *testing.T, s *Server, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		s.mu.Lock()
+		count := len(s.clients)
+		s.mu.Unlock()
+		if count == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("clients = %d, want %d", count, n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// ruleMessage 创建规则触发消息
+func ruleMessage(description string) *Message {
+	return &Message{
+		Type: TypeRule,
+		Time: time.Now(),
+		Rule: &RuleActivation{ID: "r1", Button: gamepad.ButtonA, Pressed: true, Description: description},
+	}
+}
+
+func TestPage(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("GET / = %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if string(body) != string(overlayPage) {
+		t.Error("GET / did not return the overlay page")
+	}
+
+	resp, err = http.Get(ts.URL + "/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /missing = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestAllowedHost(t *testing.T) {
+	s := NewServer(nil)
+	tests := []struct {
+		host      string
+		bound     net.IP
+		want      bool
+		wantBound bool // 监听所有地址时的结果
+	}{
+		{"localhost:9876", nil, true, true},
+		{"LOCALHOST", nil, true, true},
+		{"127.0.0.1:9876", nil, true, true},
+		{"127.0.0.2", nil, true, true},
+		{"[::1]:9876", nil, true, true},
+		{"192.168.1.10:9876", nil, false, true},
+		{"evil.example:9876", nil, false, false}, // DNS 重绑定
+		{"localhost.evil.example", nil, false, false},
+		{"", nil, false, false},
+	}
+	for _, tt := range tests {
+		s.boundIP = net.IPv4(127, 0, 0, 1)
+		if got := s.allowedHost(tt.host); got != tt.want {
+			t.Errorf("allowedHost(%q) bound to 127.0.0.1 = %v, want %v", tt.host, got, tt.want)
+		}
+		s.boundIP = net.IPv4zero
+		if got := s.allowedHost(tt.host); got != tt.wantBound {
+			t.Errorf("allowedHost(%q) bound to 0.0.0.0 = %v, want %v", tt.host, got, tt.wantBound)
+		}
+	}
+
+	// 监听指定地址时接受该地址
+	s.boundIP = net.ParseIP("192.168.1.10")
+	if !s.allowedHost("192.168.1.10:9876") || s.allowedHost("192.168.1.11:9876") {
+		t.Error("bound address not matched exactly")
+	}
+}
+
+func TestRejectsForeignHost(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	for _, path := range []string{"/", "/ws", "/events"} {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Host = "evil.example:9876"
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("GET %s with foreign host = %d, want 403", path, resp.StatusCode)
+		}
+	}
+}
+
+// wsClient 测试用的 WebSocket 客户端
+type wsClient struct {
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+// dialWebSocket 发送握手请求，返回响应和连接
+func dialWebSocket(t *testing.T, ts *httptest.Server, header http.Header) (*http.Response, *wsClient) {
+	t.Helper()
+	conn, err := net.Dial("tcp", ts.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header = header
+	if err := req.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, &wsClient{conn: conn, br: br}
+}
+
+// handshakeHeader 返回 RFC 6455 示例中的握手请求头
+func handshakeHeader() http.Header {
+	return http.Header{
+		"Connection":            {"keep-alive, Upgrade"},
+		"Upgrade":               {"websocket"},
+		"Sec-Websocket-Version": {"13"},
+		"Sec-Websocket-Key":     {"dGhlIHNhbXBsZSBub25jZQ=="},
+	}
+}
+
+// readFrame 读取服务端的帧（不加掩码）
+func (c *wsClient) readFrame(t *testing.T) (byte, []byte) {
+	t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	if head[0]&0x80 == 0 {
+		t.Fatal("server frame without FIN")
+	}
+	if head[1]&0x80 != 0 {
+		t.Fatal("server frame is masked")
+	}
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(c.br, ext[:])
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(c.br, ext[:])
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		t.Fatal(err)
+	}
+	return head[0] & 0x0F, payload
+}
+
+// readMessage 读取下一条指定类型的消息（跳过其它消息）
+func (c *wsClient) readMessage(t *testing.T, kind string) Message {
+	t.Helper()
+	for {
+		opcode, payload := c.readFrame(t)
+		if opcode != opText {
+			t.Fatalf("opcode = %#x, want text frame", opcode)
+		}
+		var msg Message
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Type == kind {
+			return msg
+		}
+	}
+}
+
+// writeFrame 发送加掩码的客户端帧
+func (c *wsClient) writeFrame(t *testing.T, opcode byte, payload []byte) {
+	t.Helper()
+	mask := [4]byte{0x12, 0x34, 0x56, 0x78}
+	frame := []byte{0x80 | opcode, 0x80 | byte(len(payload))}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWebSocket(t *testing.T) {
+	s, ts := newTestServer(t)
+
+	resp, ws := dialWebSocket(t, ts, handshakeHeader())
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake status = %d, want 101", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Sec-WebSocket-Accept = %q", got)
+	}
+	waitClients(t, s, 1)
+
+	// 短消息和需要 16 位扩展长度的消息
+	long := strings.Repeat("x", 300)
+	for _, description := range []string{"A → ⌨️ Ctrl+S", long} {
+		s.broadcast(ruleMessage(description))
+		msg := ws.readMessage(t, TypeRule)
+		if msg.Rule == nil || msg.Rule.Description != description {
+			t.Errorf("rule message = %+v, want description %q", msg.Rule, description)
+		}
+	}
+
+	// ping 原样回复 pong
+	ws.writeFrame(t, opPing, []byte("hi"))
+	for {
+		opcode, payload := ws.readFrame(t)
+		if opcode == opText {
+			continue // 手柄状态
+		}
+		if opcode != opPong || string(payload) != "hi" {
+			t.Fatalf("reply to ping = %#x %q, want pong hi", opcode, payload)
+		}
+		break
+	}
+
+	// 客户端关闭后服务端回复关闭帧并取消订阅
+	ws.writeFrame(t, opClose, nil)
+	for {
+		opcode, _ := ws.readFrame(t)
+		if opcode == opClose {
+			break
+		}
+	}
+	waitClients(t, s, 0)
+}
+
+func TestWebSocketServerClose(t *testing.T) {
+	s, ts := newTestServer(t)
+
+	_, ws := dialWebSocket(t, ts, handshakeHeader())
+	waitClients(t, s, 1)
+	s.Close()
+	for {
+		opcode, _ := ws.readFrame(t)
+		if opcode == opClose {
+			break
+		}
+	}
+}
+
+func TestWebSocketHandshakeErrors(t *testing.T) {
+	_, ts := newTestServer(t)
+	host := ts.Listener.Addr().String()
+
+	tests := []struct {
+		name   string
+		modify func(h http.Header)
+		want   int
+	}{
+		{"same origin", func(h http.Header) { h.Set("Origin", "http://"+host) }, http.StatusSwitchingProtocols},
+		{"foreign origin", func(h http.Header) { h.Set("Origin", "https://evil.example") }, http.StatusForbidden},
+		{"same host other port", func(h http.Header) { h.Set("Origin", "http://127.0.0.1:1") }, http.StatusForbidden},
+		{"not upgrade", func(h http.Header) { h.Del("Upgrade") }, http.StatusBadRequest},
+		{"old version", func(h http.Header) { h.Set("Sec-WebSocket-Version", "8") }, http.StatusBadRequest},
+		{"missing key", func(h http.Header) { h.Del("Sec-WebSocket-Key") }, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := handshakeHeader()
+			tt.modify(header)
+			resp, _ := dialWebSocket(t, ts, header)
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteFrameLengths(t *testing.T) {
+	tests := []struct {
+		length int
+		header []byte
+	}{
+		{0, []byte{0x81, 0}},
+		{125, []byte{0x81, 125}},
+		{126, []byte{0x81, 126, 0, 126}},
+		{0xFFFF, []byte{0x81, 126, 0xFF, 0xFF}},
+		{0x10000, []byte{0x81, 127, 0, 0, 0, 0, 0, 1, 0, 0}},
+	}
+	for _, tt := range tests {
+		server, client := net.Pipe()
+		c := &wsConn{conn: server}
+		go func() {
+			c.writeText(make([]byte, tt.length))
+			server.Close()
+		}()
+		data, err := io.ReadAll(client)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != len(tt.header)+tt.length || string(data[:len(tt.header)]) != string(tt.header) {
+			t.Errorf("length %d: header % x (total %d), want % x", tt.length, data[:min(len(data), len(tt.header))], len(data), tt.header)
+		}
+	}
+}
+
+func TestReadFrameRejectsInvalidFrames(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame []byte
+	}{
+		{"unmasked", []byte{0x81, 2, 'h', 'i'}},
+		{"too large", append([]byte{0x81, 0x80 | 127}, binary.BigEndian.AppendUint64(nil, maxClientFrame+1)...)},
+		{"truncated", []byte{0x81, 0x80 | 5, 1, 2, 3, 4, 'h'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &wsConn{br: bufio.NewReader(strings.NewReader(string(tt.frame)))}
+			if _, _, err := c.readFrame(); err == nil {
+				t.Error("readFrame accepted invalid frame")
+			}
+		})
+	}
+}
+
+func TestEvents(t *testing.T) {
+	s, ts := newTestServer(t)
+	rule, err := s.app.AddRule(gamepad.ButtonA, keyboard.KeyCode(0x53), keyboard.Modifiers{Ctrl: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(ts.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("GET /events = %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	waitClients(t, s, 1)
+
+	// 规则触发的跟踪事件转换为带规则描述的消息
+	traces := make(chan mapper.TraceEvent, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	go s.feed(nil, traces, stop)
+	traces <- mapper.TraceEvent{Kind: mapper.TraceMatch, Time: time.Now(), Button: gamepad.ButtonA, Pressed: true, RuleID: rule.ID}
+
+	br := bufio.NewReader(resp.Body)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != "event: rule\n" {
+			continue
+		}
+		data, err := br.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		var msg Message
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(data, "data: ")), &msg); err != nil {
+			t.Fatalf("data line %q: %v", data, err)
+		}
+		want := RuleActivation{ID: rule.ID, Button: gamepad.ButtonA, Pressed: true, Description: rule.String()}
+		if msg.Type != TypeRule || msg.Rule == nil || *msg.Rule != want {
+			t.Errorf("message = %+v, want rule %+v", msg, want)
+		}
+		if blank, _ := br.ReadString('\n'); blank != "\n" {
+			t.Errorf("event not terminated by blank line: %q", blank)
+		}
+		break
+	}
+
+	resp.Body.Close()
+	waitClients(t, s, 0)
+}
@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// onShowBackups 打开配置备份对话框：选择备份恢复
+//
+// 启动时配置文件无法读取（保存已暂停）时同时显示原因，并可以确认用当前配置覆盖配置文件。
+func (mw *MainWindow) onShowBackups() {
+	backups, err := mw.appCtrl.ListBackups()
+	if err != nil {
+		dialog.ShowError(err, mw.window)
+		return
+	}
+
+	var items []fyne.CanvasObject
+	loadErr := mw.appCtrl.LoadFailure()
+	if loadErr != nil {
+		msg := widget.NewLabel(loadErr.Error() + "\n\n" +
+			"为避免默认配置覆盖原文件，自动保存已暂停。可以从备份恢复，" +
+			"或确认使用当前配置（原文件仍保留在备份中）。")
+		msg.Wrapping = fyne.TextWrapWord
+		items = append(items, msg, widget.NewSeparator())
+	}
+
+	labels := make([]string, len(backups))
+	for i, b := range backups {
+		labels[i] = fmt.Sprintf("%s  %s（%d 字节）", b.Time.Format("2006-01-02 15:04:05"), filepath.Base(b.Path), b.Size)
+	}
+	selector := widget.NewSelect(labels, nil)
+	selector.PlaceHolder = "选择要恢复的备份"
+	if len(backups) == 0 {
+		selector.PlaceHolder = "没有备份"
+		selector.Disable()
+	}
+	items = append(items, selector)
+
+	var d *dialog.CustomDialog
+	restoreBtn := widget.NewButtonWithIcon("恢复", theme.HistoryIcon(), func() {
+		i := selector.SelectedIndex()
+		if i < 0 {
+			return
+		}
+		path := backups[i].Path
+		dialog.ShowConfirm("恢复备份",
+			"当前配置会先被备份，确定要恢复 "+filepath.Base(path)+" 吗？",
+			func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				d.Hide()
+				if err := mw.appCtrl.RestoreBackup(path); err != nil {
+					dialog.ShowError(err, mw.window)
+				}
+			},
+			mw.window,
+		)
+	})
+	restoreBtn.Importance = widget.HighImportance
+	restoreBtn.Disable()
+	selector.OnChanged = func(string) { restoreBtn.Enable() }
+
+	buttons := []fyne.CanvasObject{restoreBtn}
+	if loadErr != nil {
+		keepBtn := widget.NewButton("使用当前配置", func() {
+			d.Hide()
+			if err := mw.appCtrl.ConfirmDefaultConfig(); err != nil {
+				dialog.ShowError(err, mw.window)
+			}
+		})
+		buttons = append(buttons, keepBtn)
+	}
+	buttons = append(buttons, widget.NewButton("关闭", func() { d.Hide() }))
+
+	title := "配置备份"
+	if loadErr != nil {
+		title = "配置文件无法读取"
+	}
+	d = dialog.NewCustomWithoutButtons(title, container.NewVBox(items...), mw.window)
+	d.SetButtons(buttons)
+	d.Resize(fyne.NewSize(480, 0))
+	d.Show()
+}
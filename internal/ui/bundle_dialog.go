@@ -0,0 +1,264 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"gamepad-key-mapper/internal/app"
+	"gamepad-key-mapper/internal/config"
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// bundleExtensions 方案包文件可用的扩展名
+var bundleExtensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+// onExport 导出当前方案：先填写描述信息，再选择保存位置
+func (pb *ProfileBar) onExport() {
+	current := pb.appCtrl.ActiveProfile()
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(current)
+	authorEntry := widget.NewEntry()
+	gameEntry := widget.NewEntry()
+	controllerEntry := widget.NewEntry()
+	controllerEntry.SetPlaceHolder("如 Xbox Series、Xbox Elite 2")
+	descEntry := widget.NewMultiLineEntry()
+
+	dialog.ShowForm("导出方案", "选择位置", "取消",
+		[]*widget.FormItem{
+			widget.NewFormItem("名称", nameEntry),
+			widget.NewFormItem("作者", authorEntry),
+			widget.NewFormItem("适用游戏", gameEntry),
+			widget.NewFormItem("手柄类型", controllerEntry),
+			widget.NewFormItem("说明", descEntry),
+		},
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			meta := config.BundleMeta{
+				Name:        strings.TrimSpace(nameEntry.Text),
+				Author:      strings.TrimSpace(authorEntry.Text),
+				Game:        strings.TrimSpace(gameEntry.Text),
+				Controller:  strings.TrimSpace(controllerEntry.Text),
+				Description: strings.TrimSpace(descEntry.Text),
+			}
+			pb.saveBundle(current, meta)
+		},
+		pb.parent,
+	)
+}
+
+// saveBundle 选择保存位置并导出方案包
+func (pb *ProfileBar) saveBundle(profile string, meta config.BundleMeta) {
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, pb.parent)
+			return
+		}
+		if writer == nil {
+			return // 用户取消
+		}
+		path := writer.URI().Path()
+		writer.Close()
+
+		if err := pb.appCtrl.ExportProfile(profile, path, meta); err != nil {
+			dialog.ShowError(err, pb.parent)
+			return
+		}
+		dialog.ShowInformation("导出完成", "方案已导出到 "+path, pb.parent)
+	}, pb.parent)
+
+	name := meta.Name
+	if name == "" {
+		name = profile
+	}
+	save.SetFileName(name + ".json")
+	save.SetFilter(storage.NewExtensionFileFilter(bundleExtensions))
+	save.Show()
+}
+
+// onImport 选择方案包文件并导入
+func (pb *ProfileBar) onImport() {
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, pb.parent)
+			return
+		}
+		if reader == nil {
+			return // 用户取消
+		}
+		path := reader.URI().Path()
+		reader.Close()
+
+		// 其它映射工具的配置文件先转换为方案包
+		var bundle *config.Bundle
+		var report *config.ImportReport
+		if config.IsForeignFormat(path) {
+			bundle, report, err = pb.appCtrl.ReadForeign(path)
+		} else {
+			bundle, err = pb.appCtrl.ReadBundle(path)
+		}
+		if err != nil {
+			dialog.ShowError(err, pb.parent)
+			return
+		}
+		pb.showImportOptions(bundle, report)
+	}, pb.parent)
+
+	extensions := append(append([]string{}, bundleExtensions...), config.ForeignFormatExtensions...)
+	open.SetFilter(storage.NewExtensionFileFilter(extensions))
+	open.Show()
+}
+
+// importModeOptions 导入方式选项（顺序与 app.ImportMode 一致）
+var importModeOptions = []string{"新建方案", "合并到当前方案", "替换当前方案的规则"}
+
+// conflictOptions 冲突处理选项（顺序与 app.ConflictPolicy 一致）
+var conflictOptions = []string{"保留已有规则", "使用导入的规则"}
+
+// showImportOptions 显示方案包信息并选择导入方式（report 不为 nil 时一并显示转换报告）
+func (pb *ProfileBar) showImportOptions(bundle *config.Bundle, report *config.ImportReport) {
+	info := widget.NewLabel(bundleSummary(bundle))
+	info.Wrapping = fyne.TextWrapWord
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder(bundle.Meta.Name)
+
+	conflictSelect := widget.NewSelect(conflictOptions, nil)
+	conflictSelect.SetSelectedIndex(int(app.ConflictSkip))
+	conflictSelect.Disable()
+
+	modeSelect := widget.NewRadioGroup(importModeOptions, func(selected string) {
+		// 名称只用于新建，冲突处理只用于合并
+		if selected == importModeOptions[app.ImportAsNew] {
+			nameEntry.Enable()
+		} else {
+			nameEntry.Disable()
+		}
+		if selected == importModeOptions[app.ImportMerge] {
+			conflictSelect.Enable()
+		} else {
+			conflictSelect.Disable()
+		}
+	})
+	modeSelect.Required = true
+	modeSelect.SetSelected(importModeOptions[app.ImportAsNew])
+
+	items := []*widget.FormItem{widget.NewFormItem("方案包", info)}
+	if report != nil && len(report.Issues) > 0 {
+		issues := widget.NewLabel(reportSummary(report))
+		issues.Wrapping = fyne.TextWrapWord
+		scroll := container.NewVScroll(issues)
+		scroll.SetMinSize(fyne.NewSize(0, 120))
+		items = append(items, widget.NewFormItem("未能转换", scroll))
+	}
+	// 命令规则可以执行任意程序：列出命令，默认导入后停用，勾选后才保持启用
+	execCheck := widget.NewCheck("保持这些命令规则启用（只在信任来源时勾选）", nil)
+	if execRules := bundle.ExecRules(); len(execRules) > 0 {
+		commands := widget.NewLabel(execRulesSummary(execRules))
+		commands.Wrapping = fyne.TextWrapBreak
+		scroll := container.NewVScroll(commands)
+		scroll.SetMinSize(fyne.NewSize(0, 80))
+		warning := widget.NewLabel(fmt.Sprintf("⚠ 包含 %d 条命令规则，按下按键时会执行以下命令。默认导入后停用，检查后可在列表中启用。", len(execRules)))
+		warning.Wrapping = fyne.TextWrapWord
+		items = append(items, widget.NewFormItem("命令规则", container.NewVBox(warning, scroll, execCheck)))
+	}
+
+	items = append(items,
+		widget.NewFormItem("导入方式", modeSelect),
+		widget.NewFormItem("新方案名称", nameEntry),
+		widget.NewFormItem("按键冲突时", conflictSelect),
+	)
+
+	dialog.ShowForm("导入方案", "导入", "取消", items,
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+
+			opts := app.ImportOptions{
+				Conflict:  app.ConflictPolicy(conflictSelect.SelectedIndex()),
+				AllowExec: execCheck.Checked,
+			}
+			for i, option := range importModeOptions {
+				if option == modeSelect.Selected {
+					opts.Mode = app.ImportMode(i)
+				}
+			}
+			if opts.Mode == app.ImportAsNew {
+				opts.Target = nameEntry.Text
+			}
+
+			result, err := pb.appCtrl.ImportBundle(bundle, opts)
+			if err != nil {
+				dialog.ShowError(err, pb.parent)
+				return
+			}
+			dialog.ShowInformation("导入完成", importSummary(result), pb.parent)
+		},
+		pb.parent,
+	)
+}
+
+// bundleSummary 方案包描述
+func bundleSummary(bundle *config.Bundle) string {
+	lines := []string{bundle.Meta.Name}
+	if bundle.Meta.Author != "" {
+		lines = append(lines, "作者: "+bundle.Meta.Author)
+	}
+	if bundle.Meta.Game != "" {
+		lines = append(lines, "游戏: "+bundle.Meta.Game)
+	}
+	if bundle.Meta.Controller != "" {
+		lines = append(lines, "手柄: "+bundle.Meta.Controller)
+	}
+	if bundle.Meta.Description != "" {
+		lines = append(lines, bundle.Meta.Description)
+	}
+	counts := fmt.Sprintf("%d 条按键规则", len(bundle.Profile.Rules))
+	if n := len(bundle.ExecRules()); n > 0 {
+		counts += fmt.Sprintf("（其中 %d 条命令规则）", n)
+	}
+	lines = append(lines, counts+fmt.Sprintf("，%d 条模拟量规则", len(bundle.Profile.AxisRules)))
+	return strings.Join(lines, "\n")
+}
+
+// execRulesSummary 命令规则列表（每行一条，显示完整命令）
+func execRulesSummary(rules []*mapper.MappingRule) string {
+	lines := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		lines = append(lines, rule.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// reportSummary 转换报告中的问题列表
+func reportSummary(report *config.ImportReport) string {
+	lines := make([]string, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		lines = append(lines, issue.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// importSummary 导入结果描述
+func importSummary(result *app.ImportResult) string {
+	text := fmt.Sprintf("已导入到方案 \"%s\"：新增 %d 条规则", result.Profile, result.Added)
+	if result.Replaced > 0 {
+		text += fmt.Sprintf("，替换 %d 条", result.Replaced)
+	}
+	if result.Skipped > 0 {
+		text += fmt.Sprintf("，因按键冲突跳过 %d 条", result.Skipped)
+	}
+	if result.ExecDisabled > 0 {
+		text += fmt.Sprintf("\n%d 条命令规则已停用，确认命令内容后可在列表中启用", result.ExecDisabled)
+	}
+	return text
+}
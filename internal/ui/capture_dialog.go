@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"gamepad-key-mapper/internal/app"
+	"gamepad-key-mapper/internal/gamepad"
+)
+
+// captureTimeout 等待按下手柄按键的时间
+const captureTimeout = 10 * time.Second
+
+// showCaptureDialog 等待用户在手柄上按下按键，成功后以按下顺序回调（在界面线程中调用）
+func showCaptureDialog(parent fyne.Window, appCtrl *app.App, onCaptured func([]gamepad.Button)) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	message := widget.NewLabel(fmt.Sprintf("请在 %d 秒内按下手柄按键、组合键或推动摇杆，松开后完成", int(captureTimeout.Seconds())))
+	message.Wrapping = fyne.TextWrapWord
+	progress := widget.NewProgressBarInfinite()
+
+	d := dialog.NewCustom("等待按键", "取消", container.NewVBox(message, progress), parent)
+	d.SetOnClosed(cancel)
+	d.Resize(fyne.NewSize(320, 140))
+	d.Show()
+
+	go func() {
+		buttons, err := appCtrl.CaptureInput(ctx, captureTimeout)
+		fyne.Do(func() {
+			d.Hide()
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					dialog.ShowError(err, parent)
+				}
+				return
+			}
+			onCaptured(buttons)
+		})
+	}()
+}
@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"sync/atomic"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"gamepad-key-mapper/internal/app"
+	"gamepad-key-mapper/internal/mapper"
+)
+
+// eventKindFilters 事件类型筛选选项
+var eventKindFilters = []struct {
+	label string
+	kinds []mapper.TraceKind
+}{
+	{"全部", nil},
+	{"输入", []mapper.TraceKind{mapper.TraceInput}},
+	{"匹配", []mapper.TraceKind{mapper.TraceMatch}},
+	{"未触发", []mapper.TraceKind{mapper.TraceSkip}},
+	{"键盘输出", []mapper.TraceKind{mapper.TraceKeyDown, mapper.TraceKeyUp, mapper.TraceReleaseAll}},
+	{"命令", []mapper.TraceKind{mapper.TraceExec}},
+}
+
+// eventConsole 事件日志窗口
+type eventConsole struct {
+	window   fyne.Window
+	log      *app.EventLog
+	list     *widget.List
+	kinds    []mapper.TraceKind
+	search   *widget.Entry
+	follow   *widget.Check
+	shown    []mapper.TraceEvent
+	changed  atomic.Bool // 有新事件等待刷新
+	onClosed func()
+}
+
+// ShowEventConsole 打开事件日志窗口，窗口关闭时调用 onClosed
+func ShowEventConsole(fyneApp fyne.App, appCtrl *app.App, onClosed func()) fyne.Window {
+	c := &eventConsole{
+		window:   fyneApp.NewWindow("事件日志"),
+		log:      appCtrl.EventLog(),
+		onClosed: onClosed,
+	}
+	c.setup()
+
+	// 新事件在映射引擎的协程中到达，合并后在界面线程刷新
+	c.log.SetOnAppend(func(mapper.TraceEvent) {
+		if c.changed.CompareAndSwap(false, true) {
+			fyne.Do(func() {
+				c.changed.Store(false)
+				c.refresh()
+			})
+		}
+	})
+	c.window.SetOnClosed(func() {
+		c.log.SetOnAppend(nil)
+		if c.onClosed != nil {
+			c.onClosed()
+		}
+	})
+
+	c.refresh()
+	c.window.Resize(fyne.NewSize(640, 480))
+	c.window.Show()
+	return c.window
+}
+
+// setup 设置界面
+func (c *eventConsole) setup() {
+	c.list = widget.NewList(
+		func() int {
+			return len(c.shown)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.TextStyle = fyne.TextStyle{Monospace: true}
+			return label
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			if id < len(c.shown) {
+				item.(*widget.Label).SetText(c.shown[id].String())
+			}
+		},
+	)
+
+	labels := make([]string, len(eventKindFilters))
+	for i, f := range eventKindFilters {
+		labels[i] = f.label
+	}
+	kindSelect := widget.NewSelect(labels, func(selected string) {
+		for _, f := range eventKindFilters {
+			if f.label == selected {
+				c.kinds = f.kinds
+			}
+		}
+		c.refresh()
+	})
+	kindSelect.SetSelected(labels[0])
+
+	c.search = widget.NewEntry()
+	c.search.SetPlaceHolder("搜索按键、规则ID或按键名称")
+	c.search.OnChanged = func(string) {
+		c.refresh()
+	}
+
+	pauseCheck := widget.NewCheck("暂停记录", c.log.SetPaused)
+	pauseCheck.SetChecked(c.log.Paused())
+	c.follow = widget.NewCheck("自动滚动", nil)
+	c.follow.SetChecked(true)
+
+	clearBtn := widget.NewButtonWithIcon("清空", theme.ContentClearIcon(), func() {
+		c.log.Clear()
+		c.refresh()
+	})
+	exportBtn := widget.NewButtonWithIcon("导出", theme.DocumentSaveIcon(), c.export)
+
+	toolbar := container.NewBorder(nil, nil,
+		kindSelect,
+		container.NewHBox(pauseCheck, c.follow, clearBtn, exportBtn),
+		c.search,
+	)
+	c.window.SetContent(container.NewBorder(toolbar, nil, nil, nil, c.list))
+}
+
+// filter 返回当前的过滤条件
+func (c *eventConsole) filter() app.EventFilter {
+	return app.EventFilter{Kinds: c.kinds, Text: c.search.Text}
+}
+
+// refresh 按过滤条件重新显示事件
+func (c *eventConsole) refresh() {
+	if c.follow == nil {
+		return // 界面还在创建中
+	}
+	c.shown = c.filter().Apply(c.log.Entries())
+	c.list.Refresh()
+	if c.follow.Checked {
+		c.list.ScrollToBottom()
+	}
+}
+
+// export 将当前显示的事件导出到文件
+func (c *eventConsole) export() {
+	filter := c.filter()
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, c.window)
+			return
+		}
+		if writer == nil {
+			return // 用户取消
+		}
+		path := writer.URI().Path()
+		writer.Close()
+
+		if err := c.log.Export(path, filter); err != nil {
+			dialog.ShowError(err, c.window)
+			return
+		}
+		dialog.ShowInformation("导出完成", "事件日志已导出到 "+path, c.window)
+	}, c.window)
+
+	save.SetFileName("events-" + time.Now().Format("20060102-150405") + ".log")
+	save.SetFilter(storage.NewExtensionFileFilter([]string{".log", ".txt"}))
+	save.Show()
+}
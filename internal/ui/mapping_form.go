@@ -2,6 +2,8 @@ package ui
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -11,10 +13,21 @@ import (
 	"gamepad-key-mapper/internal/app"
 	"gamepad-key-mapper/internal/gamepad"
 	"gamepad-key-mapper/internal/keyboard"
+	"gamepad-key-mapper/internal/mapper"
 )
 
 // ShowMappingForm 显示添加/编辑映射对话框
 func ShowMappingForm(parent fyne.Window, appCtrl *app.App, editID *string) {
+	// 编辑时读取原规则
+	var editing *mapper.MappingRule
+	if editID != nil {
+		editing = appCtrl.GetRule(*editID)
+		if editing == nil {
+			dialog.ShowError(errors.New("规则不存在或已被删除"), parent)
+			return
+		}
+	}
+
 	// 源按键选择
 	sourceButtons := gamepad.AllButtons()
 	sourceOptions := make([]string, len(sourceButtons))
@@ -25,17 +38,23 @@ func ShowMappingForm(parent fyne.Window, appCtrl *app.App, editID *string) {
 	sourceSelect.PlaceHolder = "选择手柄按键"
 
 	// 目标类型选择
-	targetTypeSelect := widget.NewSelect([]string{"键盘按键", "手柄按键"}, nil)
+	targetTypeSelect := widget.NewSelect([]string{"键盘按键", "手柄按键", "执行命令"}, nil)
 	targetTypeSelect.SetSelected("键盘按键")
 
 	// ===== 键盘目标部分 =====
 	targetKeys := keyboard.AllKeys()
 	targetKeyOptions := make([]string, len(targetKeys))
 	for i, key := range targetKeys {
-		targetKeyOptions[i] = key.String()
+		targetKeyOptions[i] = key.Name()
 	}
 
+	// 快捷键文本是键盘目标的最终结果，复选框和录制都会更新它
+	shortcutEntry := widget.NewEntry()
+	shortcutEntry.SetPlaceHolder("如 Ctrl+Shift+Esc、W+D、RCtrl")
+	syncing := false // 防止快捷键文本与复选框互相触发
+
 	selectedKeyTargets := make(map[int]bool)
+	var updateShortcutText func()
 	keyboardCheckGroup := widget.NewCheckGroup(targetKeyOptions, func(selected []string) {
 		selectedKeyTargets = make(map[int]bool)
 		for _, sel := range selected {
@@ -46,23 +65,87 @@ func ShowMappingForm(parent fyne.Window, appCtrl *app.App, editID *string) {
 				}
 			}
 		}
+		updateShortcutText()
 	})
 	keyboardScroll := container.NewVScroll(keyboardCheckGroup)
 	keyboardScroll.SetMinSize(fyne.NewSize(200, 120))
 
 	// 修饰键复选框
-	ctrlCheck := widget.NewCheck("Ctrl", nil)
-	altCheck := widget.NewCheck("Alt", nil)
-	shiftCheck := widget.NewCheck("Shift", nil)
+	onModifierChanged := func(bool) { updateShortcutText() }
+	ctrlCheck := widget.NewCheck("Ctrl", onModifierChanged)
+	altCheck := widget.NewCheck("Alt", onModifierChanged)
+	shiftCheck := widget.NewCheck("Shift", onModifierChanged)
+	winCheck := widget.NewCheck("Win", onModifierChanged)
 	modifiersBox := container.NewHBox(
 		widget.NewLabel("修饰键:"),
 		ctrlCheck,
 		altCheck,
 		shiftCheck,
+		winCheck,
 	)
 
+	// 复选框修改后重新生成快捷键文本（按列表顺序）
+	updateShortcutText = func() {
+		if syncing {
+			return
+		}
+		var keys []keyboard.KeyCode
+		for idx, key := range targetKeys {
+			if selectedKeyTargets[idx] {
+				keys = append(keys, key)
+			}
+		}
+		mods := keyboard.Modifiers{
+			Ctrl:  ctrlCheck.Checked,
+			Alt:   altCheck.Checked,
+			Shift: shiftCheck.Checked,
+			Win:   winCheck.Checked,
+		}
+
+		syncing = true
+		shortcutEntry.SetText(keyboard.FormatShortcut(keys, mods))
+		syncing = false
+	}
+
+	// 快捷键文本（输入或录制）修改后同步复选框
+	applyShortcut := func(keys []keyboard.KeyCode, mods keyboard.Modifiers) {
+		var names []string
+		for _, key := range keys {
+			if !containsKey(targetKeys, key) {
+				// 列表中没有的按键（如右侧修饰键）加入列表
+				targetKeys = append(targetKeys, key)
+				targetKeyOptions = append(targetKeyOptions, key.Name())
+				keyboardCheckGroup.Options = targetKeyOptions
+			}
+			names = append(names, key.Name())
+		}
+
+		syncing = true
+		keyboardCheckGroup.SetSelected(names)
+		ctrlCheck.SetChecked(mods.Ctrl)
+		altCheck.SetChecked(mods.Alt)
+		shiftCheck.SetChecked(mods.Shift)
+		winCheck.SetChecked(mods.Win)
+		syncing = false
+	}
+	shortcutEntry.OnChanged = func(text string) {
+		if syncing {
+			return
+		}
+		if keys, mods, err := keyboard.ParseShortcut(text); err == nil {
+			applyShortcut(keys, mods)
+		}
+	}
+
+	recorder := newShortcutRecorder(func(keys []keyboard.KeyCode, mods keyboard.Modifiers) {
+		shortcutEntry.SetText(keyboard.FormatShortcut(keys, mods))
+	}, func(err error) {
+		dialog.ShowError(err, parent)
+	})
+
 	keyboardContainer := container.NewVBox(
-		widget.NewLabel("目标按键 (键盘) - 可多选"),
+		widget.NewLabel("目标按键 (键盘) - 输入快捷键、点击录制后按下，或在列表中多选"),
+		container.NewBorder(nil, nil, nil, recorder, shortcutEntry),
 		keyboardScroll,
 		modifiersBox,
 	)
@@ -96,21 +179,103 @@ func ShowMappingForm(parent fyne.Window, appCtrl *app.App, editID *string) {
 	)
 	gamepadContainer.Hide() // 默认隐藏
 
+	// ===== 命令目标部分 =====
+	execModes := []string{"启动程序", "Shell 命令", "打开URL/文件"}
+	execModeSelect := widget.NewSelect(execModes, nil)
+	execModeSelect.SetSelected(execModes[0])
+
+	execCommandEntry := widget.NewEntry()
+	execCommandEntry.SetPlaceHolder("程序路径 / 命令行 / URL")
+	execArgsEntry := widget.NewMultiLineEntry()
+	execArgsEntry.SetPlaceHolder("参数（每行一个，仅启动程序）")
+	execArgsEntry.SetMinRowsVisible(2)
+	execDirEntry := widget.NewEntry()
+	execDirEntry.SetPlaceHolder("工作目录（可选）")
+	execEnvEntry := widget.NewMultiLineEntry()
+	execEnvEntry.SetPlaceHolder("环境变量 KEY=VALUE（每行一个，可选）")
+	execEnvEntry.SetMinRowsVisible(2)
+
+	execOnPressCheck := widget.NewCheck("按下时执行", nil)
+	execOnPressCheck.SetChecked(true)
+	execOnReleaseCheck := widget.NewCheck("释放时执行", nil)
+
+	allowExecCheck := widget.NewCheck("允许本配置执行命令", func(checked bool) {
+		if err := appCtrl.SetAllowExec(checked); err != nil {
+			dialog.ShowError(err, parent)
+		}
+	})
+	allowExecCheck.Checked = appCtrl.ExecAllowed()
+
+	execContainer := container.NewVBox(
+		widget.NewLabel("执行方式"),
+		execModeSelect,
+		execCommandEntry,
+		execArgsEntry,
+		execDirEntry,
+		execEnvEntry,
+		container.NewHBox(execOnPressCheck, execOnReleaseCheck),
+		allowExecCheck,
+	)
+	execContainer.Hide() // 默认隐藏
+
 	// 目标容器（切换显示）
-	targetContainer := container.NewStack(keyboardContainer, gamepadContainer)
+	targetContainer := container.NewStack(keyboardContainer, gamepadContainer, execContainer)
 
 	// 目标类型切换逻辑
 	targetTypeSelect.OnChanged = func(selected string) {
-		if selected == "键盘按键" {
+		keyboardContainer.Hide()
+		gamepadContainer.Hide()
+		execContainer.Hide()
+		switch selected {
+		case "键盘按键":
 			keyboardContainer.Show()
-			gamepadContainer.Hide()
-		} else {
-			keyboardContainer.Hide()
+		case "手柄按键":
 			gamepadContainer.Show()
+		default:
+			execContainer.Show()
 		}
 		targetContainer.Refresh()
 	}
 
+	// 编辑时填入原规则
+	if editing != nil {
+		fillMappingForm(editing, mappingFormFields{
+			source:        sourceSelect,
+			targetType:    targetTypeSelect,
+			shortcut:      shortcutEntry,
+			buttons:       gamepadCheckGroup,
+			execMode:      execModeSelect,
+			execCommand:   execCommandEntry,
+			execArgs:      execArgsEntry,
+			execDir:       execDirEntry,
+			execEnv:       execEnvEntry,
+			execOnPress:   execOnPressCheck,
+			execOnRelease: execOnReleaseCheck,
+		})
+	}
+
+	// 按下手柄按键选择源按键
+	listenBtn := widget.NewButton("监听", func() {
+		showCaptureDialog(parent, appCtrl, func(buttons []gamepad.Button) {
+			sourceSelect.SetSelected(buttons[0].String())
+			if len(buttons) > 1 {
+				dialog.ShowInformation("组合键",
+					"映射规则只能使用单个源按键，已选择最先按下的 "+buttons[0].String(), parent)
+			}
+		})
+	})
+
+	// 名称和说明（可选）
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("规则名称（可选）")
+	descEntry := widget.NewMultiLineEntry()
+	descEntry.SetPlaceHolder("说明（可选）")
+	descEntry.SetMinRowsVisible(2)
+	if editing != nil {
+		nameEntry.SetText(editing.Name)
+		descEntry.SetText(editing.Description)
+	}
+
 	// 提示标签
 	tipLabel := widget.NewLabel("按住源按键时，目标按键也会保持按住状态")
 	tipLabel.Wrapping = fyne.TextWrapWord
@@ -118,19 +283,27 @@ func ShowMappingForm(parent fyne.Window, appCtrl *app.App, editID *string) {
 	// 表单内容
 	formContent := container.NewVBox(
 		widget.NewLabel("源按键 (手柄)"),
-		sourceSelect,
+		container.NewBorder(nil, nil, nil, listenBtn, sourceSelect),
 		widget.NewSeparator(),
 		widget.NewLabel("目标类型"),
 		targetTypeSelect,
 		widget.NewSeparator(),
 		targetContainer,
 		widget.NewSeparator(),
+		nameEntry,
+		descEntry,
+		widget.NewSeparator(),
 		tipLabel,
 	)
 
+	title := "添加按键映射"
+	if editing != nil {
+		title = "编辑按键映射"
+	}
+
 	// 创建对话框
 	d := dialog.NewCustomConfirm(
-		"添加按键映射",
+		title,
 		"确定",
 		"取消",
 		formContent,
@@ -155,30 +328,60 @@ func ShowMappingForm(parent fyne.Window, appCtrl *app.App, editID *string) {
 			if editID != nil {
 				excludeID = *editID
 			}
-			if appCtrl.HasConflict(sourceKey, excludeID) {
-				dialog.ShowError(errors.New("源按键已存在映射，请选择其他按键"), parent)
+			if (editing == nil || editing.Enabled) && appCtrl.HasConflict(sourceKey, excludeID) {
+				dialog.ShowError(errors.New("源按键已存在启用的映射，请选择其他按键或先停用原规则"), parent)
 				return
 			}
 
 			if targetTypeSelect.Selected == "键盘按键" {
 				// 键盘映射
-				if len(selectedKeyTargets) == 0 {
+				if strings.TrimSpace(shortcutEntry.Text) == "" {
 					dialog.ShowError(errors.New("请至少选择一个目标按键"), parent)
 					return
 				}
+				targets, mods, err := keyboard.ParseShortcut(shortcutEntry.Text)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("快捷键格式错误: %w", err), parent)
+					return
+				}
 
-				var targets []keyboard.KeyCode
-				for idx := range selectedKeyTargets {
-					targets = append(targets, targetKeys[idx])
+				if editing != nil {
+					err = updateRule(appCtrl, editing, mapper.NewRuleMultiKeys(editing.ID, sourceKey, targets, mods), nameEntry.Text, descEntry.Text)
+				} else {
+					var added *mapper.MappingRule
+					if added, err = appCtrl.AddRuleMultiKeys(sourceKey, targets, mods); err == nil {
+						err = setRuleInfo(appCtrl, added, nameEntry.Text, descEntry.Text)
+					}
+				}
+				if err != nil {
+					dialog.ShowError(err, parent)
+					return
+				}
+			} else if targetTypeSelect.Selected == "执行命令" {
+				// 命令执行
+				action := &mapper.ExecAction{
+					Mode:      mapper.ExecMode(execModeSelect.SelectedIndex()),
+					Command:   strings.TrimSpace(execCommandEntry.Text),
+					Args:      splitLines(execArgsEntry.Text),
+					Dir:       strings.TrimSpace(execDirEntry.Text),
+					Env:       splitLines(execEnvEntry.Text),
+					OnPress:   execOnPressCheck.Checked,
+					OnRelease: execOnReleaseCheck.Checked,
 				}
 
-				mods := keyboard.Modifiers{
-					Ctrl:  ctrlCheck.Checked,
-					Alt:   altCheck.Checked,
-					Shift: shiftCheck.Checked,
+				if editing != nil && editing.Exec != nil {
+					action.Timeout = editing.Exec.Timeout // 表单中没有超时设置，保留原设置
 				}
 
-				_, err := appCtrl.AddRuleMultiKeys(sourceKey, targets, mods)
+				var err error
+				if editing != nil {
+					err = updateRule(appCtrl, editing, mapper.NewRuleExec(editing.ID, sourceKey, action), nameEntry.Text, descEntry.Text)
+				} else {
+					var added *mapper.MappingRule
+					if added, err = appCtrl.AddRuleExec(sourceKey, action); err == nil {
+						err = setRuleInfo(appCtrl, added, nameEntry.Text, descEntry.Text)
+					}
+				}
 				if err != nil {
 					dialog.ShowError(err, parent)
 					return
@@ -191,11 +394,21 @@ func ShowMappingForm(parent fyne.Window, appCtrl *app.App, editID *string) {
 				}
 
 				var targets []gamepad.Button
-				for idx := range selectedBtnTargets {
-					targets = append(targets, targetButtons[idx])
+				for idx, btn := range targetButtons {
+					if selectedBtnTargets[idx] {
+						targets = append(targets, btn)
+					}
 				}
 
-				_, err := appCtrl.AddRuleGamepad(sourceKey, targets)
+				var err error
+				if editing != nil {
+					err = updateRule(appCtrl, editing, mapper.NewRuleGamepad(editing.ID, sourceKey, targets), nameEntry.Text, descEntry.Text)
+				} else {
+					var added *mapper.MappingRule
+					if added, err = appCtrl.AddRuleGamepad(sourceKey, targets); err == nil {
+						err = setRuleInfo(appCtrl, added, nameEntry.Text, descEntry.Text)
+					}
+				}
 				if err != nil {
 					dialog.ShowError(err, parent)
 					return
@@ -205,6 +418,90 @@ func ShowMappingForm(parent fyne.Window, appCtrl *app.App, editID *string) {
 		parent,
 	)
 
-	d.Resize(fyne.NewSize(420, 550))
+	d.Resize(fyne.NewSize(420, 640))
 	d.Show()
 }
+
+// mappingFormFields 映射表单中需要根据规则填写的控件
+type mappingFormFields struct {
+	source        *widget.Select
+	targetType    *widget.Select
+	shortcut      *widget.Entry
+	buttons       *widget.CheckGroup
+	execMode      *widget.Select
+	execCommand   *widget.Entry
+	execArgs      *widget.Entry
+	execDir       *widget.Entry
+	execEnv       *widget.Entry
+	execOnPress   *widget.Check
+	execOnRelease *widget.Check
+}
+
+// fillMappingForm 用已有规则填写表单
+func fillMappingForm(rule *mapper.MappingRule, f mappingFormFields) {
+	f.source.SetSelected(rule.SourceKey.String())
+
+	switch rule.TargetType {
+	case mapper.TargetGamepad:
+		f.targetType.SetSelected("手柄按键")
+		var names []string
+		for _, btn := range rule.TargetButtons {
+			names = append(names, btn.String())
+		}
+		f.buttons.SetSelected(names)
+
+	case mapper.TargetExec:
+		f.targetType.SetSelected("执行命令")
+		if rule.Exec != nil {
+			f.execMode.SetSelectedIndex(int(rule.Exec.Mode))
+			f.execCommand.SetText(rule.Exec.Command)
+			f.execArgs.SetText(strings.Join(rule.Exec.Args, "\n"))
+			f.execDir.SetText(rule.Exec.Dir)
+			f.execEnv.SetText(strings.Join(rule.Exec.Env, "\n"))
+			f.execOnPress.SetChecked(rule.Exec.OnPress)
+			f.execOnRelease.SetChecked(rule.Exec.OnRelease)
+		}
+
+	default:
+		f.targetType.SetSelected("键盘按键")
+		f.shortcut.SetText(keyboard.FormatShortcut(rule.TargetKeys, rule.Modifiers))
+	}
+}
+
+// updateRule 用表单生成的规则替换原规则（保留启用状态）
+func updateRule(appCtrl *app.App, old *mapper.MappingRule, rule *mapper.MappingRule, name string, description string) error {
+	rule.Name = strings.TrimSpace(name)
+	rule.Description = strings.TrimSpace(description)
+	rule.Enabled = old.Enabled
+	return appCtrl.UpdateRule(rule)
+}
+
+// setRuleInfo 设置新添加规则的名称和说明（都为空时不做修改）
+func setRuleInfo(appCtrl *app.App, rule *mapper.MappingRule, name string, description string) error {
+	if strings.TrimSpace(name) == "" && strings.TrimSpace(description) == "" {
+		return nil
+	}
+	return appCtrl.SetRuleInfo(rule.ID, name, description)
+}
+
+// containsKey 检查按键列表中是否包含指定按键
+func containsKey(keys []keyboard.KeyCode, key keyboard.KeyCode) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// splitLines 按行拆分文本，忽略空行
+func splitLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
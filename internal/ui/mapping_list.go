@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"strings"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
@@ -8,6 +10,7 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"gamepad-key-mapper/internal/app"
+	"gamepad-key-mapper/internal/mapper"
 )
 
 // MappingList 映射列表组件
@@ -16,6 +19,8 @@ type MappingList struct {
 	parent    fyne.Window
 	container *fyne.Container
 	list      *widget.List
+
+	diagnostics map[string][]mapper.Diagnostic // 按规则ID分组的检查结果
 }
 
 // NewMappingList 创建映射列表
@@ -38,6 +43,7 @@ func NewMappingList(appCtrl *app.App, parent fyne.Window) *MappingList {
 	)
 
 	ml.container = container.NewStack(ml.list)
+	ml.updateDiagnostics()
 
 	return ml
 }
@@ -49,16 +55,39 @@ func (ml *MappingList) Container() *fyne.Container {
 
 // Refresh 刷新列表
 func (ml *MappingList) Refresh() {
+	ml.updateDiagnostics()
 	ml.list.Refresh()
 }
 
+// updateDiagnostics 重新检查当前规则
+func (ml *MappingList) updateDiagnostics() {
+	ml.diagnostics = make(map[string][]mapper.Diagnostic)
+	for _, d := range ml.appCtrl.Diagnostics() {
+		ml.diagnostics[d.RuleID] = append(ml.diagnostics[d.RuleID], d)
+	}
+}
+
 // createListItem 创建列表项模板
 func (ml *MappingList) createListItem() fyne.CanvasObject {
+	enableCheck := widget.NewCheck("", nil)
+	statusIcon := widget.NewIcon(nil)
 	label := widget.NewLabel("映射规则")
+	label.Truncation = fyne.TextTruncateEllipsis
+
+	upBtn := widget.NewButtonWithIcon("", theme.MoveUpIcon(), nil)
+	upBtn.Importance = widget.LowImportance
+	downBtn := widget.NewButtonWithIcon("", theme.MoveDownIcon(), nil)
+	downBtn.Importance = widget.LowImportance
+	editBtn := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), nil)
+	editBtn.Importance = widget.LowImportance
 	deleteBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
 	deleteBtn.Importance = widget.LowImportance
 
-	return container.NewBorder(nil, nil, nil, deleteBtn, label)
+	return container.NewBorder(nil, nil,
+		container.NewHBox(enableCheck, statusIcon),
+		container.NewHBox(upBtn, downBtn, editBtn, deleteBtn),
+		label,
+	)
 }
 
 // updateListItem 更新列表项内容
@@ -69,20 +98,92 @@ func (ml *MappingList) updateListItem(id widget.ListItemID, item fyne.CanvasObje
 	}
 
 	rule := rules[id]
+	ruleID := rule.ID // 捕获当前规则ID
 	border := item.(*fyne.Container)
 
-	// 更新标签
+	// 更新标签（有名称时显示名称，有问题的规则附带说明，停用的规则变暗）
 	label := border.Objects[0].(*widget.Label)
-	label.SetText(rule.String())
+	diags := ml.diagnostics[rule.ID]
+	text := rule.String()
+	if rule.Name != "" {
+		text = rule.Name + "：" + text
+	}
+	if len(diags) > 0 {
+		messages := make([]string, 0, len(diags))
+		for _, d := range diags {
+			messages = append(messages, d.Message)
+		}
+		text += "  ⚠ " + strings.Join(messages, "；")
+	}
+	label.Text = text
+	if rule.Enabled {
+		label.Importance = widget.MediumImportance
+	} else {
+		label.Importance = widget.LowImportance
+	}
+	label.Refresh()
+
+	// 更新启用开关（先清除回调，避免复用列表项时触发）
+	left := border.Objects[1].(*fyne.Container)
+	enableCheck := left.Objects[0].(*widget.Check)
+	enableCheck.OnChanged = nil
+	enableCheck.SetChecked(rule.Enabled)
+	enableCheck.OnChanged = func(checked bool) {
+		if err := ml.appCtrl.SetRuleEnabled(ruleID, checked); err != nil {
+			dialog.ShowError(err, ml.parent)
+			ml.Refresh() // 恢复开关状态
+		}
+	}
 
-	// 更新删除按钮
-	deleteBtn := border.Objects[1].(*widget.Button)
-	ruleID := rule.ID // 捕获当前规则ID
+	// 更新状态图标
+	statusIcon := left.Objects[1].(*widget.Icon)
+	switch {
+	case mapper.HasErrors(diags):
+		statusIcon.SetResource(theme.ErrorIcon())
+	case len(diags) > 0:
+		statusIcon.SetResource(theme.WarningIcon())
+	default:
+		statusIcon.SetResource(nil)
+	}
+
+	// 更新排序、编辑和删除按钮
+	buttons := border.Objects[2].(*fyne.Container)
+	upBtn := buttons.Objects[0].(*widget.Button)
+	downBtn := buttons.Objects[1].(*widget.Button)
+	editBtn := buttons.Objects[2].(*widget.Button)
+	deleteBtn := buttons.Objects[3].(*widget.Button)
+
+	if id == 0 {
+		upBtn.Disable()
+	} else {
+		upBtn.Enable()
+	}
+	if id == len(rules)-1 {
+		downBtn.Disable()
+	} else {
+		downBtn.Enable()
+	}
+	upBtn.OnTapped = func() {
+		ml.moveRule(ruleID, -1)
+	}
+	downBtn.OnTapped = func() {
+		ml.moveRule(ruleID, 1)
+	}
+	editBtn.OnTapped = func() {
+		ShowMappingForm(ml.parent, ml.appCtrl, &ruleID)
+	}
 	deleteBtn.OnTapped = func() {
 		ml.confirmDelete(ruleID, rule.String())
 	}
 }
 
+// moveRule 调整规则顺序
+func (ml *MappingList) moveRule(ruleID string, delta int) {
+	if err := ml.appCtrl.MoveRule(ruleID, delta); err != nil {
+		dialog.ShowError(err, ml.parent)
+	}
+}
+
 // confirmDelete 确认删除对话框
 func (ml *MappingList) confirmDelete(ruleID string, ruleDesc string) {
 	dialog.ShowConfirm(
@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"gamepad-key-mapper/internal/app"
+)
+
+// ProfileBar 方案选择栏
+type ProfileBar struct {
+	appCtrl   *app.App
+	parent    fyne.Window
+	container *fyne.Container
+	selector  *widget.Select
+	autoCheck *widget.Check
+
+	updating bool // 刷新选项时忽略选择回调
+}
+
+// NewProfileBar 创建方案选择栏
+func NewProfileBar(appCtrl *app.App, parent fyne.Window) *ProfileBar {
+	pb := &ProfileBar{
+		appCtrl: appCtrl,
+		parent:  parent,
+	}
+
+	pb.selector = widget.NewSelect(nil, pb.onSelect)
+
+	newBtn := widget.NewButtonWithIcon("", theme.ContentAddIcon(), pb.onCreate)
+	renameBtn := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), pb.onRename)
+	duplicateBtn := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), pb.onDuplicate)
+	deleteBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), pb.onDelete)
+	importBtn := widget.NewButtonWithIcon("", theme.DownloadIcon(), pb.onImport)
+	exportBtn := widget.NewButtonWithIcon("", theme.UploadIcon(), pb.onExport)
+
+	pb.autoCheck = widget.NewCheck("按窗口自动切换", func(checked bool) {
+		if pb.updating {
+			return
+		}
+		if err := pb.appCtrl.SetAutoSwitch(checked); err != nil {
+			dialog.ShowError(err, pb.parent)
+		}
+	})
+
+	pb.container = container.NewBorder(
+		nil, nil,
+		widget.NewLabel("方案:"),
+		container.NewHBox(newBtn, renameBtn, duplicateBtn, deleteBtn, importBtn, exportBtn, pb.autoCheck),
+		pb.selector,
+	)
+
+	pb.Refresh()
+	return pb
+}
+
+// Container 返回容器
+func (pb *ProfileBar) Container() *fyne.Container {
+	return pb.container
+}
+
+// Refresh 根据当前方案列表刷新选项
+func (pb *ProfileBar) Refresh() {
+	pb.updating = true
+	defer func() { pb.updating = false }()
+
+	pb.selector.Options = pb.appCtrl.ProfileNames()
+	pb.selector.SetSelected(pb.appCtrl.ActiveProfile())
+	pb.autoCheck.SetChecked(pb.appCtrl.AutoSwitchEnabled())
+}
+
+// onSelect 选择方案
+func (pb *ProfileBar) onSelect(name string) {
+	if pb.updating {
+		return
+	}
+	if err := pb.appCtrl.ActivateProfile(name); err != nil {
+		dialog.ShowError(err, pb.parent)
+	}
+}
+
+// onCreate 新建方案
+func (pb *ProfileBar) onCreate() {
+	pb.askName("新建方案", "", func(name string) error {
+		if err := pb.appCtrl.CreateProfile(name); err != nil {
+			return err
+		}
+		return pb.appCtrl.ActivateProfile(name)
+	})
+}
+
+// onRename 重命名当前方案
+func (pb *ProfileBar) onRename() {
+	current := pb.appCtrl.ActiveProfile()
+	pb.askName("重命名方案", current, func(name string) error {
+		return pb.appCtrl.RenameProfile(current, name)
+	})
+}
+
+// onDuplicate 复制当前方案
+func (pb *ProfileBar) onDuplicate() {
+	current := pb.appCtrl.ActiveProfile()
+	pb.askName("复制方案", current+" 副本", func(name string) error {
+		if err := pb.appCtrl.DuplicateProfile(current, name); err != nil {
+			return err
+		}
+		return pb.appCtrl.ActivateProfile(name)
+	})
+}
+
+// onDelete 删除当前方案
+func (pb *ProfileBar) onDelete() {
+	current := pb.appCtrl.ActiveProfile()
+	dialog.ShowConfirm(
+		"确认删除",
+		"确定要删除方案 \""+current+"\" 及其所有规则吗？",
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := pb.appCtrl.DeleteProfile(current); err != nil {
+				dialog.ShowError(err, pb.parent)
+			}
+		},
+		pb.parent,
+	)
+}
+
+// askName 显示方案名称输入对话框
+func (pb *ProfileBar) askName(title string, initial string, apply func(string) error) {
+	entry := widget.NewEntry()
+	entry.SetText(initial)
+
+	dialog.ShowForm(title, "确定", "取消",
+		[]*widget.FormItem{widget.NewFormItem("名称", entry)},
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := apply(entry.Text); err != nil {
+				dialog.ShowError(err, pb.parent)
+			}
+		},
+		pb.parent,
+	)
+}
@@ -0,0 +1,216 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+
+	"gamepad-key-mapper/internal/keyboard"
+)
+
+// fyneKeys 界面按键名称到虚拟键码的对应关系（字母和数字键另行处理）
+var fyneKeys = map[fyne.KeyName]keyboard.KeyCode{
+	fyne.KeyEscape:    keyboard.KeyEscape,
+	fyne.KeyReturn:    keyboard.KeyEnter,
+	fyne.KeyEnter:     keyboard.KeyEnter,
+	fyne.KeyTab:       keyboard.KeyTab,
+	fyne.KeyBackspace: keyboard.KeyBackspace,
+	fyne.KeyInsert:    keyboard.KeyInsert,
+	fyne.KeyDelete:    keyboard.KeyDelete,
+	fyne.KeyHome:      keyboard.KeyHome,
+	fyne.KeyEnd:       keyboard.KeyEnd,
+	fyne.KeyPageUp:    keyboard.KeyPageUp,
+	fyne.KeyPageDown:  keyboard.KeyPageDown,
+	fyne.KeyUp:        keyboard.KeyUp,
+	fyne.KeyDown:      keyboard.KeyDown,
+	fyne.KeyLeft:      keyboard.KeyLeft,
+	fyne.KeyRight:     keyboard.KeyRight,
+	fyne.KeySpace:     keyboard.KeySpace,
+	fyne.KeyF1:        keyboard.KeyF1,
+	fyne.KeyF2:        keyboard.KeyF2,
+	fyne.KeyF3:        keyboard.KeyF3,
+	fyne.KeyF4:        keyboard.KeyF4,
+	fyne.KeyF5:        keyboard.KeyF5,
+	fyne.KeyF6:        keyboard.KeyF6,
+	fyne.KeyF7:        keyboard.KeyF7,
+	fyne.KeyF8:        keyboard.KeyF8,
+	fyne.KeyF9:        keyboard.KeyF9,
+	fyne.KeyF10:       keyboard.KeyF10,
+	fyne.KeyF11:       keyboard.KeyF11,
+	fyne.KeyF12:       keyboard.KeyF12,
+
+	desktop.KeyShiftLeft:    keyboard.KeyLShift,
+	desktop.KeyShiftRight:   keyboard.KeyRShift,
+	desktop.KeyControlLeft:  keyboard.KeyLCtrl,
+	desktop.KeyControlRight: keyboard.KeyRCtrl,
+	desktop.KeyAltLeft:      keyboard.KeyLAlt,
+	desktop.KeyAltRight:     keyboard.KeyRAlt,
+	desktop.KeySuperLeft:    keyboard.KeyLWin,
+	desktop.KeySuperRight:   keyboard.KeyRWin,
+}
+
+// fyneKeyCode 将界面按键名称转换为虚拟键码
+func fyneKeyCode(name fyne.KeyName) (keyboard.KeyCode, bool) {
+	if len(name) == 1 {
+		c := name[0]
+		if (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			return keyboard.KeyCode(c), true // 字母和数字的虚拟键码与 ASCII 相同
+		}
+	}
+	key, ok := fyneKeys[name]
+	return key, ok
+}
+
+// recordedShortcut 将按下的按键（按按下顺序）转换为目标按键和修饰键
+//
+// 左侧修饰键与其它键一起按下时作为修饰键（Ctrl/Alt/Shift/Win），右侧修饰键保留为按键，
+// 只按了修饰键时所有修饰键都作为按键。
+func recordedShortcut(keys []keyboard.KeyCode) ([]keyboard.KeyCode, keyboard.Modifiers) {
+	var targets []keyboard.KeyCode
+	var mods keyboard.Modifiers
+	for _, key := range keys {
+		switch key {
+		case keyboard.KeyLCtrl:
+			mods.Ctrl = true
+		case keyboard.KeyLAlt:
+			mods.Alt = true
+		case keyboard.KeyLShift:
+			mods.Shift = true
+		case keyboard.KeyLWin:
+			mods.Win = true
+		default:
+			targets = append(targets, key)
+		}
+	}
+
+	if len(targets) == 0 {
+		return keys, keyboard.Modifiers{}
+	}
+	return targets, mods
+}
+
+// shortcutRecorder 快捷键录制按钮：点击后按下组合键，全部松开时完成录制
+type shortcutRecorder struct {
+	widget.BaseWidget
+
+	button     *widget.Button
+	recording  bool
+	held       map[fyne.KeyName]bool
+	pressed    []keyboard.KeyCode
+	unknown    fyne.KeyName // 录制中按下的不支持的按键
+	onRecorded func(keys []keyboard.KeyCode, mods keyboard.Modifiers)
+	onError    func(error)
+}
+
+// newShortcutRecorder 创建快捷键录制按钮
+func newShortcutRecorder(onRecorded func([]keyboard.KeyCode, keyboard.Modifiers), onError func(error)) *shortcutRecorder {
+	r := &shortcutRecorder{onRecorded: onRecorded, onError: onError}
+	r.button = widget.NewButton("录制", r.start)
+	r.ExtendBaseWidget(r)
+	return r
+}
+
+// CreateRenderer 实现 fyne.Widget
+func (r *shortcutRecorder) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(r.button)
+}
+
+// start 开始录制（获取键盘焦点）
+func (r *shortcutRecorder) start() {
+	c := fyne.CurrentApp().Driver().CanvasForObject(r)
+	if c == nil {
+		return
+	}
+	r.recording = true
+	r.held = make(map[fyne.KeyName]bool)
+	r.pressed = nil
+	r.unknown = ""
+	r.button.SetText("请按下快捷键…")
+	r.button.Importance = widget.HighImportance
+	r.button.Refresh()
+	c.Focus(r)
+}
+
+// stop 结束录制并释放键盘焦点
+func (r *shortcutRecorder) stop() {
+	r.reset()
+	if c := fyne.CurrentApp().Driver().CanvasForObject(r); c != nil && c.Focused() == r {
+		c.Unfocus()
+	}
+}
+
+// FocusGained 实现 fyne.Focusable
+func (r *shortcutRecorder) FocusGained() {}
+
+// reset 退出录制状态
+func (r *shortcutRecorder) reset() {
+	r.recording = false
+	r.button.SetText("录制")
+	r.button.Importance = widget.MediumImportance
+	r.button.Refresh()
+}
+
+// FocusLost 失去焦点时取消录制
+func (r *shortcutRecorder) FocusLost() {
+	if r.recording {
+		r.reset()
+	}
+}
+
+// TypedRune 实现 fyne.Focusable（按键在 KeyDown/KeyUp 中处理）
+func (r *shortcutRecorder) TypedRune(rune) {}
+
+// TypedKey 实现 fyne.Focusable（按键在 KeyDown/KeyUp 中处理）
+func (r *shortcutRecorder) TypedKey(*fyne.KeyEvent) {}
+
+// AcceptsTab 录制时 Tab 作为普通按键
+func (r *shortcutRecorder) AcceptsTab() bool {
+	return r.recording
+}
+
+// KeyDown 实现 desktop.Keyable，记录按下的按键
+func (r *shortcutRecorder) KeyDown(event *fyne.KeyEvent) {
+	if !r.recording || r.held[event.Name] {
+		return
+	}
+	r.held[event.Name] = true
+
+	key, ok := fyneKeyCode(event.Name)
+	if !ok {
+		r.unknown = event.Name
+		return
+	}
+	for _, k := range r.pressed {
+		if k == key {
+			return
+		}
+	}
+	r.pressed = append(r.pressed, key)
+	r.button.SetText(keyboard.FormatShortcut(r.pressed, keyboard.Modifiers{}))
+}
+
+// KeyUp 实现 desktop.Keyable，全部松开时完成录制
+func (r *shortcutRecorder) KeyUp(event *fyne.KeyEvent) {
+	if !r.recording || !r.held[event.Name] {
+		return
+	}
+	delete(r.held, event.Name)
+	if len(r.held) > 0 {
+		return
+	}
+
+	pressed, unknown := r.pressed, r.unknown
+	r.stop()
+
+	if unknown != "" {
+		if r.onError != nil {
+			r.onError(fmt.Errorf("不支持的按键 %s", unknown))
+		}
+		return
+	}
+	if len(pressed) > 0 && r.onRecorded != nil {
+		r.onRecorded(recordedShortcut(pressed))
+	}
+}
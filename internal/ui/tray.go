@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/driver/desktop"
 
 	"gamepad-key-mapper/internal/app"
@@ -14,8 +15,9 @@ type Tray struct {
 	appCtrl *app.App
 	menu    *fyne.Menu
 	
-	startItem *fyne.MenuItem
-	stopItem  *fyne.MenuItem
+	startItem   *fyne.MenuItem
+	stopItem    *fyne.MenuItem
+	profileItem *fyne.MenuItem
 }
 
 // NewTray 创建系统托盘
@@ -46,6 +48,10 @@ func (t *Tray) setup() {
 	t.stopItem = fyne.NewMenuItem("停止", t.onStop)
 	t.stopItem.Disabled = true
 
+	// 方案子菜单
+	t.profileItem = fyne.NewMenuItem("方案", nil)
+	t.profileItem.ChildMenu = t.buildProfileMenu()
+
 	separator := fyne.NewMenuItemSeparator()
 	showItem := fyne.NewMenuItem("显示窗口", t.onShow)
 	quitItem := fyne.NewMenuItem("退出", t.onQuit)
@@ -55,6 +61,8 @@ func (t *Tray) setup() {
 		t.startItem,
 		t.stopItem,
 		separator,
+		t.profileItem,
+		separator,
 		showItem,
 		separator,
 		quitItem,
@@ -62,19 +70,49 @@ func (t *Tray) setup() {
 
 	// 设置托盘菜单
 	desk.SetSystemTrayMenu(t.menu)
+}
 
-	// 设置状态变更回调以同步菜单状态
-	t.appCtrl.SetOnStateChange(func(state app.State) {
-		t.updateMenuState(state)
-	})
+// buildProfileMenu 创建方案子菜单（勾选当前方案）
+func (t *Tray) buildProfileMenu() *fyne.Menu {
+	active := t.appCtrl.ActiveProfile()
+
+	var items []*fyne.MenuItem
+	for _, name := range t.appCtrl.ProfileNames() {
+		profileName := name // 捕获当前方案名
+		item := fyne.NewMenuItem(profileName, func() {
+			if err := t.appCtrl.ActivateProfile(profileName); err != nil {
+				t.showError(err)
+			}
+		})
+		item.Checked = profileName == active
+		items = append(items, item)
+	}
+
+	return fyne.NewMenu("方案", items...)
+}
+
+// updateProfiles 更新方案子菜单
+func (t *Tray) updateProfiles() {
+	if t.menu == nil {
+		return
+	}
+	t.profileItem.ChildMenu = t.buildProfileMenu()
+	t.menu.Refresh()
 }
 
 // updateMenuState 更新菜单状态
 func (t *Tray) updateMenuState(state app.State) {
+	if t.menu == nil {
+		return
+	}
+
 	switch state {
 	case app.StateRunning:
 		t.startItem.Disabled = true
 		t.stopItem.Disabled = false
+	case app.StatePaused:
+		t.startItem.Disabled = false
+		t.stopItem.Disabled = false
 	case app.StateStopped:
 		t.startItem.Disabled = false
 		t.stopItem.Disabled = true
@@ -97,9 +135,16 @@ func (t *Tray) onShow() {
 	t.window.Show()
 }
 
+// showError 显示窗口并弹出错误提示（窗口可能已最小化到托盘）
+func (t *Tray) showError(err error) {
+	t.window.Show()
+	dialog.ShowError(err, t.window)
+}
+
 // onQuit 退出程序
 func (t *Tray) onQuit() {
 	t.appCtrl.Stop()
+	t.appCtrl.StopWatchingConfig()
 	t.app.Quit()
 }
 
@@ -0,0 +1,258 @@
+package ui
+
+import (
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"gamepad-key-mapper/internal/app"
+	"gamepad-key-mapper/internal/gamepad"
+)
+
+// visualizerInterval 手柄状态显示的刷新间隔（约 30Hz）
+const visualizerInterval = time.Second / 30
+
+const (
+	stickRadius   = 36  // 摇杆活动范围半径
+	knobRadius    = 8   // 摇杆位置标记半径
+	triggerWidth  = 100 // 扳机条宽度
+	triggerHeight = 12  // 扳机条高度
+)
+
+// ShowInputVisualizer 打开手柄状态窗口，实时显示按键、摇杆和扳机，窗口关闭时调用 onClosed
+func ShowInputVisualizer(fyneApp fyne.App, parent fyne.Window, appCtrl *app.App, onClosed func()) fyne.Window {
+	states, cancel, err := appCtrl.WatchInput(visualizerInterval)
+	if err != nil {
+		dialog.ShowError(err, parent)
+		return nil
+	}
+
+	view := newInputView()
+	w := fyneApp.NewWindow("手柄状态")
+	w.SetContent(container.NewBorder(view.status, nil, nil, nil, container.NewCenter(view.diagram)))
+	w.SetOnClosed(func() {
+		cancel()
+		if onClosed != nil {
+			onClosed()
+		}
+	})
+	w.Show()
+
+	go func() {
+		for snapshot := range states {
+			fyne.Do(func() {
+				view.update(snapshot)
+			})
+		}
+	}()
+	return w
+}
+
+// inputView 手柄示意图
+type inputView struct {
+	diagram *fyne.Container
+	status  *widget.Label
+
+	buttons    map[gamepad.Button]*canvas.Rectangle
+	leftKnob   *canvas.Circle
+	rightKnob  *canvas.Circle
+	leftStick  fyne.Position // 左摇杆中心
+	rightStick fyne.Position // 右摇杆中心
+	ltBar      *canvas.Rectangle
+	rtBar      *canvas.Rectangle
+
+	last    gamepad.Snapshot
+	updated bool
+}
+
+// newInputView 创建手柄示意图（所有元素按固定坐标摆放）
+func newInputView() *inputView {
+	v := &inputView{
+		diagram:    container.NewWithoutLayout(),
+		status:     widget.NewLabel("等待手柄数据…"),
+		buttons:    make(map[gamepad.Button]*canvas.Rectangle),
+		leftStick:  fyne.NewPos(90, 120),
+		rightStick: fyne.NewPos(290, 200),
+	}
+	v.status.Alignment = fyne.TextAlignCenter
+
+	// 容器没有布局管理器，用透明背景固定示意图大小
+	background := canvas.NewRectangle(color.Transparent)
+	background.SetMinSize(fyne.NewSize(460, 250))
+	v.diagram.Add(background)
+
+	// 扳机和肩键
+	v.ltBar = v.addTrigger("LT", fyne.NewPos(40, 10))
+	v.rtBar = v.addTrigger("RT", fyne.NewPos(320, 10))
+	v.addButton(gamepad.ButtonLB, "LB", fyne.NewPos(90, 44), fyne.NewSize(100, 20), 6)
+	v.addButton(gamepad.ButtonRB, "RB", fyne.NewPos(370, 44), fyne.NewSize(100, 20), 6)
+
+	// 摇杆
+	v.leftKnob = v.addStick(v.leftStick)
+	v.rightKnob = v.addStick(v.rightStick)
+
+	// 功能键
+	v.addButton(gamepad.ButtonBack, "Back", fyne.NewPos(185, 120), fyne.NewSize(40, 18), 9)
+	v.addButton(gamepad.ButtonXbox, "Xbox", fyne.NewPos(230, 95), fyne.NewSize(30, 30), 15)
+	v.addButton(gamepad.ButtonStart, "Start", fyne.NewPos(275, 120), fyne.NewSize(40, 18), 9)
+	v.addButton(gamepad.ButtonShare, "Share", fyne.NewPos(230, 145), fyne.NewSize(40, 18), 9)
+
+	// 十字键
+	dpad := fyne.NewPos(170, 200)
+	dpadSize := fyne.NewSize(22, 22)
+	v.addButton(gamepad.ButtonDPadUp, "↑", dpad.AddXY(0, -22), dpadSize, 3)
+	v.addButton(gamepad.ButtonDPadDown, "↓", dpad.AddXY(0, 22), dpadSize, 3)
+	v.addButton(gamepad.ButtonDPadLeft, "←", dpad.AddXY(-22, 0), dpadSize, 3)
+	v.addButton(gamepad.ButtonDPadRight, "→", dpad.AddXY(22, 0), dpadSize, 3)
+
+	// ABXY
+	face := fyne.NewPos(370, 120)
+	faceSize := fyne.NewSize(26, 26)
+	v.addButton(gamepad.ButtonY, "Y", face.AddXY(0, -28), faceSize, 13)
+	v.addButton(gamepad.ButtonA, "A", face.AddXY(0, 28), faceSize, 13)
+	v.addButton(gamepad.ButtonX, "X", face.AddXY(-28, 0), faceSize, 13)
+	v.addButton(gamepad.ButtonB, "B", face.AddXY(28, 0), faceSize, 13)
+	return v
+}
+
+// addButton 在 center 处添加一个按键
+func (v *inputView) addButton(button gamepad.Button, text string, center fyne.Position, size fyne.Size, radius float32) {
+	rect := canvas.NewRectangle(idleColor())
+	rect.CornerRadius = radius
+	rect.StrokeColor = theme.Color(theme.ColorNameForeground)
+	rect.StrokeWidth = 1
+	rect.Resize(size)
+	rect.Move(center.SubtractXY(size.Width/2, size.Height/2))
+
+	label := canvas.NewText(text, theme.Color(theme.ColorNameForeground))
+	label.TextSize = 10
+	label.Alignment = fyne.TextAlignCenter
+	label.Resize(size)
+	label.Move(rect.Position())
+
+	v.buttons[button] = rect
+	v.diagram.Add(rect)
+	v.diagram.Add(label)
+}
+
+// addStick 在 center 处添加摇杆（活动范围、阈值圆和位置标记），返回位置标记
+func (v *inputView) addStick(center fyne.Position) *canvas.Circle {
+	outer := canvas.NewCircle(color.Transparent)
+	outer.StrokeColor = theme.Color(theme.ColorNameForeground)
+	outer.StrokeWidth = 1
+	placeCircle(outer, center, stickRadius)
+
+	// 推动超过阈值才视为摇杆方向按下
+	deadzone := canvas.NewCircle(color.Transparent)
+	deadzone.StrokeColor = theme.Color(theme.ColorNameDisabled)
+	deadzone.StrokeWidth = 1
+	placeCircle(deadzone, center, stickRadius*float32(gamepad.StickThreshold)/32768)
+
+	knob := canvas.NewCircle(idleColor())
+	knob.StrokeColor = theme.Color(theme.ColorNameForeground)
+	knob.StrokeWidth = 1
+	placeCircle(knob, center, knobRadius)
+
+	v.diagram.Add(outer)
+	v.diagram.Add(deadzone)
+	v.diagram.Add(knob)
+	return knob
+}
+
+// addTrigger 在 pos 处添加扳机条（带阈值刻度），返回填充条
+func (v *inputView) addTrigger(text string, pos fyne.Position) *canvas.Rectangle {
+	label := canvas.NewText(text, theme.Color(theme.ColorNameForeground))
+	label.TextSize = 10
+	label.Move(pos.SubtractXY(20, 0))
+
+	background := canvas.NewRectangle(idleColor())
+	background.Resize(fyne.NewSize(triggerWidth, triggerHeight))
+	background.Move(pos)
+
+	bar := canvas.NewRectangle(theme.Color(theme.ColorNameForeground))
+	bar.Resize(fyne.NewSize(0, triggerHeight))
+	bar.Move(pos)
+
+	// 超过阈值才视为扳机按下
+	x := pos.X + triggerWidth*float32(gamepad.TriggerThreshold)/255
+	threshold := canvas.NewLine(theme.Color(theme.ColorNameError))
+	threshold.Position1 = fyne.NewPos(x, pos.Y-2)
+	threshold.Position2 = fyne.NewPos(x, pos.Y+triggerHeight+2)
+
+	v.diagram.Add(label)
+	v.diagram.Add(background)
+	v.diagram.Add(bar)
+	v.diagram.Add(threshold)
+	return bar
+}
+
+// update 根据状态快照刷新示意图
+func (v *inputView) update(snapshot gamepad.Snapshot) {
+	if v.updated && snapshot == v.last {
+		return
+	}
+	v.last, v.updated = snapshot, true
+
+	if snapshot.Connected {
+		v.status.SetText("手柄已连接")
+	} else {
+		v.status.SetText("未检测到手柄")
+	}
+
+	gp := snapshot.Gamepad
+	for button, rect := range v.buttons {
+		setPressed(rect, gp.Buttons&uint16(button) != 0)
+	}
+
+	updateTrigger(v.ltBar, gp.LeftTrigger)
+	updateTrigger(v.rtBar, gp.RightTrigger)
+	updateKnob(v.leftKnob, v.leftStick, gp.ThumbLX, gp.ThumbLY, gp.Buttons&uint16(gamepad.ButtonLeftThumb) != 0)
+	updateKnob(v.rightKnob, v.rightStick, gp.ThumbRX, gp.ThumbRY, gp.Buttons&uint16(gamepad.ButtonRightThumb) != 0)
+}
+
+// setPressed 按下的按键高亮显示
+func setPressed(rect *canvas.Rectangle, pressed bool) {
+	rect.FillColor = idleColor()
+	if pressed {
+		rect.FillColor = theme.Color(theme.ColorNamePrimary)
+	}
+	rect.Refresh()
+}
+
+// updateTrigger 按扳机行程设置填充长度，超过阈值时高亮
+func updateTrigger(bar *canvas.Rectangle, value byte) {
+	bar.FillColor = theme.Color(theme.ColorNameForeground)
+	if value > gamepad.TriggerThreshold {
+		bar.FillColor = theme.Color(theme.ColorNamePrimary)
+	}
+	bar.Resize(fyne.NewSize(triggerWidth*float32(value)/255, triggerHeight))
+	bar.Refresh()
+}
+
+// updateKnob 按摇杆位置移动标记（Y 轴向上为正），按下摇杆时高亮
+func updateKnob(knob *canvas.Circle, center fyne.Position, x, y int16, pressed bool) {
+	pos := center.AddXY(stickRadius*float32(x)/32768, -stickRadius*float32(y)/32768)
+	knob.FillColor = idleColor()
+	if pressed {
+		knob.FillColor = theme.Color(theme.ColorNamePrimary)
+	}
+	placeCircle(knob, pos, knobRadius)
+	knob.Refresh()
+}
+
+// placeCircle 以 center 为圆心、radius 为半径放置圆
+func placeCircle(circle *canvas.Circle, center fyne.Position, radius float32) {
+	circle.Resize(fyne.NewSize(radius*2, radius*2))
+	circle.Move(center.SubtractXY(radius, radius))
+}
+
+// idleColor 未按下的按键颜色
+func idleColor() color.Color {
+	return theme.Color(theme.ColorNameInputBackground)
+}
@@ -1,14 +1,21 @@
 package ui
 
 import (
+	"log/slog"
+	"strings"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
 	appPkg "gamepad-key-mapper/internal/app"
+	"gamepad-key-mapper/internal/config"
+	"gamepad-key-mapper/internal/ipc"
+	"gamepad-key-mapper/internal/overlay"
 )
 
 // MainWindow 主窗口
@@ -20,7 +27,10 @@ type MainWindow struct {
 	startBtn    *widget.Button
 	stopBtn     *widget.Button
 	mappingList *MappingList
+	profileBar  *ProfileBar
 	tray        *Tray
+	inputWindow fyne.Window // 手柄状态窗口（未打开时为 nil）
+	eventWindow fyne.Window // 事件日志窗口（未打开时为 nil）
 }
 
 // Run 运行应用
@@ -32,8 +42,8 @@ func Run(appCtrl *appPkg.App) {
 	w.SetIcon(resourceIcon128Png) // 设置窗口图标
 	w.Resize(fyne.NewSize(500, 400))
 
-	// 加载配置
-	appCtrl.LoadConfig()
+	// 加载配置（失败时已回退为默认配置，窗口显示后提示用户）
+	loadErr := appCtrl.LoadConfig()
 
 	mw := &MainWindow{
 		app:     a,
@@ -48,6 +58,34 @@ func Run(appCtrl *appPkg.App) {
 	mw.tray = NewTray(a, w, appCtrl)
 	mw.tray.SetupWindowClose()
 
+	// 配置文件损坏时保存已暂停，由用户选择恢复备份或使用当前配置
+	if appCtrl.LoadFailure() != nil {
+		mw.onShowBackups()
+	} else if loadErr != nil {
+		dialog.ShowError(loadErr, w)
+	}
+
+	// 配置文件被外部修改时自动重新加载（失败只影响热加载）
+	if err := appCtrl.WatchConfig(); err != nil {
+		dialog.ShowError(err, w)
+	}
+
+	// 本地控制接口，供 Stream Deck 插件、脚本和 gkm 命令行使用（失败只记录日志）
+	server := ipc.NewServer(appCtrl)
+	if err := server.ListenDefault(); err != nil {
+		slog.Warn("control api disabled", "err", err)
+	}
+	defer server.Close()
+
+	// 直播叠加层（配置中开启时）
+	if addr := appCtrl.OverlayAddr(); addr != "" {
+		overlayServer := overlay.NewServer(appCtrl)
+		if err := overlayServer.Listen(addr); err != nil {
+			dialog.ShowError(err, w)
+		}
+		defer overlayServer.Close()
+	}
+
 	w.ShowAndRun()
 }
 
@@ -61,20 +99,34 @@ func (mw *MainWindow) setup() {
 	mw.startBtn = widget.NewButtonWithIcon("启动", theme.MediaPlayIcon(), mw.onStart)
 	mw.stopBtn = widget.NewButtonWithIcon("停止", theme.MediaStopIcon(), mw.onStop)
 	mw.stopBtn.Disable()
+	inputBtn := widget.NewButtonWithIcon("手柄状态", theme.VisibilityIcon(), mw.onShowInput)
+	eventBtn := widget.NewButtonWithIcon("事件日志", theme.ListIcon(), mw.onShowEvents)
+	backupBtn := widget.NewButtonWithIcon("配置备份", theme.HistoryIcon(), mw.onShowBackups)
 
 	controlBar := container.NewHBox(
 		mw.statusLabel,
 		widget.NewSeparator(),
 		mw.startBtn,
 		mw.stopBtn,
+		layout.NewSpacer(),
+		inputBtn,
+		eventBtn,
+		backupBtn,
 	)
 
+	// 方案选择栏
+	mw.profileBar = NewProfileBar(mw.appCtrl, mw.window)
+
 	// 映射列表
 	mw.mappingList = NewMappingList(mw.appCtrl, mw.window)
 
 	// 添加按钮
 	addBtn := widget.NewButtonWithIcon("添加映射", theme.ContentAddIcon(), mw.onAddMapping)
 
+	// 分享码
+	copyShareBtn := widget.NewButtonWithIcon("复制分享码", theme.ContentCopyIcon(), mw.onCopyShareCode)
+	importShareBtn := widget.NewButtonWithIcon("导入分享码", theme.ContentPasteIcon(), mw.onImportShareCode)
+
 	// 布局
 	content := container.NewBorder(
 		container.NewVBox(
@@ -83,14 +135,14 @@ func (mw *MainWindow) setup() {
 		),
 		container.NewVBox(
 			widget.NewSeparator(),
-			container.NewHBox(addBtn),
+			container.NewHBox(addBtn, layout.NewSpacer(), copyShareBtn, importShareBtn),
 		),
 		nil, nil,
 		mw.mappingList.Container(),
 	)
 
 	mainLayout := container.NewBorder(
-		container.NewVBox(controlBar, widget.NewSeparator()),
+		container.NewVBox(controlBar, widget.NewSeparator(), mw.profileBar.Container(), widget.NewSeparator()),
 		nil, nil, nil,
 		content,
 	)
@@ -101,15 +153,40 @@ func (mw *MainWindow) setup() {
 // setupCallbacks 设置回调
 func (mw *MainWindow) setupCallbacks() {
 	mw.appCtrl.SetOnStateChange(func(state appPkg.State) {
-		mw.updateStatus(state)
+		fyne.Do(func() {
+			mw.updateStatus(state)
+			if mw.tray != nil {
+				mw.tray.updateMenuState(state)
+			}
+		})
 	})
 
 	mw.appCtrl.SetOnRulesChange(func() {
-		mw.mappingList.Refresh()
+		fyne.Do(mw.mappingList.Refresh)
+	})
+
+	// 方案可能由前台窗口监视协程切换
+	mw.appCtrl.SetOnProfileChange(func(string) {
+		fyne.Do(func() {
+			mw.profileBar.Refresh()
+			if mw.tray != nil {
+				mw.tray.updateProfiles()
+			}
+		})
+	})
+
+	// 系统组合键请求显示窗口
+	mw.appCtrl.SetOnShowWindow(func() {
+		fyne.Do(func() {
+			mw.window.Show()
+			mw.window.RequestFocus()
+		})
 	})
 
 	mw.appCtrl.SetOnError(func(err error) {
-		dialog.ShowError(err, mw.window)
+		fyne.Do(func() {
+			dialog.ShowError(err, mw.window)
+		})
 	})
 }
 
@@ -120,6 +197,10 @@ func (mw *MainWindow) updateStatus(state appPkg.State) {
 		mw.statusLabel.SetText("状态: 运行中")
 		mw.startBtn.Disable()
 		mw.stopBtn.Enable()
+	case appPkg.StatePaused:
+		mw.statusLabel.SetText("状态: 已暂停")
+		mw.startBtn.Enable()
+		mw.stopBtn.Enable()
 	case appPkg.StateStopped:
 		mw.statusLabel.SetText("状态: 已停止")
 		mw.startBtn.Enable()
@@ -134,6 +215,28 @@ func (mw *MainWindow) onStart() {
 	}
 }
 
+// onShowInput 打开手柄状态窗口（已打开时切换到该窗口）
+func (mw *MainWindow) onShowInput() {
+	if mw.inputWindow != nil {
+		mw.inputWindow.RequestFocus()
+		return
+	}
+	mw.inputWindow = ShowInputVisualizer(mw.app, mw.window, mw.appCtrl, func() {
+		mw.inputWindow = nil
+	})
+}
+
+// onShowEvents 打开事件日志窗口（已打开时切换到该窗口）
+func (mw *MainWindow) onShowEvents() {
+	if mw.eventWindow != nil {
+		mw.eventWindow.RequestFocus()
+		return
+	}
+	mw.eventWindow = ShowEventConsole(mw.app, mw.appCtrl, func() {
+		mw.eventWindow = nil
+	})
+}
+
 // onStop 停止按钮点击
 func (mw *MainWindow) onStop() {
 	mw.appCtrl.Stop()
@@ -143,3 +246,41 @@ func (mw *MainWindow) onStop() {
 func (mw *MainWindow) onAddMapping() {
 	ShowMappingForm(mw.window, mw.appCtrl, nil)
 }
+
+// onCopyShareCode 复制当前方案的分享码到剪贴板
+func (mw *MainWindow) onCopyShareCode() {
+	code, err := mw.appCtrl.ShareCode(mw.appCtrl.ActiveProfile())
+	if err != nil {
+		dialog.ShowError(err, mw.window)
+		return
+	}
+	mw.app.Clipboard().SetContent(code)
+	dialog.ShowInformation("已复制", "当前方案的分享码已复制到剪贴板，可直接粘贴到聊天中", mw.window)
+}
+
+// onImportShareCode 粘贴分享码并导入
+func (mw *MainWindow) onImportShareCode() {
+	entry := widget.NewMultiLineEntry()
+	entry.Wrapping = fyne.TextWrapBreak
+	entry.SetPlaceHolder(config.ShareCodePrefix + "...")
+	if clip := strings.TrimSpace(mw.app.Clipboard().Content()); strings.HasPrefix(clip, config.ShareCodePrefix) {
+		entry.SetText(clip)
+	}
+	entry.SetMinRowsVisible(4)
+
+	dialog.ShowForm("导入分享码", "下一步", "取消",
+		[]*widget.FormItem{widget.NewFormItem("分享码", entry)},
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			bundle, err := mw.appCtrl.ReadShareCode(entry.Text)
+			if err != nil {
+				dialog.ShowError(err, mw.window)
+				return
+			}
+			mw.profileBar.showImportOptions(bundle, nil)
+		},
+		mw.window,
+	)
+}
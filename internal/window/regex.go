@@ -0,0 +1,71 @@
+package window
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// 正则表达式缓存（匹配在轮询中反复执行）
+var (
+	regexCache   = make(map[string]*regexp.Regexp)
+	regexCacheMu sync.Mutex
+)
+
+// compileRegex 编译并缓存正则表达式
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	regexCacheMu.Lock()
+	defer regexCacheMu.Unlock()
+
+	if re, ok := regexCache[pattern]; ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexCache[pattern] = re
+	return re, nil
+}
+
+// compileGlob 将通配符模式转换为整体匹配、忽略大小写的正则表达式并编译
+//
+// * 匹配任意多个字符、? 匹配单个字符（都可以跨越 / 和 \，窗口标题中常有 URL 和文件路径），
+// [...] 为字符组（[!...] 表示取反），\ 转义下一个字符。
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString(`(?is)^`)
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString(`.*`)
+		case '?':
+			b.WriteString(`.`)
+		case '\\':
+			i++
+			if i >= len(pattern) {
+				return nil, errors.New("glob pattern ends with an unfinished escape")
+			}
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, errors.New("glob pattern has an unclosed [")
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	b.WriteString(`$`)
+	return compileRegex(b.String())
+}
@@ -0,0 +1,16 @@
+//go:build !windows && !linux
+
+package window
+
+// stubSource 不支持的平台
+type stubSource struct{}
+
+// NewSource 创建当前平台的前台窗口信息来源（存根）
+func NewSource() Source {
+	return stubSource{}
+}
+
+// Foreground 获取前台窗口信息（存根）
+func (stubSource) Foreground() (Info, error) {
+	return Info{}, ErrUnsupported
+}
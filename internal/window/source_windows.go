@@ -0,0 +1,77 @@
+//go:build windows
+
+package window
+
+import (
+	"errors"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	user32                         = syscall.NewLazyDLL("user32.dll")
+	kernel32                       = syscall.NewLazyDLL("kernel32.dll")
+	procGetForegroundWindow        = user32.NewProc("GetForegroundWindow")
+	procGetWindowTextW             = user32.NewProc("GetWindowTextW")
+	procGetWindowThreadProcessId   = user32.NewProc("GetWindowThreadProcessId")
+	procQueryFullProcessImageNameW = kernel32.NewProc("QueryFullProcessImageNameW")
+)
+
+// PROCESS_QUERY_LIMITED_INFORMATION 进程访问权限
+const PROCESS_QUERY_LIMITED_INFORMATION = 0x1000
+
+// win32Source 通过 GetForegroundWindow 获取前台窗口
+type win32Source struct{}
+
+// NewSource 创建当前平台的前台窗口信息来源
+func NewSource() Source {
+	return win32Source{}
+}
+
+// Foreground 获取前台窗口信息
+func (win32Source) Foreground() (Info, error) {
+	hwnd, _, _ := procGetForegroundWindow.Call()
+	if hwnd == 0 {
+		return Info{}, errors.New("no foreground window")
+	}
+
+	var info Info
+
+	// 窗口标题
+	buf := make([]uint16, 512)
+	n, _, _ := procGetWindowTextW.Call(hwnd, uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	info.Title = syscall.UTF16ToString(buf[:n])
+
+	// 进程名
+	var pid uint32
+	procGetWindowThreadProcessId.Call(hwnd, uintptr(unsafe.Pointer(&pid)))
+	if pid != 0 {
+		info.Process = processName(pid)
+	}
+
+	return info, nil
+}
+
+// processName 根据 PID 获取进程可执行文件名
+func processName(pid uint32) string {
+	h, err := syscall.OpenProcess(PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return ""
+	}
+	defer syscall.CloseHandle(h)
+
+	buf := make([]uint16, syscall.MAX_PATH)
+	size := uint32(len(buf))
+	ret, _, _ := procQueryFullProcessImageNameW.Call(
+		uintptr(h),
+		0,
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(unsafe.Pointer(&size)),
+	)
+	if ret == 0 {
+		return ""
+	}
+
+	return filepath.Base(syscall.UTF16ToString(buf[:size]))
+}
@@ -0,0 +1,143 @@
+//go:build linux
+
+package window
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// x11Source 通过 _NET_ACTIVE_WINDOW 获取前台窗口（直接连接 X 服务器，连接在多次查询间复用）
+type x11Source struct {
+	mu     sync.Mutex
+	conn   *x11Conn
+	failed bool // 上一次连接失败（只记录一次日志）
+}
+
+// NewSource 创建当前平台的前台窗口信息来源
+func NewSource() Source {
+	return &x11Source{}
+}
+
+// Foreground 获取前台窗口信息
+func (s *x11Source) Foreground() (Info, error) {
+	display := os.Getenv("DISPLAY")
+	if display == "" {
+		return Info{}, ErrUnsupported
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		conn, err := dialX11(display)
+		if err != nil {
+			if !s.failed {
+				s.failed = true
+				slog.Warn("cannot connect to X server, foreground window detection unavailable", "display", display, "err", err)
+			}
+			return Info{}, err
+		}
+		s.conn = conn
+		s.failed = false
+	}
+
+	info, err := s.query()
+	var xerr *x11Error
+	if err != nil && !errors.As(err, &xerr) {
+		// 连接已断开（如 X 服务器重启），下次重新连接
+		s.conn.Close()
+		s.conn = nil
+	}
+	return info, err
+}
+
+// query 读取当前活动窗口的标题和进程
+func (s *x11Source) query() (Info, error) {
+	x := s.conn
+	activeAtom, err := x.internAtom("_NET_ACTIVE_WINDOW")
+	if err != nil {
+		return Info{}, err
+	}
+	if activeAtom == 0 {
+		return Info{}, errors.New("window manager does not support _NET_ACTIVE_WINDOW")
+	}
+	window, err := x.cardinal(x.root, activeAtom, x11AtomWindow)
+	if err != nil {
+		return Info{}, err
+	}
+	if window == 0 {
+		return Info{}, errors.New("no active window")
+	}
+
+	var info Info
+	if info.Title, err = s.title(window); err != nil {
+		return Info{}, err
+	}
+
+	pidAtom, err := x.internAtom("_NET_WM_PID")
+	if err != nil {
+		return Info{}, err
+	}
+	if pidAtom != 0 {
+		pid, err := x.cardinal(window, pidAtom, x11AtomCardinal)
+		if err != nil {
+			return Info{}, err
+		}
+		if pid != 0 {
+			info.Process = processName(int(pid))
+		}
+	}
+
+	return info, nil
+}
+
+// title 读取窗口标题（优先使用 UTF-8 的 _NET_WM_NAME，没有时使用 WM_NAME）
+func (s *x11Source) title(window uint32) (string, error) {
+	x := s.conn
+	nameAtom, err := x.internAtom("_NET_WM_NAME")
+	if err != nil {
+		return "", err
+	}
+	if nameAtom != 0 {
+		_, value, err := x.getProperty(window, nameAtom, x11AnyPropertyType, 1024)
+		if err != nil {
+			return "", err
+		}
+		if len(value) > 0 {
+			return string(value), nil
+		}
+	}
+
+	typ, value, err := x.getProperty(window, x11AtomWMName, x11AnyPropertyType, 1024)
+	if err != nil {
+		return "", err
+	}
+	if typ == x11AtomString && !utf8.Valid(value) {
+		return latin1ToString(value), nil // STRING 类型为 Latin-1 编码
+	}
+	return string(value), nil
+}
+
+// latin1ToString 将 Latin-1 字节转换为字符串
+func latin1ToString(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// processName 根据 PID 读取进程名
+func processName(pid int) string {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/comm")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,88 @@
+package window
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Watcher 前台窗口监视器（轮询 Source，窗口变化时回调）
+type Watcher struct {
+	source   Source
+	interval time.Duration
+	onChange func(Info)
+
+	running bool
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+}
+
+// NewWatcher 创建前台窗口监视器
+func NewWatcher(source Source, interval time.Duration, onChange func(Info)) *Watcher {
+	return &Watcher{
+		source:   source,
+		interval: interval,
+		onChange: onChange,
+	}
+}
+
+// Start 开始监视
+func (w *Watcher) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.running {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.running = true
+
+	go w.pollLoop(ctx)
+}
+
+// Stop 停止监视
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.running {
+		return
+	}
+
+	w.cancel()
+	w.running = false
+}
+
+// IsRunning 检查是否正在运行
+func (w *Watcher) IsRunning() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.running
+}
+
+// pollLoop 轮询循环
+func (w *Watcher) pollLoop(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	var last Info
+	first := true
+
+	for {
+		info, err := w.source.Foreground()
+		if err == nil && (first || info != last) {
+			// 窗口变化（获取失败时保持上一次结果）
+			last = info
+			first = false
+			w.onChange(info)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package window
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Info 前台窗口信息
+type Info struct {
+	Process string // 进程名（如 "chrome.exe"）
+	Title   string // 窗口标题
+}
+
+// Source 前台窗口信息来源
+type Source interface {
+	// Foreground 返回当前前台窗口信息
+	Foreground() (Info, error)
+}
+
+// ErrUnsupported 当前平台不支持获取前台窗口
+var ErrUnsupported = errors.New("foreground window detection is not supported on this platform")
+
+// MatchField 匹配字段
+type MatchField int
+
+const (
+	MatchProcess MatchField = iota // 匹配进程名
+	MatchTitle                     // 匹配窗口标题
+)
+
+// MatchMode 匹配方式
+type MatchMode int
+
+const (
+	MatchExact MatchMode = iota // 完全相同（忽略大小写）
+	MatchGlob                   // 通配符（* 和 ? 可匹配包括 / 在内的任意字符，忽略大小写）
+	MatchRegex                  // 正则表达式
+)
+
+// Matcher 窗口匹配条件
+type Matcher struct {
+	Field   MatchField `json:"field"`   // 匹配字段
+	Mode    MatchMode  `json:"mode"`    // 匹配方式
+	Pattern string     `json:"pattern"` // 匹配模式
+}
+
+// String 返回匹配条件的可读描述
+func (m Matcher) String() string {
+	field := "进程"
+	if m.Field == MatchTitle {
+		field = "标题"
+	}
+
+	switch m.Mode {
+	case MatchGlob:
+		return field + " ~ " + m.Pattern
+	case MatchRegex:
+		return field + " =~ /" + m.Pattern + "/"
+	default:
+		return field + " = " + m.Pattern
+	}
+}
+
+// Validate 检查匹配条件是否有效
+func (m Matcher) Validate() error {
+	if m.Pattern == "" {
+		return errors.New("empty match pattern")
+	}
+
+	switch m.Mode {
+	case MatchExact:
+		return nil
+	case MatchGlob:
+		_, err := compileGlob(m.Pattern)
+		return err
+	case MatchRegex:
+		_, err := regexp.Compile(m.Pattern)
+		return err
+	default:
+		return errors.New("unknown match mode")
+	}
+}
+
+// Match 检查窗口是否满足匹配条件（无效的模式视为不匹配）
+func (m Matcher) Match(info Info) bool {
+	value := info.Process
+	if m.Field == MatchTitle {
+		value = info.Title
+	}
+
+	switch m.Mode {
+	case MatchExact:
+		return strings.EqualFold(value, m.Pattern)
+	case MatchGlob:
+		re, err := compileGlob(m.Pattern)
+		return err == nil && re.MatchString(value)
+	case MatchRegex:
+		re, err := compileRegex(m.Pattern)
+		return err == nil && re.MatchString(value)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,143 @@
+package window
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMatcherMatch(t *testing.T) {
+	browser := Info{Process: "chrome.exe", Title: "https://github.com/JunJianSyu/gamepad-key-mapper - Google Chrome"}
+	editor := Info{Process: "Code.exe", Title: `C:\Users\me\src\main.go - Visual Studio Code`}
+
+	tests := []struct {
+		name    string
+		matcher Matcher
+		info    Info
+		want    bool
+	}{
+		{"exact process ignores case", Matcher{MatchProcess, MatchExact, "CHROME.EXE"}, browser, true},
+		{"exact process mismatch", Matcher{MatchProcess, MatchExact, "chrome"}, browser, false},
+		{"exact title", Matcher{MatchTitle, MatchExact, browser.Title}, browser, true},
+		{"glob process", Matcher{MatchProcess, MatchGlob, "chrome*"}, browser, true},
+		{"glob crosses slashes in URL", Matcher{MatchTitle, MatchGlob, "*github*"}, browser, true},
+		{"glob crosses backslashes in path", Matcher{MatchTitle, MatchGlob, "*\\\\src\\\\*.go - *"}, editor, true},
+		{"glob ignores case", Matcher{MatchTitle, MatchGlob, "*GOOGLE CHROME"}, browser, true},
+		{"glob is anchored", Matcher{MatchTitle, MatchGlob, "github*"}, browser, false},
+		{"glob question mark", Matcher{MatchProcess, MatchGlob, "c?de.exe"}, editor, true},
+		{"glob character class", Matcher{MatchProcess, MatchGlob, "[cC]ode.exe"}, editor, true},
+		{"glob negated class", Matcher{MatchProcess, MatchGlob, "[!c]ode.exe"}, editor, false},
+		{"glob dot is literal", Matcher{MatchProcess, MatchGlob, "code.ex."}, editor, false},
+		{"glob escaped star", Matcher{MatchProcess, MatchGlob, `code\*`}, Info{Process: "code*"}, true},
+		{"glob invalid never matches", Matcher{MatchProcess, MatchGlob, "[code"}, editor, false},
+		{"regex title", Matcher{MatchTitle, MatchRegex, `github\.com/\w+`}, browser, true},
+		{"regex is unanchored", Matcher{MatchTitle, MatchRegex, `Chrome$`}, browser, true},
+		{"regex mismatch", Matcher{MatchProcess, MatchRegex, `^firefox`}, browser, false},
+		{"regex invalid never matches", Matcher{MatchProcess, MatchRegex, `(`}, browser, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.matcher.Match(tt.info); got != tt.want {
+				t.Errorf("%s Match(%+v) = %v, want %v", tt.matcher, tt.info, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcherValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		matcher Matcher
+		wantErr bool
+	}{
+		{"exact", Matcher{MatchProcess, MatchExact, "game.exe"}, false},
+		{"empty pattern", Matcher{MatchProcess, MatchExact, ""}, true},
+		{"glob", Matcher{MatchTitle, MatchGlob, "*/path/*"}, false},
+		{"glob unclosed class", Matcher{MatchTitle, MatchGlob, "[abc"}, true},
+		{"glob trailing escape", Matcher{MatchTitle, MatchGlob, `abc\`}, true},
+		{"regex", Matcher{MatchTitle, MatchRegex, `^a.*b$`}, false},
+		{"regex invalid", Matcher{MatchTitle, MatchRegex, `(`}, true},
+		{"unknown mode", Matcher{MatchTitle, MatchMode(9), "x"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.matcher.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeSource 按顺序返回预设结果的前台窗口信息来源，用完后重复最后一个
+type fakeSource struct {
+	mu      sync.Mutex
+	results []fakeResult
+}
+
+type fakeResult struct {
+	info Info
+	err  error
+}
+
+func (s *fakeSource) Foreground() (Info, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.results[0]
+	if len(s.results) > 1 {
+		s.results = s.results[1:]
+	}
+	return r.info, r.err
+}
+
+func TestWatcherReportsChanges(t *testing.T) {
+	game := Info{Process: "game.exe", Title: "Game"}
+	browser := Info{Process: "chrome.exe", Title: "Browser"}
+	source := &fakeSource{results: []fakeResult{
+		{info: game},
+		{info: game},
+		{err: errors.New("transient failure")},
+		{info: game},
+		{info: browser},
+		{info: browser},
+	}}
+
+	changes := make(chan Info, 10)
+	w := NewWatcher(source, time.Millisecond, func(info Info) { changes <- info })
+	w.Start()
+	defer w.Stop()
+
+	for _, want := range []Info{game, browser} {
+		select {
+		case got := <-changes:
+			if got != want {
+				t.Fatalf("change = %+v, want %+v", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for change to %+v", want)
+		}
+	}
+
+	select {
+	case got := <-changes:
+		t.Errorf("unexpected change to %+v", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestWatcherStop(t *testing.T) {
+	source := &fakeSource{results: []fakeResult{{info: Info{Process: "a.exe"}}}}
+	w := NewWatcher(source, time.Millisecond, func(Info) {})
+
+	w.Start()
+	if !w.IsRunning() {
+		t.Fatal("watcher not running after Start")
+	}
+	w.Stop()
+	if w.IsRunning() {
+		t.Fatal("watcher still running after Stop")
+	}
+}
@@ -0,0 +1,329 @@
+//go:build linux
+
+package window
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// X11 协议常量（只包含查询前台窗口用到的部分）
+const (
+	x11OpInternAtom  = 16
+	x11OpGetProperty = 20
+
+	x11AtomCardinal = 6  // CARDINAL
+	x11AtomString   = 31 // STRING
+	x11AtomWindow   = 33 // WINDOW
+	x11AtomWMName   = 39 // WM_NAME
+
+	x11AnyPropertyType = 0
+
+	x11GenericEvent = 35
+)
+
+// x11Timeout 连接和每次请求的超时时间
+const x11Timeout = 2 * time.Second
+
+// x11Error X 服务器返回的请求错误（如窗口已关闭时的 BadWindow），连接仍然可用
+type x11Error struct {
+	code byte
+}
+
+func (e *x11Error) Error() string {
+	return fmt.Sprintf("X11 request failed with error code %d", e.code)
+}
+
+// x11Conn 到 X 服务器的最小连接，直接使用 X11 协议，只实现查询窗口属性所需的
+// InternAtom 和 GetProperty 请求（不依赖 libX11 或 xprop 等外部工具）
+type x11Conn struct {
+	conn  net.Conn
+	r     *bufio.Reader
+	root  uint32 // 第一个屏幕的根窗口
+	seq   uint16 // 最近一次请求的序号
+	atoms map[string]uint32
+}
+
+// dialX11 连接 DISPLAY 指定的 X 服务器（使用 XAUTHORITY 或 ~/.Xauthority 中的 MIT-MAGIC-COOKIE-1）
+func dialX11(display string) (*x11Conn, error) {
+	network, addr, number, err := parseDisplay(display)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout(network, addr, x11Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	host := ""
+	if network == "tcp" {
+		host, _, _ = net.SplitHostPort(addr)
+	}
+	authName, authData := findXauth(xauthorityPath(), network, host, number)
+
+	x := newX11Conn(conn)
+	if err := x.setup(authName, authData); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return x, nil
+}
+
+// newX11Conn 在已建立的连接上创建 X11 客户端（尚未握手）
+func newX11Conn(conn net.Conn) *x11Conn {
+	return &x11Conn{conn: conn, r: bufio.NewReader(conn), atoms: make(map[string]uint32)}
+}
+
+// Close 关闭连接
+func (x *x11Conn) Close() error {
+	return x.conn.Close()
+}
+
+// parseDisplay 解析 DISPLAY（[主机]:显示号[.屏幕号]），返回连接地址和显示号
+//
+// 主机为空或为 unix 时使用本地套接字 /tmp/.X11-unix/X<显示号>，否则连接 TCP 6000+显示号。
+func parseDisplay(display string) (network, addr, number string, err error) {
+	i := strings.LastIndex(display, ":")
+	if i < 0 {
+		return "", "", "", fmt.Errorf("invalid DISPLAY %q", display)
+	}
+	host, rest := display[:i], display[i+1:]
+	number, _, _ = strings.Cut(rest, ".")
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 0 {
+		return "", "", "", fmt.Errorf("invalid DISPLAY %q", display)
+	}
+
+	if host == "" || host == "unix" {
+		return "unix", "/tmp/.X11-unix/X" + number, number, nil
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]") // IPv6 地址写作 [::1]:0
+	return "tcp", net.JoinHostPort(host, strconv.Itoa(6000+n)), number, nil
+}
+
+// xauthorityPath 返回 Xauthority 文件路径
+func xauthorityPath() string {
+	if path := os.Getenv("XAUTHORITY"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".Xauthority")
+}
+
+// Xauthority 条目的地址类型
+const (
+	xauthFamilyLocal = 256
+	xauthFamilyWild  = 65535
+)
+
+// findXauth 在 Xauthority 文件中查找连接使用的 MIT-MAGIC-COOKIE-1（找不到时不认证）
+func findXauth(path, network, host, number string) (name string, data []byte) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil
+	}
+	defer f.Close()
+
+	hostname, _ := os.Hostname()
+	r := bufio.NewReader(f)
+	for {
+		var family uint16
+		if err := binary.Read(r, binary.BigEndian, &family); err != nil {
+			return "", nil
+		}
+		var fields [4][]byte // 地址、显示号、认证方式、认证数据
+		for i := range fields {
+			if fields[i], err = readXauthField(r); err != nil {
+				return "", nil
+			}
+		}
+		addr, num, authName, authData := string(fields[0]), string(fields[1]), string(fields[2]), fields[3]
+
+		if authName != "MIT-MAGIC-COOKIE-1" || (num != "" && num != number) {
+			continue
+		}
+		switch {
+		case family == xauthFamilyWild:
+		case network == "unix" && family == xauthFamilyLocal && addr == hostname:
+		case network == "tcp" && addr == host:
+		default:
+			continue
+		}
+		return authName, authData
+	}
+}
+
+// readXauthField 读取 Xauthority 中带长度前缀的字段
+func readXauthField(r io.Reader) ([]byte, error) {
+	var n uint16
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	_, err := io.ReadFull(r, buf)
+	return buf, err
+}
+
+// pad4 返回补齐到 4 字节所需的填充长度
+func pad4(n int) int {
+	return (4 - n%4) % 4
+}
+
+// setup 发送连接请求并读取根窗口
+func (x *x11Conn) setup(authName string, authData []byte) error {
+	x.conn.SetDeadline(time.Now().Add(x11Timeout))
+	defer x.conn.SetDeadline(time.Time{})
+
+	req := make([]byte, 12, 12+len(authName)+pad4(len(authName))+len(authData)+pad4(len(authData)))
+	req[0] = 'l' // 小端字节序
+	binary.LittleEndian.PutUint16(req[2:], 11)
+	binary.LittleEndian.PutUint16(req[4:], 0)
+	binary.LittleEndian.PutUint16(req[6:], uint16(len(authName)))
+	binary.LittleEndian.PutUint16(req[8:], uint16(len(authData)))
+	req = append(req, authName...)
+	req = append(req, make([]byte, pad4(len(authName)))...)
+	req = append(req, authData...)
+	req = append(req, make([]byte, pad4(len(authData)))...)
+	if _, err := x.conn.Write(req); err != nil {
+		return err
+	}
+
+	var head [8]byte
+	if _, err := io.ReadFull(x.r, head[:]); err != nil {
+		return err
+	}
+	body := make([]byte, int(binary.LittleEndian.Uint16(head[6:]))*4)
+	if _, err := io.ReadFull(x.r, body); err != nil {
+		return err
+	}
+
+	switch head[0] {
+	case 1:
+	case 0:
+		reason := body[:min(int(head[1]), len(body))]
+		return fmt.Errorf("X server refused connection: %s", strings.TrimSpace(string(reason)))
+	default:
+		return errors.New("X server requires unsupported authentication")
+	}
+
+	// 成功应答：固定部分 32 字节，之后是厂商字符串、像素格式（每个 8 字节）和屏幕列表
+	if len(body) < 32 {
+		return errors.New("short X11 setup reply")
+	}
+	vendorLen := int(binary.LittleEndian.Uint16(body[16:]))
+	screens := body[20]
+	formats := int(body[21])
+	offset := 32 + vendorLen + pad4(vendorLen) + formats*8
+	if screens == 0 || len(body) < offset+4 {
+		return errors.New("X11 setup reply has no screens")
+	}
+	x.root = binary.LittleEndian.Uint32(body[offset:])
+	return nil
+}
+
+// roundTrip 发送一个请求并等待对应的应答（返回应答的 32 字节头部和附加数据）
+func (x *x11Conn) roundTrip(req []byte) ([]byte, error) {
+	x.conn.SetDeadline(time.Now().Add(x11Timeout))
+	defer x.conn.SetDeadline(time.Time{})
+
+	x.seq++
+	if _, err := x.conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	for {
+		reply := make([]byte, 32)
+		if _, err := io.ReadFull(x.r, reply); err != nil {
+			return nil, err
+		}
+		seq := binary.LittleEndian.Uint16(reply[2:])
+
+		switch kind := reply[0] & 0x7f; kind {
+		case 0:
+			if seq == x.seq {
+				return nil, &x11Error{code: reply[1]}
+			}
+		case 1, x11GenericEvent:
+			extra := make([]byte, int(binary.LittleEndian.Uint32(reply[4:]))*4)
+			if _, err := io.ReadFull(x.r, extra); err != nil {
+				return nil, err
+			}
+			if kind == 1 && seq == x.seq {
+				return append(reply, extra...), nil
+			}
+		}
+		// 其它事件：没有选择任何事件，直接忽略
+	}
+}
+
+// internAtom 查询原子（不存在时返回 0，不创建）
+func (x *x11Conn) internAtom(name string) (uint32, error) {
+	if atom, ok := x.atoms[name]; ok {
+		return atom, nil
+	}
+
+	req := make([]byte, 8, 8+len(name)+pad4(len(name)))
+	req[0] = x11OpInternAtom
+	req[1] = 1 // only-if-exists
+	binary.LittleEndian.PutUint16(req[2:], uint16(2+(len(name)+pad4(len(name)))/4))
+	binary.LittleEndian.PutUint16(req[4:], uint16(len(name)))
+	req = append(req, name...)
+	req = append(req, make([]byte, pad4(len(name)))...)
+
+	reply, err := x.roundTrip(req)
+	if err != nil {
+		return 0, err
+	}
+	atom := binary.LittleEndian.Uint32(reply[8:])
+	if atom != 0 {
+		x.atoms[name] = atom
+	}
+	return atom, nil
+}
+
+// getProperty 读取窗口属性（最多 maxLen 个 32 位单元），返回属性类型和内容
+//
+// 属性不存在时类型为 0，内容为空。
+func (x *x11Conn) getProperty(window, property, typ uint32, maxLen uint32) (uint32, []byte, error) {
+	req := make([]byte, 24)
+	req[0] = x11OpGetProperty
+	binary.LittleEndian.PutUint16(req[2:], 6)
+	binary.LittleEndian.PutUint32(req[4:], window)
+	binary.LittleEndian.PutUint32(req[8:], property)
+	binary.LittleEndian.PutUint32(req[12:], typ)
+	binary.LittleEndian.PutUint32(req[16:], 0)
+	binary.LittleEndian.PutUint32(req[20:], maxLen)
+
+	reply, err := x.roundTrip(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	format := int(reply[1])
+	actualType := binary.LittleEndian.Uint32(reply[8:])
+	n := int(binary.LittleEndian.Uint32(reply[16:])) * format / 8
+	if n > len(reply)-32 {
+		return 0, nil, errors.New("short X11 property reply")
+	}
+	return actualType, reply[32 : 32+n], nil
+}
+
+// cardinal 读取 32 位整数属性（WINDOW、CARDINAL 等），属性不存在时返回 0
+func (x *x11Conn) cardinal(window, property, typ uint32) (uint32, error) {
+	_, value, err := x.getProperty(window, property, typ, 1)
+	if err != nil || len(value) < 4 {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(value), nil
+}
@@ -0,0 +1,318 @@
+//go:build linux
+
+package window
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeProperty 假 X 服务器上的窗口属性
+type fakeProperty struct {
+	typ    uint32
+	format byte
+	value  []byte
+}
+
+// fakeXServer 在管道上实现 X11 握手、InternAtom 和 GetProperty 的假服务器
+type fakeXServer struct {
+	root       uint32
+	auth       string // 期望的认证数据（为空时不检查）
+	atoms      map[string]uint32
+	properties map[[2]uint32]fakeProperty // (窗口, 属性) → 值；窗口不在 windows 中时返回 BadWindow
+	windows    map[uint32]bool
+}
+
+// serve 处理一个连接，直到连接关闭
+func (s *fakeXServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	head := make([]byte, 12)
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return
+	}
+	nameLen := int(binary.LittleEndian.Uint16(head[6:]))
+	dataLen := int(binary.LittleEndian.Uint16(head[8:]))
+	auth := make([]byte, nameLen+pad4(nameLen)+dataLen+pad4(dataLen))
+	if _, err := io.ReadFull(conn, auth); err != nil {
+		return
+	}
+	data := auth[nameLen+pad4(nameLen):][:dataLen]
+	if s.auth != "" && string(data) != s.auth {
+		reason := []byte("No protocol specified\n\x00\x00")
+		reply := []byte{0, byte(len(reason) - 2), 11, 0, 0, 0, byte(len(reason) / 4), 0}
+		conn.Write(append(reply, reason...))
+		return
+	}
+
+	// 成功应答：4 字节厂商名、1 个像素格式、1 个屏幕
+	body := make([]byte, 32, 32+4+8+40)
+	binary.LittleEndian.PutUint16(body[16:], 4)
+	body[20] = 1
+	body[21] = 1
+	body = append(body, "test"...)
+	body = append(body, make([]byte, 8)...)
+	screen := make([]byte, 40)
+	binary.LittleEndian.PutUint32(screen, s.root)
+	body = append(body, screen...)
+	reply := []byte{1, 0, 11, 0, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint16(reply[6:], uint16(len(body)/4))
+	conn.Write(append(reply, body...))
+
+	var seq uint16
+	for {
+		req := make([]byte, 4)
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+		rest := make([]byte, int(binary.LittleEndian.Uint16(req[2:]))*4-4)
+		if _, err := io.ReadFull(conn, rest); err != nil {
+			return
+		}
+		req = append(req, rest...)
+		seq++
+
+		// 每个应答前插入一个无关事件，客户端应当跳过
+		event := make([]byte, 32)
+		event[0] = 28 // PropertyNotify
+		conn.Write(event)
+
+		out := make([]byte, 32)
+		binary.LittleEndian.PutUint16(out[2:], seq)
+		switch req[0] {
+		case x11OpInternAtom:
+			n := int(binary.LittleEndian.Uint16(req[4:]))
+			out[0] = 1
+			binary.LittleEndian.PutUint32(out[8:], s.atoms[string(req[8:8+n])])
+		case x11OpGetProperty:
+			window := binary.LittleEndian.Uint32(req[4:])
+			if !s.windows[window] {
+				out[0], out[1] = 0, 3 // BadWindow
+				break
+			}
+			p := s.properties[[2]uint32{window, binary.LittleEndian.Uint32(req[8:])}]
+			value := append(p.value, make([]byte, pad4(len(p.value)))...)
+			out[0], out[1] = 1, p.format
+			binary.LittleEndian.PutUint32(out[4:], uint32(len(value)/4))
+			binary.LittleEndian.PutUint32(out[8:], p.typ)
+			if p.format != 0 {
+				binary.LittleEndian.PutUint32(out[16:], uint32(len(p.value)*8/int(p.format)))
+			}
+			out = append(out, value...)
+		}
+		conn.Write(out)
+	}
+}
+
+// dialFake 通过管道连接假服务器并完成握手
+func dialFake(t *testing.T, s *fakeXServer, authData string) (*x11Conn, error) {
+	t.Helper()
+	client, server := net.Pipe()
+	go s.serve(server)
+	t.Cleanup(func() { client.Close() })
+
+	x := newX11Conn(client)
+	name := ""
+	if authData != "" {
+		name = "MIT-MAGIC-COOKIE-1"
+	}
+	return x, x.setup(name, []byte(authData))
+}
+
+func u32(v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(nil, v)
+}
+
+func newFakeDesktop() *fakeXServer {
+	const root, active = 0x100, 0x2a00005
+	return &fakeXServer{
+		root:  root,
+		auth:  "secret",
+		atoms: map[string]uint32{"_NET_ACTIVE_WINDOW": 300, "_NET_WM_PID": 301, "_NET_WM_NAME": 302},
+		properties: map[[2]uint32]fakeProperty{
+			{root, 300}:   {typ: x11AtomWindow, format: 32, value: u32(active)},
+			{active, 302}: {typ: 303, format: 8, value: []byte(`终端 "~/src"`)},
+			{active, 301}: {typ: x11AtomCardinal, format: 32, value: u32(uint32(os.Getpid()))},
+		},
+		windows: map[uint32]bool{root: true, active: true},
+	}
+}
+
+func TestX11SourceQuery(t *testing.T) {
+	conn, err := dialFake(t, newFakeDesktop(), "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.root != 0x100 {
+		t.Fatalf("root = %#x, want 0x100", conn.root)
+	}
+
+	s := &x11Source{conn: conn}
+	info, err := s.query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Info{Title: `终端 "~/src"`, Process: processName(os.Getpid())}
+	if info != want || want.Process == "" {
+		t.Errorf("query() = %+v, want %+v", info, want)
+	}
+}
+
+func TestX11SourceQueryFallbacks(t *testing.T) {
+	const root, active = 0x100, 0x500
+	tests := []struct {
+		name    string
+		server  *fakeXServer
+		want    Info
+		wantErr bool
+		xerr    bool // 应为 X 协议错误（连接保留）
+	}{
+		{
+			name: "latin-1 WM_NAME without _NET_WM_NAME",
+			server: &fakeXServer{
+				root:  root,
+				atoms: map[string]uint32{"_NET_ACTIVE_WINDOW": 300},
+				properties: map[[2]uint32]fakeProperty{
+					{root, 300}:             {typ: x11AtomWindow, format: 32, value: u32(active)},
+					{active, x11AtomWMName}: {typ: x11AtomString, format: 8, value: []byte("caf\xe9")},
+				},
+				windows: map[uint32]bool{root: true, active: true},
+			},
+			want: Info{Title: "café"},
+		},
+		{
+			name: "no active window",
+			server: &fakeXServer{
+				root:       root,
+				atoms:      map[string]uint32{"_NET_ACTIVE_WINDOW": 300},
+				properties: map[[2]uint32]fakeProperty{{root, 300}: {typ: x11AtomWindow, format: 32, value: u32(0)}},
+				windows:    map[uint32]bool{root: true},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "window manager without EWMH",
+			server:  &fakeXServer{root: root, windows: map[uint32]bool{root: true}},
+			wantErr: true,
+		},
+		{
+			name: "active window already closed",
+			server: &fakeXServer{
+				root:       root,
+				atoms:      map[string]uint32{"_NET_ACTIVE_WINDOW": 300, "_NET_WM_NAME": 302},
+				properties: map[[2]uint32]fakeProperty{{root, 300}: {typ: x11AtomWindow, format: 32, value: u32(active)}},
+				windows:    map[uint32]bool{root: true},
+			},
+			wantErr: true,
+			xerr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := dialFake(t, tt.server, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := &x11Source{conn: conn}
+			info, err := s.query()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("query() error = %v, want error %v", err, tt.wantErr)
+			}
+			var xerr *x11Error
+			if errors.As(err, &xerr) != tt.xerr {
+				t.Errorf("query() error = %v, want X11 error %v", err, tt.xerr)
+			}
+			if info != tt.want {
+				t.Errorf("query() = %+v, want %+v", info, tt.want)
+			}
+		})
+	}
+}
+
+func TestX11SetupRefused(t *testing.T) {
+	_, err := dialFake(t, newFakeDesktop(), "wrong")
+	if err == nil || !bytes.Contains([]byte(err.Error()), []byte("No protocol specified")) {
+		t.Errorf("setup with wrong cookie err = %v, want refusal reason", err)
+	}
+}
+
+func TestParseDisplay(t *testing.T) {
+	tests := []struct {
+		display string
+		network string
+		addr    string
+		number  string
+		wantErr bool
+	}{
+		{display: ":0", network: "unix", addr: "/tmp/.X11-unix/X0", number: "0"},
+		{display: ":1.0", network: "unix", addr: "/tmp/.X11-unix/X1", number: "1"},
+		{display: "unix:2", network: "unix", addr: "/tmp/.X11-unix/X2", number: "2"},
+		{display: "localhost:10.0", network: "tcp", addr: "localhost:6010", number: "10"},
+		{display: "[::1]:0", network: "tcp", addr: "[::1]:6000", number: "0"},
+		{display: "wayland-0", wantErr: true},
+		{display: ":x", wantErr: true},
+	}
+	for _, tt := range tests {
+		network, addr, number, err := parseDisplay(tt.display)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDisplay(%q) error = %v, want error %v", tt.display, err, tt.wantErr)
+			continue
+		}
+		if network != tt.network || addr != tt.addr || number != tt.number {
+			t.Errorf("parseDisplay(%q) = %q, %q, %q; want %q, %q, %q",
+				tt.display, network, addr, number, tt.network, tt.addr, tt.number)
+		}
+	}
+}
+
+func TestFindXauth(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skip(err)
+	}
+	entry := func(family uint16, addr, number, name, data string) []byte {
+		buf := binary.BigEndian.AppendUint16(nil, family)
+		for _, field := range []string{addr, number, name, data} {
+			buf = binary.BigEndian.AppendUint16(buf, uint16(len(field)))
+			buf = append(buf, field...)
+		}
+		return buf
+	}
+	var file []byte
+	file = append(file, entry(xauthFamilyLocal, hostname, "0", "XDM-AUTHORIZATION-1", "xdm")...)
+	file = append(file, entry(xauthFamilyLocal, "otherhost", "0", "MIT-MAGIC-COOKIE-1", "other")...)
+	file = append(file, entry(xauthFamilyLocal, hostname, "1", "MIT-MAGIC-COOKIE-1", "display1")...)
+	file = append(file, entry(xauthFamilyLocal, hostname, "0", "MIT-MAGIC-COOKIE-1", "local0")...)
+	file = append(file, entry(0, "remote", "2", "MIT-MAGIC-COOKIE-1", "remote2")...)
+	file = append(file, entry(xauthFamilyWild, "", "", "MIT-MAGIC-COOKIE-1", "wild")...)
+	path := filepath.Join(t.TempDir(), "Xauthority")
+	if err := os.WriteFile(path, file, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		network, host, number string
+		want                  string
+	}{
+		{"unix", "", "0", "local0"},
+		{"unix", "", "1", "display1"},
+		{"tcp", "remote", "2", "remote2"},
+		{"unix", "", "5", "wild"},
+	}
+	for _, tt := range tests {
+		name, data := findXauth(path, tt.network, tt.host, tt.number)
+		if name != "MIT-MAGIC-COOKIE-1" || string(data) != tt.want {
+			t.Errorf("findXauth(%s, %q, %s) = %q, %q; want cookie %q", tt.network, tt.host, tt.number, name, data, tt.want)
+		}
+	}
+	if name, data := findXauth(filepath.Join(t.TempDir(), "missing"), "unix", "", "0"); name != "" || data != nil {
+		t.Errorf("findXauth(missing file) = %q, %q; want no auth", name, data)
+	}
+}
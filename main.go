@@ -1,13 +1,47 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
 	"gamepad-key-mapper/internal/app"
+	"gamepad-key-mapper/internal/config"
+	"gamepad-key-mapper/internal/logging"
 	"gamepad-key-mapper/internal/ui"
 )
 
 func main() {
+	configPath := flag.String("config", "", "配置文件路径（按扩展名识别 .json/.yaml/.yml/.toml）")
+	flag.Parse()
+
+	if *configPath != "" {
+		if err := config.SetConfigPath(*configPath); err != nil {
+			fmt.Fprintln(os.Stderr, "无效的配置文件路径:", err)
+			os.Exit(2)
+		}
+	}
+
+	// 日志文件写在配置文件旁边（日志级别由配置中的 log_level 决定）
+	var logDir string
+	if path, err := config.GetConfigPath(); err == nil {
+		logDir = filepath.Dir(path)
+	}
+	closeLog, err := logging.Setup(logDir, os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	defer closeLog()
+
 	// 创建应用实例
-	application := app.New()
+	application, err := app.New()
+	if err != nil {
+		slog.Error("startup failed", "err", err)
+		closeLog()
+		os.Exit(1)
+	}
 
 	// 创建并运行UI
 	ui.Run(application)